@@ -1,13 +1,28 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"note_app/internal/app"
 	"note_app/internal/config"
 )
 
 func main() {
+	promoteAdmin := flag.String("promote-admin", "", "назначить пользователя администратором и завершить работу")
+	flag.Parse()
+
 	application := app.NewApp()
-	application.Initialize()
+	if err := application.Initialize(); err != nil {
+		log.Fatal(err)
+	}
+
+	if *promoteAdmin != "" {
+		if err := application.PromoteAdmin(*promoteAdmin); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Пользователь %s назначен администратором", *promoteAdmin)
+		return
+	}
+
 	log.Fatal(application.Run(config.Config.Port))
 }
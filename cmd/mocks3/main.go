@@ -0,0 +1,96 @@
+// Команда mocks3 запускает тестовый S3-совместимый сервер для локальной проверки хранилища вложений.
+// Поддерживаются запросы PUT, GET (в том числе с заголовком Range), HEAD и DELETE к объектам в path-style
+// адресации (/бакет/ключ). Объекты хранятся в каталоге. Подпись запросов не проверяется, проверяется только
+// наличие заголовка Authorization с ключом доступа из флага -access-key.
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// mockS3 тестовый S3-совместимый сервер.
+type mockS3 struct {
+	dir       string
+	accessKey string
+}
+
+func main() {
+	addr := flag.String("addr", ":9100", "адрес для прослушивания")
+	dir := flag.String("dir", "data/mocks3", "каталог для хранения объектов")
+	accessKey := flag.String("access-key", "mock", "ключ доступа")
+	flag.Parse()
+
+	if err := os.MkdirAll(*dir, 0o750); err != nil {
+		log.Fatal(err)
+	}
+
+	s := &mockS3{dir: *dir, accessKey: *accessKey}
+	log.Printf("Тестовый S3 запущен на %s, объекты хранятся в %s", *addr, *dir)
+	log.Fatal(http.ListenAndServe(*addr, s))
+}
+
+// ServeHTTP обрабатывает запросы к объектам.
+func (s *mockS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+s.accessKey+"/") {
+		writeError(w, http.StatusForbidden, "AccessDenied")
+		return
+	}
+
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if !ok || bucket == "" || key == "" || strings.Contains(key, "..") || strings.ContainsAny(bucket, `/\.`) {
+		writeError(w, http.StatusBadRequest, "InvalidRequest")
+		return
+	}
+	path := filepath.Join(s.dir, bucket, filepath.FromSlash(key))
+
+	switch r.Method {
+	case http.MethodPut:
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError")
+			return
+		}
+		file, err := os.Create(path)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "InternalError")
+			return
+		}
+		_, err = io.Copy(file, r.Body)
+		file.Close()
+		if err != nil {
+			os.Remove(path)
+			writeError(w, http.StatusInternalServerError, "InternalError")
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodGet, http.MethodHead:
+		file, err := os.Open(path)
+		if err != nil {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		defer file.Close()
+		http.ServeContent(w, r, "", time.Time{}, file)
+
+	case http.MethodDelete:
+		os.Remove(path)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// writeError отправляет ошибку в формате S3.
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, "<?xml version=\"1.0\" encoding=\"UTF-8\"?><Error><Code>"+code+"</Code></Error>")
+}
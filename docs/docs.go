@@ -15,39 +15,115 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/notes": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "Обрабатывает запрос на получение заметок с возможностью фильтрации.",
+                "description": "Возвращает открытые ключи RS256 и EdDSA для проверки токенов другими сервисами. Ключи HS256 не публикуются.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Открытые ключи JWT",
+                "responses": {}
+            }
+        },
+        "/2fa/confirm": {
+            "post": {
+                "description": "Проверяет код из приложения-аутентификатора, включает 2FA и возвращает одноразовые коды восстановления",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получение заметок",
+                "summary": "Подтверждение 2FA",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Дата начала в формате 'ГГГГ-ММ-ДД'",
-                        "name": "start_date",
-                        "in": "query"
-                    },
+                        "description": "Код из приложения-аутентификатора",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TOTPCodeInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/2fa/disable": {
+            "post": {
+                "description": "Выключает 2FA после проверки кода TOTP или кода восстановления. После нескольких неверных кодов подряд ввод кода блокируется на время; в этом случае возвращается 429.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Выключение 2FA",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Дата окончания в формате 'ГГГГ-ММ-ДД'",
-                        "name": "end_date",
-                        "in": "query"
-                    },
+                        "description": "Код TOTP или код восстановления",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorCodeInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/2fa/enroll": {
+            "post": {
+                "description": "Генерирует секрет TOTP и otpauth:// URI для приложения-аутентификатора. 2FA включается после подтверждения кодом.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Подключение 2FA",
+                "responses": {}
+            }
+        },
+        "/admin/notes/{id}": {
+            "delete": {
+                "description": "Удаляет заметку независимо от автора. Доступно только администраторам.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление заметки администратором",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя пользователя",
-                        "name": "username",
-                        "in": "query"
-                    },
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/admin/stats": {
+            "get": {
+                "description": "Возвращает количество пользователей и заметок. Доступно только администраторам.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Статистика",
+                "responses": {}
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "description": "Возвращает пользователей, имя или email которых содержит строку q. Доступно только администраторам.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список пользователей",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Дата в формате 'ГГГГ-ММ-ДД'",
-                        "name": "date",
+                        "description": "Строка поиска",
+                        "name": "q",
                         "in": "query"
                     },
                     {
@@ -64,131 +140,1781 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {}
-            },
+            }
+        },
+        "/admin/users/{id}/disable": {
             "post": {
-                "description": "Обрабатывает запрос на добавление новой заметки.",
-                "consumes": [
+                "description": "Блокирует учетную запись: пользователь не может войти, а выданные токены перестают приниматься. Доступно только администраторам.",
+                "produces": [
                     "application/json"
                 ],
+                "summary": "Блокировка пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/admin/users/{id}/enable": {
+            "post": {
+                "description": "Разблокирует учетную запись пользователя. Доступно только администраторам.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Добавление новой заметки",
+                "summary": "Разблокировка пользователя",
                 "parameters": [
                     {
-                        "description": "Данные новой заметки",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.NoteInput"
-                        }
+                        "type": "integer",
+                        "description": "Идентификатор пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {}
             }
         },
-        "/notes/{id}": {
+        "/admin/users/{id}/role": {
             "put": {
-                "description": "Обрабатывает запрос на редактирование заметки.",
+                "description": "Назначает пользователю роль user или admin. Доступно только администраторам.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Редактирование заметки",
+                "summary": "Изменение роли пользователя",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Идентификатор заметки",
+                        "description": "Идентификатор пользователя",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Новые данные заметки",
+                        "description": "Новая роль",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.NoteInput"
+                            "$ref": "#/definitions/models.RoleInput"
                         }
                     }
                 ],
                 "responses": {}
-            },
-            "delete": {
-                "description": "Обрабатывает запрос на удаление заметки.",
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Обменивает код авторизации на id_token, находит или создает пользователя и выдает токен доступа",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Удаление заметки",
+                "summary": "Завершение входа через OpenID Connect",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Идентификатор заметки",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Код авторизации",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Состояние",
+                        "name": "state",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {}
             }
         },
-        "/signin": {
-            "post": {
-                "description": "Аутентифицирует пользователя и генерирует токен доступа",
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Перенаправляет на страницу входа провайдера (authorization code + PKCE). С параметром link=true привязывает внешнюю учетную запись к текущему пользователю.",
+                "summary": "Вход через OpenID Connect",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Привязать учетную запись к текущему пользователю",
+                        "name": "link",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/events": {
+            "get": {
+                "description": "Передает события note.created, note.updated и note.deleted в формате text/event-stream. События личных заметок передаются только их автору. После переподключения с заголовком Last-Event-ID (или параметром last_event_id) передаются пропущенные события; если они уже недоступны, передается событие resync, и список заметок нужно запросить заново. Соединение поддерживается комментариями \": ping\".",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Поток событий заметок (SSE)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Только события заметок этого автора",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор последнего полученного события",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор последнего полученного события",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/events/ws": {
+            "get": {
+                "description": "Устанавливает соединение WebSocket и передает события заметок JSON-сообщениями в том же формате, что и GET /events. Пропущенные события передаются по параметру last_event_id. Соединение поддерживается управляющими кадрами ping.",
+                "summary": "Поток событий заметок (WebSocket)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Только события заметок этого автора",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Идентификатор последнего полученного события",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/favorites": {
+            "get": {
+                "description": "Возвращает заметки, добавленные текущим пользователем в избранное, в том же формате, что и GET /notes.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Избранные заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть полный текст заметок",
+                        "name": "full",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть избранные заметки из архива",
+                        "name": "archived",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/me/email": {
+            "put": {
+                "description": "Сохраняет новый адрес электронной почты и отправляет на него письмо для подтверждения",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Вход пользователя",
+                "summary": "Изменение email",
                 "parameters": [
                     {
-                        "description": "Данные пользователя для входа",
+                        "description": "Новый адрес электронной почты",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserInput"
+                            "$ref": "#/definitions/models.EmailInput"
                         }
                     }
                 ],
                 "responses": {}
             }
         },
-        "/signup": {
+        "/me/email/resend": {
             "post": {
-                "description": "Регистрирует нового пользователя с заданными данными",
+                "description": "Отправляет новое письмо для подтверждения текущего адреса электронной почты",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Повторная отправка письма подтверждения",
+                "responses": {}
+            }
+        },
+        "/me/email/verify": {
+            "get": {
+                "description": "Подтверждает адрес электронной почты. Токен передается в параметре token или в теле запроса.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Регистрация пользователя",
+                "summary": "Подтверждение email",
                 "parameters": [
                     {
-                        "description": "Данные нового пользователя",
+                        "type": "string",
+                        "description": "Токен из письма",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Токен из письма",
                         "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UserInput"
+                            "$ref": "#/definitions/models.TokenInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Подтверждает адрес электронной почты. Токен передается в параметре token или в теле запроса.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Подтверждение email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Токен из письма",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Токен из письма",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.TokenInput"
                         }
                     }
                 ],
                 "responses": {}
             }
-        }
-    },
-    "definitions": {
-        "models.NoteInput": {
-            "type": "object",
+        },
+        "/me/export": {
+            "get": {
+                "description": "Выгружает все заметки текущего пользователя файлом: json (массив заметок с пунктами списков задач), markdown-zip (ZIP-архив, каждая заметка — Markdown-файл с YAML-заголовком) или csv. Выгрузка включает метки, папку и видимость заметок. Выгрузка передается потоком по мере чтения заметок.",
+                "produces": [
+                    "application/json",
+                    "application/zip",
+                    "text/csv"
+                ],
+                "summary": "Выгрузка заметок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат: json (по умолчанию), markdown-zip или csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/me/import": {
+            "post": {
+                "description": "Загружает заметки из файла в поле file формы multipart/form-data: JSON-массива (как в выгрузке GET /me/export), ZIP-архива Markdown-файлов с YAML-заголовком или файла Evernote .enex. Формат задается параметром format или определяется по расширению файла. Каждая заметка проверяется по правилам создания заметок, допустимые заметки сохраняются в одной транзакции. Время создания из будущего заменяется временем загрузки. Метки, папка и видимость сохраняются из файла, заметка без видимости становится общедоступной. В ответе для каждой записи файла указывается результат: created, skipped (повтор существующей заметки или не заметка) или failed.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Загрузка заметок из файла",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Формат: json, markdown-zip или enex",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/me/sessions": {
+            "get": {
+                "description": "Возвращает устройства, на которых выполнен вход: браузер и система, User-Agent, IP-адрес, время входа и последней активности. Текущая сессия отмечена признаком current.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список сессий",
+                "responses": {}
+            }
+        },
+        "/me/sessions/{id}": {
+            "delete": {
+                "description": "Завершает сессию на другом устройстве или текущую сессию. Токен сессии перестает приниматься сразу.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отзыв сессии",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор сессии",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/me/tokens": {
+            "get": {
+                "description": "Возвращает действующие персональные токены текущего пользователя без их значений",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список токенов доступа",
+                "responses": {}
+            },
+            "post": {
+                "description": "Создает персональный токен для скриптов и интеграций. Токен передается в заголовке Authorization: Bearer и возвращается только один раз. Области действия: notes:read, notes:write.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание токена доступа",
+                "parameters": [
+                    {
+                        "description": "Название, области действия и срок токена",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AccessTokenInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/me/tokens/{id}": {
+            "delete": {
+                "description": "Отзывает персональный токен текущего пользователя",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отзыв токена доступа",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор токена",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes": {
+            "get": {
+                "description": "Обрабатывает запрос на получение заметок с возможностью фильтрации. Вместо полного текста возвращается краткое содержание без разметки (excerpt), полный текст — с параметром full=true. Для каждой заметки возвращается количество комментариев (comment_count), количество реакций каждого типа (reactions) и реакции текущего пользователя (my_reactions). Заметки, закрепленные текущим пользователем, идут первыми, заметки из его архива по умолчанию не возвращаются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение заметок",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Дата начала в формате 'ГГГГ-ММ-ДД'",
+                        "name": "start_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата окончания в формате 'ГГГГ-ММ-ДД'",
+                        "name": "end_date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя пользователя",
+                        "name": "username",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата в формате 'ГГГГ-ММ-ДД'",
+                        "name": "date",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть полный текст заметок",
+                        "name": "full",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Сортировка: new (по умолчанию), popular (по числу реакций) или trending (по числу реакций с учетом возраста заметки)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Вернуть заметки из архива текущего пользователя вместо остальных",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Только заметки со сроком выполнения раньше указанного времени (RFC 3339 или 'ГГГГ-ММ-ДД')",
+                        "name": "due_before",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Обрабатывает запрос на добавление новой заметки. С параметром template заметка создается по шаблону: тело запроса в этом случае имеет вид models.TemplateNoteInput и может быть пустым.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление новой заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор шаблона",
+                        "name": "template",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Данные новой заметки",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NoteInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/bulk": {
+            "post": {
+                "description": "Выполняет операции delete, archive, unarchive, tag (tags — добавляемые теги), move (folder — папка, пустая строка убирает заметку из папки) и visibility (visibility — public или private) над списками заметок по порядку в одной транзакции. Удалять и изменять можно только свои заметки, архив личный и доступен для любых видимых заметок. Для каждой заметки каждой операции возвращается результат: ok, not_found или forbidden. Если хотя бы один результат не ok, ни одна операция не выполняется и возвращается код 409.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Операции над несколькими заметками",
+                "parameters": [
+                    {
+                        "description": "Операции",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}": {
+            "get": {
+                "description": "Возвращает заметку целиком. С параметром render=html дополнительно возвращает текст, преобразованный в безопасный HTML (Markdown для заметок в формате markdown). Реакции на заметку возвращаются в полях reactions и my_reactions, отметки текущего пользователя — в полях pinned, favorite и archived.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Формат отображения: html",
+                        "name": "render",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "description": "Обрабатывает запрос на редактирование заметки.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Редактирование заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные заметки",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.NoteInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Обрабатывает запрос на удаление заметки.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/archive": {
+            "put": {
+                "description": "Переносит заметку в архив текущего пользователя: она перестает показываться в GET /notes и доступна с параметром archived=true.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Перенос в архив",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Возвращает заметку из архива текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Возврат из архива",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/attachments": {
+            "get": {
+                "description": "Возвращает имена, типы и размеры файлов, прикрепленных к заметке.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список вложений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Прикрепляет файл к заметке текущего пользователя. Файл передается в поле file формы multipart/form-data. Тип файла определяется по содержимому и должен входить в список допустимых, размер ограничен настройками.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Загрузка вложения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Файл",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/attachments/{attachmentId}": {
+            "get": {
+                "description": "Отдает файл потоком. Поддерживаются запросы части файла с заголовком Range.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "summary": "Скачивание вложения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор вложения",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Удаляет файл, прикрепленный к заметке текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление вложения",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор вложения",
+                        "name": "attachmentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/backlinks": {
+            "get": {
+                "description": "Возвращает заметки, которые ссылаются на заметку, сначала новые, с постраничной навигацией.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Обратные ссылки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/comments": {
+            "get": {
+                "description": "Возвращает комментарии к заметке в порядке добавления с постраничной навигацией.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Комментарии к заметке",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            },
+            "post": {
+                "description": "Добавляет комментарий текущего пользователя к заметке.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление комментария",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Текст комментария",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/comments/{commentId}": {
+            "put": {
+                "description": "Изменяет текст комментария. Доступно только автору комментария.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение комментария",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор комментария",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый текст комментария",
+                        "name": "comment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CommentInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Удаляет комментарий. Доступно автору комментария и автору заметки.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление комментария",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор комментария",
+                        "name": "commentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/favorite": {
+            "put": {
+                "description": "Добавляет заметку в избранное текущего пользователя (GET /favorites).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление в избранное",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Убирает заметку из избранного текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление из избранного",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/items": {
+            "post": {
+                "description": "Добавляет пункт в конец заметки-списка задач текущего пользователя. Доступно в течение срока редактирования заметки (1 день). Возвращает все пункты списка и прогресс.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление пункта списка задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пункт списка",
+                        "name": "item",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChecklistItemInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/items/order": {
+            "put": {
+                "description": "Меняет порядок пунктов заметки-списка задач текущего пользователя в течение срока редактирования заметки (1 день). Передаются идентификаторы всех пунктов в новом порядке.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение порядка пунктов списка задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новый порядок пунктов",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChecklistOrderInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/items/{itemId}": {
+            "delete": {
+                "description": "Удаляет пункт из заметки-списка задач текущего пользователя. Доступно в течение срока редактирования заметки (1 день).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление пункта списка задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пункта",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/items/{itemId}/check": {
+            "put": {
+                "description": "Отмечает пункт заметки-списка задач текущего пользователя выполненным.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отметка пункта списка задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пункта",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Снимает отметку о выполнении с пункта заметки-списка задач текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Снятие отметки с пункта списка задач",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор пункта",
+                        "name": "itemId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/links": {
+            "get": {
+                "description": "Возвращает ссылки [[заголовок]] и [[#id]] из текста заметки. Для каждой ссылки возвращается заметка, на которую она указывает, и ее текущий заголовок; ссылка без такой заметки отмечается как битая (broken).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Ссылки заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/pin": {
+            "put": {
+                "description": "Закрепляет заметку для текущего пользователя: в его списке заметок она идет первой.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Закрепление заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Снимает закрепление заметки для текущего пользователя.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Открепление заметки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/reactions": {
+            "post": {
+                "description": "Добавляет реакцию на заметку: like 👍, love ❤️, laugh 😂, wow 😮, sad 😢, party 🎉 или fire 🔥. Каждый тип реакции пользователь может поставить один раз. Возвращает реакции на заметку.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление реакции",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Тип реакции",
+                        "name": "reaction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ReactionInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/notes/{id}/reactions/{reaction}": {
+            "delete": {
+                "description": "Удаляет реакцию текущего пользователя на заметку. Возвращает реакции на заметку.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление реакции",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор заметки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Тип реакции",
+                        "name": "reaction",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/password/forgot": {
+            "post": {
+                "description": "Отправляет ссылку для сброса пароля на подтвержденный email. Ответ не зависит от существования пользователя.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Запрос восстановления пароля",
+                "parameters": [
+                    {
+                        "description": "Имя пользователя или email",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/password/reset": {
+            "post": {
+                "description": "Устанавливает новый пароль по одноразовому токену из письма. Все сессии и персональные токены доступа пользователя отзываются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Сброс пароля",
+                "parameters": [
+                    {
+                        "description": "Токен из письма и новый пароль",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResetPasswordInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/signin": {
+            "post": {
+                "description": "Аутентифицирует пользователя и генерирует токен доступа. Токен устанавливается в куки вместе с CSRF-токеном, который нужно передавать в заголовке X-CSRF-Token в изменяющих запросах. Если у пользователя включена 2FA, возвращает challenge_token для второго шага входа.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Вход пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные пользователя для входа",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SignInInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/signin/2fa": {
+            "post": {
+                "description": "Проверяет код TOTP или код восстановления и генерирует токен доступа. Токен первого шага действует для одного входа. После нескольких неверных кодов подряд токен первого шага становится недействительным, а ввод кода пользователем блокируется на время; в этом случае возвращается 429.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Второй шаг входа",
+                "parameters": [
+                    {
+                        "description": "Токен первого шага и код подтверждения",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TwoFactorSignInInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Регистрирует нового пользователя с заданными данными. Если указан email, на него отправляется письмо для подтверждения.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Регистрация пользователя",
+                "parameters": [
+                    {
+                        "description": "Данные нового пользователя",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/templates": {
+            "get": {
+                "description": "Возвращает шаблоны текущего пользователя и общие шаблоны.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список шаблонов",
+                "responses": {}
+            },
+            "post": {
+                "description": "Создает шаблон заметки текущего пользователя. Заголовок и текст могут содержать подстановки {{date}}, {{time}}, {{datetime}}, {{username}} и произвольные {{имя}}, значения которых передаются при создании заметки. Общий шаблон (global) может создать только администратор.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание шаблона",
+                "parameters": [
+                    {
+                        "description": "Данные шаблона",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TemplateInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/templates/{id}": {
+            "get": {
+                "description": "Возвращает шаблон текущего пользователя или общий шаблон.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение шаблона",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор шаблона",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "description": "Изменяет шаблон. Личный шаблон изменяет его владелец, общий — администратор. Признак global не изменяется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение шаблона",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор шаблона",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новые данные шаблона",
+                        "name": "template",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TemplateInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Удаляет шаблон. Личный шаблон удаляет его владелец, общий — администратор. Заметки, созданные по шаблону, сохраняются.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление шаблона",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор шаблона",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Возвращает веб-хуки текущего пользователя без ключей подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список веб-хуков",
+                "responses": {}
+            },
+            "post": {
+                "description": "Создает веб-хук: события заметок (note.created, note.updated, note.deleted) отправляются на адрес POST-запросом в том же формате, что и в GET /events. Пустой список events означает все события, author_id оставляет события заметок одного автора. Тело запроса подписывается HMAC-SHA256 ключом secret, подпись передается в заголовке X-Signature-256 (sha256=\u003chex\u003e). Ключ возвращается только один раз. Адреса во внутренней сети не допускаются, перенаправления не выполняются.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Создание веб-хука",
+                "parameters": [
+                    {
+                        "description": "Адрес и фильтры веб-хука",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Возвращает веб-хук текущего пользователя без ключа подписи",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение веб-хука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор веб-хука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            },
+            "put": {
+                "description": "Изменяет адрес, фильтры и состояние (active) веб-хука. Ключ подписи не меняется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение веб-хука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор веб-хука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Адрес и фильтры веб-хука",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookInput"
+                        }
+                    }
+                ],
+                "responses": {}
+            },
+            "delete": {
+                "description": "Удаляет веб-хук текущего пользователя вместе с журналом доставок",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление веб-хука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор веб-хука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "description": "Возвращает доставки веб-хука, начиная с последних: событие, состояние (pending, delivered, failed), число попыток, время следующей попытки, код ответа и ошибку последней попытки.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Журнал доставок веб-хука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор веб-хука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество записей на странице",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {}
+            }
+        },
+        "/webhooks/{id}/deliveries/{deliveryId}/redeliver": {
+            "post": {
+                "description": "Ставит событие доставки в очередь повторно. Создается новая доставка со ссылкой на прежнюю (redelivery_of), прежняя остается в журнале.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Повторная доставка веб-хука",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор веб-хука",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Идентификатор доставки",
+                        "name": "deliveryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {}
+            }
+        }
+    },
+    "definitions": {
+        "models.AccessTokenInput": {
+            "type": "object",
+            "properties": {
+                "expires_in_days": {
+                    "description": "ExpiresInDays срок действия в днях. 0 — бессрочный токен.",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.BulkInput": {
+            "type": "object",
+            "properties": {
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkOperation"
+                    }
+                }
+            }
+        },
+        "models.BulkOp": {
+            "type": "string",
+            "enum": [
+                "delete",
+                "archive",
+                "unarchive",
+                "tag",
+                "move",
+                "visibility"
+            ],
+            "x-enum-varnames": [
+                "BulkOpDelete",
+                "BulkOpArchive",
+                "BulkOpUnarchive",
+                "BulkOpTag",
+                "BulkOpMove",
+                "BulkOpVisibility"
+            ]
+        },
+        "models.BulkOperation": {
+            "type": "object",
+            "properties": {
+                "folder": {
+                    "description": "Folder папка для операции move.",
+                    "type": "string"
+                },
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "op": {
+                    "$ref": "#/definitions/models.BulkOp"
+                },
+                "tags": {
+                    "description": "Tags метки для операции tag.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "visibility": {
+                    "description": "Visibility видимость для операции visibility: public или private.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChecklistItemInput": {
+            "type": "object",
+            "properties": {
+                "checked": {
+                    "type": "boolean"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChecklistOrderInput": {
+            "type": "object",
+            "properties": {
+                "item_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.CommentInput": {
+            "type": "object",
+            "properties": {
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.EmailInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForgotPasswordInput": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NoteInput": {
+            "type": "object",
+            "properties": {
+                "due_at": {
+                    "description": "DueAt срок выполнения и RemindAt время напоминания в формате RFC 3339. Не указанные поля сбрасываются.",
+                    "type": "string"
+                },
+                "format": {
+                    "description": "Format формат текста: plain (по умолчанию), markdown или checklist.",
+                    "type": "string"
+                },
+                "items": {
+                    "description": "Items пункты списка задач для формата checklist, текст заметки в этом случае не указывается.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChecklistItemInput"
+                    }
+                },
+                "remind_at": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReactionInput": {
+            "type": "object",
+            "properties": {
+                "reaction": {
+                    "description": "Reaction тип реакции: like, love, laugh, wow, sad, party или fire.",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResetPasswordInput": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RoleInput": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SignInInput": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TOTPCodeInput": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TemplateInput": {
+            "type": "object",
             "properties": {
+                "format": {
+                    "description": "Format формат текста заметок по шаблону: plain (по умолчанию) или markdown.",
+                    "type": "string"
+                },
+                "global": {
+                    "description": "Global создать общий шаблон. Доступно только администраторам.",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
                 "text": {
                     "type": "string"
                 },
@@ -197,9 +1923,45 @@ const docTemplate = `{
                 }
             }
         },
+        "models.TokenInput": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorCodeInput": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.TwoFactorSignInInput": {
+            "type": "object",
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string"
+                },
+                "recovery_code": {
+                    "type": "string"
+                }
+            }
+        },
         "models.UserInput": {
             "type": "object",
             "properties": {
+                "email": {
+                    "type": "string"
+                },
                 "password": {
                     "type": "string"
                 },
@@ -207,6 +1969,27 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "models.WebhookInput": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active если не указан, веб-хук при создании включен, а при изменении сохраняет прежнее состояние.",
+                    "type": "boolean"
+                },
+                "author_id": {
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
         }
     }
 }`
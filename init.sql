@@ -5,15 +5,269 @@ SELECT 'CREATE DATABASE db_users' WHERE NOT EXISTS (SELECT 1 FROM pg_database WH
 CREATE TABLE users (
     id SERIAL PRIMARY KEY,
     username VARCHAR(50) UNIQUE NOT NULL,
-    password VARCHAR(100) NOT NULL
+    -- Хэш пароля в формате PHC: его длина зависит от параметров argon2id, поэтому длина не ограничивается
+    password TEXT NOT NULL,
+    role VARCHAR(16) NOT NULL DEFAULT 'user',
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    email VARCHAR(255),
+    email_verified BOOLEAN NOT NULL DEFAULT FALSE,
+    totp_secret VARCHAR(64),
+    totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    totp_last_step BIGINT NOT NULL DEFAULT 0,
+    -- Число попыток ввода кода 2FA подряд без успешной и время, до которого ввод кода заблокирован
+    totp_failed_attempts INTEGER NOT NULL DEFAULT 0,
+    totp_locked_until TIMESTAMP
 );
 
+-- Создаем таблицу попыток второго шага входа: число попыток ввода кода с каждым токеном (jti) второго шага
+CREATE TABLE two_factor_challenges (
+    token_id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+CREATE INDEX two_factor_challenges_user_id_idx ON two_factor_challenges (user_id);
+
+-- Создаем таблицу кодов восстановления двухфакторной аутентификации
+CREATE TABLE user_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Email уникален без учета регистра
+CREATE UNIQUE INDEX users_email_idx ON users (LOWER(email));
+
+-- Создаем таблицу одноразовых токенов (подтверждение email, восстановление пароля)
+CREATE TABLE user_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    email VARCHAR(255),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP
+);
+
+-- Создаем таблицу внешних учетных записей OpenID Connect
+CREATE TABLE user_identities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (issuer, subject)
+);
+
+-- Создаем таблицу персональных токенов доступа
+CREATE TABLE personal_access_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+-- Создаем таблицу сессий: каждая запись соответствует выданному при входе токену (jti)
+CREATE TABLE sessions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_id VARCHAR(64) UNIQUE NOT NULL,
+    device VARCHAR(100) NOT NULL,
+    user_agent VARCHAR(512) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
 -- Создаем таблицу заметок в базе данных db_users
 CREATE TABLE notes (
     id SERIAL PRIMARY KEY,
     user_id INTEGER REFERENCES users(id),
     title VARCHAR(100) NOT NULL,
     text TEXT NOT NULL,
+    format VARCHAR(16) NOT NULL DEFAULT 'plain',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    author VARCHAR(50) NOT NULL,
+    -- Срок выполнения и напоминание хранятся с часовым поясом: напоминание должно сработать в указанный момент
+    -- независимо от часового пояса сервера. reminded_at заполняется после отправки напоминания.
+    due_at TIMESTAMPTZ,
+    remind_at TIMESTAMPTZ,
+    reminded_at TIMESTAMPTZ,
+    -- Число попыток отправки напоминания и время следующей попытки. На время отправки напоминание занимается:
+    -- следующая попытка переносится вперед, чтобы его не взял другой экземпляр приложения
+    reminder_attempts INTEGER NOT NULL DEFAULT 0,
+    reminder_next_attempt_at TIMESTAMPTZ,
+    -- Метки и папка заметки (пустая строка — без папки). Личную (private) заметку видит только ее автор
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    folder VARCHAR(100) NOT NULL DEFAULT '',
+    visibility VARCHAR(16) NOT NULL DEFAULT 'public'
+);
+
+CREATE INDEX notes_pending_reminders_idx ON notes (remind_at) WHERE reminded_at IS NULL;
+CREATE INDEX notes_due_at_idx ON notes (due_at);
+CREATE INDEX notes_tags_idx ON notes USING GIN (tags);
+
+-- Создаем таблицу вложений заметок. Содержимое файлов хранится в хранилище (каталог или S3) под ключом storage_key
+CREATE TABLE attachments (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX attachments_note_id_idx ON attachments (note_id);
+
+-- Создаем таблицу комментариев к заметкам
+CREATE TABLE note_comments (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    author VARCHAR(50) NOT NULL
-);
\ No newline at end of file
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX note_comments_note_id_idx ON note_comments (note_id, created_at);
+
+-- Создаем таблицу реакций на заметки: каждый тип реакции пользователь может поставить заметке один раз
+CREATE TABLE note_reactions (
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reaction VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (note_id, user_id, reaction)
+);
+
+-- Создаем таблицу личных отметок пользователей на заметках: закреплена, в избранном, в архиве
+CREATE TABLE note_user_flags (
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    favorite BOOLEAN NOT NULL DEFAULT FALSE,
+    archived BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (note_id, user_id)
+);
+
+CREATE INDEX note_user_flags_favorite_idx ON note_user_flags (user_id) WHERE favorite;
+
+-- Создаем таблицу пунктов заметок-списков задач
+CREATE TABLE note_checklist_items (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    checked BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX note_checklist_items_note_id_idx ON note_checklist_items (note_id, position);
+
+-- Создаем таблицу шаблонов заметок: шаблоны без владельца (user_id IS NULL) доступны всем пользователям
+CREATE TABLE note_templates (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    title VARCHAR(100) NOT NULL DEFAULT '',
+    text TEXT NOT NULL DEFAULT '',
+    format VARCHAR(16) NOT NULL DEFAULT 'plain',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX note_templates_user_id_idx ON note_templates (user_id);
+
+-- Создаем таблицу ссылок между заметками: ссылки [[заголовок]] и [[#id]] из текста заметок.
+-- У битой ссылки target_note_id равен NULL
+CREATE TABLE note_links (
+    source_note_id INTEGER NOT NULL REFERENCES notes(id) ON DELETE CASCADE,
+    link_text VARCHAR(100) NOT NULL,
+    target_note_id INTEGER REFERENCES notes(id) ON DELETE SET NULL,
+    PRIMARY KEY (source_note_id, link_text)
+);
+
+CREATE INDEX note_links_target_note_id_idx ON note_links (target_note_id);
+CREATE INDEX note_links_broken_idx ON note_links (lower(link_text)) WHERE target_note_id IS NULL;
+CREATE INDEX notes_user_id_lower_title_idx ON notes (user_id, lower(title));
+
+-- Создаем таблицу веб-хуков пользователей: адрес, ключ подписи и фильтры событий.
+-- Пустой список events означает все события, author_id ограничивает события заметками одного автора
+CREATE TABLE webhooks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    author_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+CREATE INDEX webhooks_user_id_idx ON webhooks (user_id);
+
+-- Создаем таблицу доставок веб-хуков: очередь отправки и журнал результатов. Событие ставится в очередь
+-- веб-хука один раз, даже если outbox передал его повторно; повторная доставка по запросу пользователя
+-- ссылается на исходную (redelivery_of) и в уникальности не участвует
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    redelivery_of BIGINT REFERENCES webhook_deliveries(id) ON DELETE CASCADE,
+    event_type VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    response_status INTEGER,
+    error TEXT,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, id DESC);
+CREATE UNIQUE INDEX webhook_deliveries_event_idx ON webhook_deliveries (webhook_id, event_id) WHERE redelivery_of IS NULL;
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+-- Создаем таблицу исходящих событий заметок (transactional outbox): событие записывается в одной транзакции
+-- с изменением заметки и пересылается получателям фоновым процессом. Ссылки на заметку нет, потому что
+-- событие удаления переживает заметку. note содержит краткие данные заметки, у события удаления он равен NULL.
+-- События личной заметки (private) передаются только ее автору. Неотправленное событие повторяется
+-- с растущей задержкой (next_attempt_at), после исчерпания попыток отмечается failed_at и больше не отправляется
+CREATE TABLE note_events_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    event_type VARCHAR(32) NOT NULL,
+    note JSONB,
+    private BOOLEAN NOT NULL DEFAULT FALSE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP,
+    failed_at TIMESTAMP
+);
+
+CREATE INDEX note_events_outbox_pending_idx ON note_events_outbox (id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX note_events_outbox_pending_note_idx ON note_events_outbox (note_id, id) WHERE published_at IS NULL AND failed_at IS NULL;
+CREATE INDEX note_events_outbox_published_at_idx ON note_events_outbox (published_at) WHERE published_at IS NOT NULL;
+CREATE INDEX note_events_outbox_failed_at_idx ON note_events_outbox (failed_at) WHERE failed_at IS NOT NULL;
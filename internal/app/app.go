@@ -1,22 +1,41 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "github.com/swaggo/http-swagger"
 	"gopkg.in/yaml.v3"
+	"log"
+	"net/http"
 	_ "note_app/docs"
+	"note_app/internal/auth"
 	"note_app/internal/config"
+	"note_app/internal/events"
 	"note_app/internal/handlers"
+	"note_app/internal/mailer"
+	"note_app/internal/models"
+	"note_app/internal/notifier"
+	"note_app/internal/outbox"
 	"note_app/internal/repository"
 	"note_app/internal/services"
+	"note_app/internal/storage"
+	"note_app/pkg/utils"
 	"os"
+	"strings"
 )
 
 // App представляет собой приложение, которое содержит маршрутизатор Gin.
 type App struct {
 	Router *gin.Engine
+
+	adminService    *services.AdminService
+	reminderService *services.ReminderService
+	webhookService  *services.WebhookService
+	outboxService   *services.OutboxService
+	busListener     *outbox.BusListener
 }
 
 // NewApp создает новый экземпляр приложения.
@@ -31,13 +50,82 @@ func (a *App) Initialize() error {
 		return err
 	}
 
+	tokens, err := auth.NewTokenManager(config.Config.JWT, config.Config.JWTSecret)
+	if err != nil {
+		return err
+	}
+
 	db, err := config.Config.DB.Connect()
 	if err != nil {
 		return err
 	}
 
-	userService := services.NewUserService(repository.NewUserRepository(db))
-	noteService := services.NewNoteService(repository.NewNoteRepository(db))
+	mail, err := mailer.New(config.Config.Mail)
+	if err != nil {
+		return err
+	}
+
+	passwords, err := utils.NewPasswordManager(config.Config.Password)
+	if err != nil {
+		return err
+	}
+
+	blobs, err := storage.New(config.Config.Attachments.Storage)
+	if err != nil {
+		return err
+	}
+
+	userRepository := repository.NewUserRepository(db)
+	userService := services.NewUserService(userRepository, passwords, config.Config.TwoFactor)
+	accountService := services.NewAccountService(userRepository, repository.NewTokenRepository(db), mail, passwords, config.Config.AppURL)
+	attachmentService := services.NewAttachmentService(repository.NewAttachmentRepository(db), blobs, config.Config.Attachments)
+	eventBus := events.NewBus(config.Config.Events.BufferSize)
+	webhookRepository := repository.NewWebhookRepository(db)
+	eventSinks, err := outbox.New(config.Config.Outbox, db, webhookRepository)
+	if err != nil {
+		return err
+	}
+	a.busListener, err = outbox.NewBusListener(config.Config.DB.DataSourceName(), eventBus)
+	if err != nil {
+		return err
+	}
+	a.outboxService = services.NewOutboxService(repository.NewOutboxRepository(db), eventSinks, config.Config.Outbox)
+	noteRepository := repository.NewNoteRepository(db)
+	noteService := services.NewNoteService(noteRepository, attachmentService, a.outboxService)
+	commentService := services.NewCommentService(repository.NewCommentRepository(db))
+	reactionService := services.NewReactionService(repository.NewReactionRepository(db))
+	checklistService := services.NewChecklistService(repository.NewChecklistRepository(db), a.outboxService)
+	templateService := services.NewTemplateService(repository.NewTemplateRepository(db))
+	linkService := services.NewLinkService(repository.NewLinkRepository(db))
+	exportService := services.NewExportService(repository.NewExportRepository(db))
+	importService := services.NewImportService(noteRepository, a.outboxService, config.Config.Import)
+	bulkService := services.NewBulkService(repository.NewBulkRepository(db), attachmentService, a.outboxService)
+	webhookService := services.NewWebhookService(webhookRepository, config.Config.Webhooks)
+	accessTokenService := services.NewAccessTokenService(repository.NewAccessTokenRepository(db))
+	sessionService := services.NewSessionService(repository.NewSessionRepository(db))
+	oidcService := services.NewOIDCService(config.Config.OIDC, userRepository, repository.NewIdentityRepository(db), passwords)
+	a.adminService = services.NewAdminService(userRepository, noteService, repository.NewStatsRepository(db))
+
+	if config.Config.Reminders.Enabled {
+		reminderNotifier, err := notifier.New(config.Config.Reminders, mail, config.Config.AppURL)
+		if err != nil {
+			return err
+		}
+		a.reminderService = services.NewReminderService(repository.NewReminderRepository(db), reminderNotifier, config.Config.Reminders)
+	}
+	if config.Config.Webhooks.Enabled {
+		a.webhookService = webhookService
+	}
+
+	// Токены сессии принимаются, только пока их сессия не отозвана
+	tokens.UseSessions(sessionService)
+
+	// Назначаем администраторов, перечисленных в конфигурации
+	for _, username := range config.Config.Admins {
+		if err := a.adminService.PromoteAdmin(username); err != nil {
+			log.Printf("Не удалось назначить администратором %s: %v", username, err)
+		}
+	}
 
 	// Инициализируем маршрутизатор Gin
 	a.Router = gin.Default()
@@ -45,8 +133,14 @@ func (a *App) Initialize() error {
 	// Переключаемся в режим выпуска в производственной среде
 	gin.SetMode(gin.ReleaseMode)
 
+	// Разрешаем запросы из браузера с доверенных источников
+	a.Router.Use(handlers.CORS(config.Config.CORS))
+
+	// Определяем пользователя по токену сессии или персональному токену доступа
+	a.Router.Use(handlers.Authenticate(tokens, userService, accessTokenService))
+
 	// Используем обработчики Gin
-	a.initHandlers(userService, &noteService)
+	a.initHandlers(tokens, userService, accountService, accessTokenService, sessionService, oidcService, attachmentService, commentService, reactionService, checklistService, templateService, linkService, exportService, importService, bulkService, webhookService, eventBus, &noteService)
 
 	// Инициализируем Swagger
 	a.initSwagger()
@@ -61,28 +155,160 @@ func (a *App) initSwagger() {
 }
 
 // Добавьте инициализацию нового обработчика в метод initHandlers
-func (a *App) initHandlers(userService *services.UserService, noteService *services.NoteService) {
-	signUpHandler := handlers.NewSignupHandler(userService).SignUp
-	signInHandler := handlers.NewSignInHandler(userService, config.Config.JWTSecret).SignIn
-	noteHandler := handlers.NewNoteHandler(*noteService, userService, config.Config.JWTSecret).AddNote
-	editNoteHandler := handlers.EditNoteHandler(*noteService, userService, config.Config.JWTSecret)
-	deleteNoteHandler := handlers.DeleteNoteHandler(*noteService, config.Config.JWTSecret)
-	getNotesHandler := handlers.GetNotesHandler(*noteService, *userService, config.Config.JWTSecret)
+func (a *App) initHandlers(tokens *auth.TokenManager, userService *services.UserService, accountService *services.AccountService, accessTokenService *services.AccessTokenService, sessionService *services.SessionService, oidcService *services.OIDCService, attachmentService *services.AttachmentService, commentService *services.CommentService, reactionService *services.ReactionService, checklistService *services.ChecklistService, templateService *services.TemplateService, linkService *services.LinkService, exportService *services.ExportService, importService *services.ImportService, bulkService *services.BulkService, webhookService *services.WebhookService, eventBus *events.Bus, noteService *services.NoteService) {
+	signUpHandler := handlers.NewSignupHandler(userService, accountService).SignUp
+	cookie := cookieOptions(config.Config.Cookie)
+	signInHandler := handlers.NewSignInHandler(userService, sessionService, tokens, cookie).SignIn
+	noteHandler := handlers.NewNoteHandler(*noteService, userService, templateService, tokens).AddNote
+	editNoteHandler := handlers.EditNoteHandler(*noteService, userService, tokens)
+	deleteNoteHandler := handlers.DeleteNoteHandler(*noteService, tokens)
+	getNotesHandler := handlers.GetNotesHandler(*noteService, *userService, reactionService, tokens)
+	getNoteHandler := handlers.GetNoteHandler(*noteService, userService, reactionService, tokens)
+	twoFactorHandler := handlers.NewTwoFactorHandler(userService, tokens, config.Config.TOTPIssuer)
+	signInTwoFactorHandler := handlers.NewSignInHandler(userService, sessionService, tokens, cookie).SignInTwoFactor
+	accountHandler := handlers.NewAccountHandler(accountService, tokens)
+	adminHandler := handlers.NewAdminHandler(a.adminService)
+	accessTokenHandler := handlers.NewAccessTokenHandler(accessTokenService, tokens)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, sessionService, tokens, cookie)
+	sessionHandler := handlers.NewSessionHandler(sessionService, tokens)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, *noteService, tokens)
+	commentHandler := handlers.NewCommentHandler(commentService, *noteService, tokens)
+	reactionHandler := handlers.NewReactionHandler(reactionService, *noteService, tokens)
+	noteFlagHandler := handlers.NewNoteFlagHandler(*noteService, reactionService, tokens)
+	checklistHandler := handlers.NewChecklistHandler(checklistService, *noteService, tokens)
+	templateHandler := handlers.NewTemplateHandler(templateService, userService, tokens)
+	linkHandler := handlers.NewLinkHandler(linkService, *noteService, tokens)
+	exportHandler := handlers.NewExportHandler(exportService, tokens)
+	importHandler := handlers.NewImportHandler(importService, userService, tokens)
+	bulkHandler := handlers.NewBulkHandler(bulkService, tokens)
+	webhookHandler := handlers.NewWebhookHandler(webhookService, tokens)
+	eventHandler := handlers.NewEventHandler(eventBus, tokens, config.Config.Events, config.Config.CORS)
 
+	a.Router.GET("/.well-known/jwks.json", handlers.JWKSHandler(tokens.Keys()))
 	a.Router.POST("/signup", signUpHandler)
 	a.Router.POST("/signin", signInHandler)
-	a.Router.POST("/notes", noteHandler)
-	a.Router.PUT("/notes/:id", editNoteHandler)
-	a.Router.DELETE("/notes/:id", deleteNoteHandler)
-	a.Router.GET("/notes", getNotesHandler)
+	a.Router.POST("/signin/2fa", signInTwoFactorHandler)
+	a.Router.GET("/auth/oidc/login", oidcHandler.Login)
+	a.Router.GET("/auth/oidc/callback", oidcHandler.Callback)
+	a.Router.POST("/2fa/enroll", twoFactorHandler.Enroll)
+	a.Router.POST("/2fa/confirm", twoFactorHandler.Confirm)
+	a.Router.POST("/2fa/disable", twoFactorHandler.Disable)
+	a.Router.PUT("/me/email", accountHandler.ChangeEmail)
+	a.Router.POST("/me/email/resend", accountHandler.ResendVerification)
+	a.Router.GET("/me/email/verify", accountHandler.VerifyEmail)
+	a.Router.POST("/me/email/verify", accountHandler.VerifyEmail)
+	a.Router.POST("/password/forgot", accountHandler.ForgotPassword)
+	a.Router.POST("/password/reset", accountHandler.ResetPassword)
+	a.Router.POST("/me/tokens", accessTokenHandler.CreateToken)
+	a.Router.GET("/me/tokens", accessTokenHandler.GetTokens)
+	a.Router.DELETE("/me/tokens/:id", accessTokenHandler.RevokeToken)
+	a.Router.GET("/me/sessions", sessionHandler.GetSessions)
+	a.Router.DELETE("/me/sessions/:id", sessionHandler.RevokeSession)
+	a.Router.POST("/webhooks", webhookHandler.CreateWebhook)
+	a.Router.GET("/webhooks", webhookHandler.GetWebhooks)
+	a.Router.GET("/webhooks/:id", webhookHandler.GetWebhook)
+	a.Router.PUT("/webhooks/:id", webhookHandler.EditWebhook)
+	a.Router.DELETE("/webhooks/:id", webhookHandler.DeleteWebhook)
+	a.Router.GET("/webhooks/:id/deliveries", webhookHandler.GetDeliveries)
+	a.Router.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+
+	// Маршруты заметок доступны также по персональным токенам с соответствующей областью действия
+	readNotes := handlers.RequireScope(models.ScopeNotesRead)
+	writeNotes := handlers.RequireScope(models.ScopeNotesWrite)
+	a.Router.POST("/notes", writeNotes, noteHandler)
+	a.Router.PUT("/notes/:id", writeNotes, editNoteHandler)
+	a.Router.DELETE("/notes/:id", writeNotes, deleteNoteHandler)
+	a.Router.POST("/notes/bulk", writeNotes, bulkHandler.ApplyBulk)
+	a.Router.GET("/notes", readNotes, getNotesHandler)
+	a.Router.GET("/notes/:id", readNotes, getNoteHandler)
+	a.Router.POST("/notes/:id/attachments", writeNotes, attachmentHandler.UploadAttachment)
+	a.Router.GET("/notes/:id/attachments", readNotes, attachmentHandler.GetAttachments)
+	a.Router.GET("/notes/:id/attachments/:attachmentId", readNotes, attachmentHandler.DownloadAttachment)
+	a.Router.DELETE("/notes/:id/attachments/:attachmentId", writeNotes, attachmentHandler.DeleteAttachment)
+	a.Router.POST("/notes/:id/comments", writeNotes, commentHandler.AddComment)
+	a.Router.GET("/notes/:id/comments", readNotes, commentHandler.GetComments)
+	a.Router.PUT("/notes/:id/comments/:commentId", writeNotes, commentHandler.EditComment)
+	a.Router.DELETE("/notes/:id/comments/:commentId", writeNotes, commentHandler.DeleteComment)
+	a.Router.POST("/notes/:id/reactions", writeNotes, reactionHandler.AddReaction)
+	a.Router.DELETE("/notes/:id/reactions/:reaction", writeNotes, reactionHandler.RemoveReaction)
+	a.Router.PUT("/notes/:id/pin", writeNotes, noteFlagHandler.Pin)
+	a.Router.DELETE("/notes/:id/pin", writeNotes, noteFlagHandler.Unpin)
+	a.Router.PUT("/notes/:id/favorite", writeNotes, noteFlagHandler.Favorite)
+	a.Router.DELETE("/notes/:id/favorite", writeNotes, noteFlagHandler.Unfavorite)
+	a.Router.PUT("/notes/:id/archive", writeNotes, noteFlagHandler.Archive)
+	a.Router.DELETE("/notes/:id/archive", writeNotes, noteFlagHandler.Unarchive)
+	a.Router.POST("/notes/:id/items", writeNotes, checklistHandler.AddItem)
+	a.Router.PUT("/notes/:id/items/order", writeNotes, checklistHandler.ReorderItems)
+	a.Router.PUT("/notes/:id/items/:itemId/check", writeNotes, checklistHandler.CheckItem)
+	a.Router.DELETE("/notes/:id/items/:itemId/check", writeNotes, checklistHandler.UncheckItem)
+	a.Router.DELETE("/notes/:id/items/:itemId", writeNotes, checklistHandler.DeleteItem)
+	a.Router.GET("/notes/:id/links", readNotes, linkHandler.GetLinks)
+	a.Router.GET("/notes/:id/backlinks", readNotes, linkHandler.GetBacklinks)
+	a.Router.GET("/favorites", readNotes, noteFlagHandler.GetFavorites)
+	a.Router.GET("/events", readNotes, eventHandler.StreamEvents)
+	a.Router.GET("/events/ws", readNotes, eventHandler.StreamEventsWebSocket)
+	a.Router.GET("/me/export", readNotes, exportHandler.ExportNotes)
+	a.Router.POST("/me/import", writeNotes, importHandler.ImportNotes)
+	a.Router.POST("/templates", writeNotes, templateHandler.CreateTemplate)
+	a.Router.GET("/templates", readNotes, templateHandler.GetTemplates)
+	a.Router.GET("/templates/:id", readNotes, templateHandler.GetTemplate)
+	a.Router.PUT("/templates/:id", writeNotes, templateHandler.EditTemplate)
+	a.Router.DELETE("/templates/:id", writeNotes, templateHandler.DeleteTemplate)
+
+	admin := a.Router.Group("/admin", handlers.RequireRole(tokens, userService, models.RoleAdmin))
+	admin.GET("/users", adminHandler.ListUsers)
+	admin.POST("/users/:id/disable", adminHandler.DisableUser)
+	admin.POST("/users/:id/enable", adminHandler.EnableUser)
+	admin.PUT("/users/:id/role", adminHandler.SetUserRole)
+	admin.DELETE("/notes/:id", adminHandler.DeleteNote)
+	admin.GET("/stats", adminHandler.GetStats)
+
+}
 
+// PromoteAdmin назначает пользователя с указанным именем администратором.
+func (a *App) PromoteAdmin(username string) error {
+	return a.adminService.PromoteAdmin(username)
 }
 
-// Run запускает сервер на указанном адресе.
+// Run запускает отправку напоминаний и сервер на указанном адресе.
 func (a *App) Run(addr string) error {
+	if a.reminderService != nil {
+		go a.reminderService.Run(context.Background())
+	}
+	if a.webhookService != nil {
+		go a.webhookService.Run(context.Background())
+	}
+	go a.outboxService.Run(context.Background())
+	go a.busListener.Run(context.Background())
 	return a.Router.Run(addr)
 }
 
+// cookieOptions возвращает атрибуты куки сессии с безопасными значениями по умолчанию.
+func cookieOptions(cfg config.CookieConfig) utils.CookieOptions {
+	opts := utils.CookieOptions{
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+		Path:     "/",
+		Domain:   cfg.Domain,
+	}
+	if cfg.Secure != nil {
+		opts.Secure = *cfg.Secure
+	}
+	if cfg.Path != "" {
+		opts.Path = cfg.Path
+	}
+
+	switch strings.ToLower(cfg.SameSite) {
+	case "strict":
+		opts.SameSite = http.SameSiteStrictMode
+	case "none":
+		// Браузеры принимают SameSite=None только вместе с Secure
+		opts.SameSite = http.SameSiteNoneMode
+		opts.Secure = true
+	}
+	return opts
+}
+
 // initConfig инициализирует конфигурацию приложения из файла YAML.
 func initConfig() error {
 	data, err := os.ReadFile("configs/config.yaml")
@@ -96,6 +322,13 @@ func initConfig() error {
 		return err
 	}
 
+	// Источник "*" вместе с куки сессии разрешил бы любому сайту запросы от имени пользователя
+	for _, origin := range conf.CORS.AllowedOrigins {
+		if strings.TrimSpace(origin) == "*" {
+			return errors.New("cors.allowedOrigins не может содержать \"*\": укажите источники явно")
+		}
+	}
+
 	config.Config = conf
 	return nil
 }
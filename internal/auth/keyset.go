@@ -0,0 +1,215 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"note_app/internal/config"
+	"os"
+	"sort"
+)
+
+// DefaultKeyID идентификатор ключа, созданного из jwtSecret. Им же проверяются токены без заголовка kid,
+// выданные до появления набора ключей.
+const DefaultKeyID = "default"
+
+// signingKey ключ набора с привязанным к нему алгоритмом.
+type signingKey struct {
+	id        string
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// KeySet набор ключей для подписи и проверки JWT.
+// Токены подписываются активным ключом, а проверяются любым ключом набора, что позволяет менять ключи без простоя:
+// новый ключ добавляется и делается активным, старый остается для проверки, пока не истекут выданные им токены.
+type KeySet struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// JWK открытый ключ в формате JSON Web Key.
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// NewHMACKeySet создает набор из одного ключа HS256 с идентификатором DefaultKeyID.
+func NewHMACKeySet(secret []byte) *KeySet {
+	key := &signingKey{id: DefaultKeyID, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}
+	return &KeySet{active: key, keys: map[string]*signingKey{key.id: key}}
+}
+
+// LoadKeySet создает набор ключей из конфигурации. Если ключи не заданы, используется HS256 с jwtSecret.
+func LoadKeySet(cfg config.JWTConfig, legacySecret string) (*KeySet, error) {
+	if len(cfg.Keys) == 0 {
+		if legacySecret == "" {
+			return nil, errors.New("не задан ни jwtSecret, ни ключи jwt.keys")
+		}
+		return NewHMACKeySet([]byte(legacySecret)), nil
+	}
+
+	set := &KeySet{keys: make(map[string]*signingKey, len(cfg.Keys))}
+	for _, kc := range cfg.Keys {
+		if kc.ID == "" {
+			return nil, errors.New("у ключа JWT не указан id")
+		}
+		if _, exists := set.keys[kc.ID]; exists {
+			return nil, fmt.Errorf("ключ JWT %s указан несколько раз", kc.ID)
+		}
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("ключ JWT %s: %v", kc.ID, err)
+		}
+		set.keys[kc.ID] = key
+	}
+
+	active, ok := set.keys[cfg.ActiveKey]
+	if !ok {
+		return nil, fmt.Errorf("активный ключ JWT %q не найден", cfg.ActiveKey)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("для активного ключа JWT %s не задан закрытый ключ", active.id)
+	}
+	set.active = active
+	return set, nil
+}
+
+// loadKey загружает ключ из конфигурации. Для асимметричных алгоритмов достаточно открытого ключа,
+// если ключ используется только для проверки.
+func loadKey(kc config.JWTKeyConfig) (*signingKey, error) {
+	key := &signingKey{id: kc.ID}
+
+	switch kc.Algorithm {
+	case "HS256":
+		if kc.Secret == "" {
+			return nil, errors.New("для HS256 нужно указать secret")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(kc.Secret)
+		key.verifyKey = []byte(kc.Secret)
+
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+		if kc.PrivateKeyFile != "" {
+			data, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			if private.N.BitLen() < 2048 {
+				return nil, errors.New("длина ключа RSA должна быть не меньше 2048 бит")
+			}
+			key.signKey = private
+			key.verifyKey = &private.PublicKey
+		} else {
+			data, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = public
+		}
+
+	case "EdDSA":
+		key.method = jwt.SigningMethodEdDSA
+		if kc.PrivateKeyFile != "" {
+			data, err := os.ReadFile(kc.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = private
+			key.verifyKey = private.(ed25519.PrivateKey).Public()
+		} else {
+			data, err := os.ReadFile(kc.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			public, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.verifyKey = public
+		}
+
+	default:
+		return nil, fmt.Errorf("неподдерживаемый алгоритм: %s", kc.Algorithm)
+	}
+
+	return key, nil
+}
+
+// Sign подписывает claims активным ключом и добавляет в заголовок его идентификатор.
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.id
+	return token.SignedString(ks.active.signKey)
+}
+
+// Keyfunc возвращает ключ проверки для токена по заголовку kid.
+// Алгоритм токена должен в точности совпадать с алгоритмом ключа, иначе токен отклоняется.
+func (ks *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = DefaultKeyID
+	}
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный ключ: %s", kid)
+	}
+	if token.Method == nil || token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("алгоритм %v не соответствует ключу %s", token.Header["alg"], kid)
+	}
+	return key.verifyKey, nil
+}
+
+// JWKS возвращает открытые ключи набора. Симметричные ключи не публикуются.
+func (ks *KeySet) JWKS() []JWK {
+	keys := make([]JWK, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		switch public := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kid: key.id,
+				Kty: "RSA",
+				Alg: key.method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kid: key.id,
+				Kty: "OKP",
+				Alg: key.method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Kid < keys[j].Kid })
+	return keys
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/pkg/utils"
+	"strconv"
+	"time"
+)
+
+const (
+	// TwoFactorPurpose назначение токена, подтверждающего первый шаг входа при включенной 2FA.
+	TwoFactorPurpose = "2fa"
+	// OIDCStatePurpose назначение токена с состоянием входа через OpenID Connect.
+	OIDCStatePurpose = "oidc_state"
+)
+
+const (
+	// sessionTTL время жизни токена сессии.
+	sessionTTL = 24 * time.Hour
+	// challengeTokenTTL время жизни токена второго шага входа.
+	challengeTokenTTL = 5 * time.Minute
+	// oidcStateTTL время, за которое пользователь должен завершить вход у провайдера.
+	oidcStateTTL = 10 * time.Minute
+	// defaultIssuer издатель и получатель токенов, если они не заданы в конфигурации.
+	defaultIssuer = "note_app"
+	// tokenIDSize размер идентификатора токена (jti) в байтах.
+	tokenIDSize = 16
+)
+
+// ErrInvalidToken возвращается для токенов с неверной подписью, истекшим сроком или неверными claims.
+var ErrInvalidToken = errors.New("недействительный токен")
+
+// SessionValidator проверяет, что сессия, к которой относится токен, не отозвана.
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, tokenID string) error
+}
+
+// TokenManager выпускает и проверяет все JWT приложения: токены сессии, токены второго шага входа
+// и токены состояния входа через OpenID Connect.
+type TokenManager struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+	sessions SessionValidator
+}
+
+// NewTokenManager создает TokenManager с набором ключей из конфигурации.
+func NewTokenManager(cfg config.JWTConfig, legacySecret string) (*TokenManager, error) {
+	keys, err := LoadKeySet(cfg, legacySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	tm := &TokenManager{
+		keys:     keys,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   time.Duration(cfg.ClockSkew) * time.Second,
+		now:      time.Now,
+	}
+	if tm.issuer == "" {
+		tm.issuer = defaultIssuer
+	}
+	if tm.audience == "" {
+		tm.audience = defaultIssuer
+	}
+	return tm, nil
+}
+
+// Keys возвращает набор ключей, например для публикации JWKS.
+func (tm *TokenManager) Keys() *KeySet {
+	return tm.keys
+}
+
+// UseSessions включает проверку сессий: токены сессии принимаются, только если validator подтверждает их сессию.
+func (tm *TokenManager) UseSessions(validator SessionValidator) {
+	tm.sessions = validator
+}
+
+// IssueSession создает токен сессии пользователя. Вместе с токеном возвращаются его claims,
+// чтобы вызывающий мог сохранить сессию по идентификатору токена.
+func (tm *TokenManager) IssueSession(userID int, role string) (string, *models.Claims, error) {
+	registered, err := tm.registeredClaims(userID, sessionTTL)
+	if err != nil {
+		return "", nil, err
+	}
+	claims := &models.Claims{UserID: userID, Role: role, RegisteredClaims: registered}
+	tokenString, err := tm.keys.Sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return tokenString, claims, nil
+}
+
+// ParseSession проверяет токен сессии и возвращает его claims.
+// Токены с особым назначением (например, токен второго шага входа) и токены отозванных сессий отклоняются.
+func (tm *TokenManager) ParseSession(tokenString string) (*models.Claims, error) {
+	claims, err := tm.parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New("токен не является токеном сессии")
+	}
+	if tm.sessions != nil {
+		if err := tm.sessions.ValidateSession(context.Background(), claims.ID); err != nil {
+			return nil, err
+		}
+	}
+	return claims, nil
+}
+
+// IssueChallenge создает короткоживущий токен, который нужно обменять на токен сессии, введя код 2FA.
+func (tm *TokenManager) IssueChallenge(userID int) (string, error) {
+	registered, err := tm.registeredClaims(userID, challengeTokenTTL)
+	if err != nil {
+		return "", err
+	}
+	return tm.keys.Sign(&models.Claims{UserID: userID, Purpose: TwoFactorPurpose, RegisteredClaims: registered})
+}
+
+// ParseChallenge проверяет токен второго шага входа и возвращает его claims. Идентификатор токена (jti)
+// позволяет ограничить число попыток ввода кода с одним токеном.
+func (tm *TokenManager) ParseChallenge(tokenString string) (*models.Claims, error) {
+	claims, err := tm.parseClaims(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != TwoFactorPurpose {
+		return nil, errors.New("токен не является токеном второго шага входа")
+	}
+	return claims, nil
+}
+
+// IssueOIDCState подписывает состояние входа через OpenID Connect для хранения в куки.
+func (tm *TokenManager) IssueOIDCState(state *models.OIDCStateClaims) (string, error) {
+	registered, err := tm.registeredClaims(state.LinkUserID, oidcStateTTL)
+	if err != nil {
+		return "", err
+	}
+	state.Purpose = OIDCStatePurpose
+	state.RegisteredClaims = registered
+	return tm.keys.Sign(state)
+}
+
+// ParseOIDCState проверяет токен с состоянием входа через OpenID Connect.
+func (tm *TokenManager) ParseOIDCState(tokenString string) (*models.OIDCStateClaims, error) {
+	state := &models.OIDCStateClaims{}
+	if err := tm.parse(tokenString, state); err != nil {
+		return nil, errors.New("недействительный токен состояния")
+	}
+	if state.Purpose != OIDCStatePurpose || state.Subject != strconv.Itoa(state.LinkUserID) {
+		return nil, errors.New("недействительный токен состояния")
+	}
+	return state, nil
+}
+
+// registeredClaims заполняет стандартные claims: издателя, получателя, субъекта, время выдачи,
+// начало и окончание срока действия и уникальный идентификатор токена.
+func (tm *TokenManager) registeredClaims(userID int, ttl time.Duration) (jwt.RegisteredClaims, error) {
+	id, err := utils.GenerateRandomToken(tokenIDSize)
+	if err != nil {
+		return jwt.RegisteredClaims{}, fmt.Errorf("не удалось сгенерировать идентификатор токена: %v", err)
+	}
+
+	now := tm.now()
+	return jwt.RegisteredClaims{
+		Issuer:    tm.issuer,
+		Subject:   strconv.Itoa(userID),
+		Audience:  jwt.ClaimStrings{tm.audience},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        id,
+	}, nil
+}
+
+// parseClaims проверяет токен и соответствие субъекта идентификатору пользователя.
+func (tm *TokenManager) parseClaims(tokenString string) (*models.Claims, error) {
+	claims := &models.Claims{}
+	if err := tm.parse(tokenString, claims); err != nil {
+		return nil, err
+	}
+	if claims.Subject != strconv.Itoa(claims.UserID) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// parse проверяет подпись, алгоритм и все стандартные claims токена с учетом допустимого расхождения часов.
+// Claims, которые выставляет registeredClaims, обязательны.
+func (tm *TokenManager) parse(tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, tm.keys.Keyfunc,
+		jwt.WithIssuer(tm.issuer),
+		jwt.WithAudience(tm.audience),
+		jwt.WithLeeway(tm.leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(tm.now),
+	)
+	if err != nil || !token.Valid {
+		return ErrInvalidToken
+	}
+
+	subject, _ := claims.GetSubject()
+	notBefore, _ := claims.GetNotBefore()
+	issuedAt, _ := claims.GetIssuedAt()
+	var id string
+	switch c := claims.(type) {
+	case *models.Claims:
+		id = c.ID
+	case *models.OIDCStateClaims:
+		id = c.ID
+	}
+	if subject == "" || notBefore == nil || issuedAt == nil || id == "" {
+		return ErrInvalidToken
+	}
+	return nil
+}
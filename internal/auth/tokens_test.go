@@ -0,0 +1,151 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"testing"
+	"time"
+)
+
+func newTestTokenManager(t *testing.T) *TokenManager {
+	t.Helper()
+	tm, err := NewTokenManager(config.JWTConfig{}, "test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm
+}
+
+// forge подписывает токен сессии пользователя 1 методом method ключом key с заголовком kid, если он не пуст.
+func forge(t *testing.T, tm *TokenManager, method jwt.SigningMethod, key interface{}, kid string) string {
+	t.Helper()
+	registered, err := tm.registeredClaims(1, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := jwt.NewWithClaims(method, &models.Claims{UserID: 1, Role: "admin", RegisteredClaims: registered})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokenString
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	tm := newTestTokenManager(t)
+	tokenString, issued, err := tm.IssueSession(7, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := tm.ParseSession(tokenString)
+	if err != nil {
+		t.Fatalf("ParseSession: %v", err)
+	}
+	if claims.UserID != 7 || claims.Role != "user" || claims.ID != issued.ID {
+		t.Errorf("claims %+v, ожидались claims выданного токена %+v", claims, issued)
+	}
+}
+
+func TestParseRejectsAlgNone(t *testing.T) {
+	tm := newTestTokenManager(t)
+	tokenString := forge(t, tm, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, DefaultKeyID)
+	if _, err := tm.ParseSession(tokenString); err == nil {
+		t.Error("токен с alg none принят")
+	}
+}
+
+func TestParseRejectsAlgorithmConfusion(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &signingKey{id: "rsa", method: jwt.SigningMethodRS256, signKey: private, verifyKey: &private.PublicKey}
+	tm := newTestTokenManager(t)
+	tm.keys = &KeySet{active: key, keys: map[string]*signingKey{key.id: key}}
+
+	if _, err := tm.ParseSession(forge(t, tm, jwt.SigningMethodRS256, private, "rsa")); err != nil {
+		t.Fatalf("токен RS256: %v", err)
+	}
+
+	// Подпись HS256, где секретом служит открытый ключ RSA, известный всем
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if _, err := tm.ParseSession(forge(t, tm, jwt.SigningMethodHS256, public, "rsa")); err == nil {
+		t.Error("токен HS256, подписанный открытым ключом RSA, принят")
+	}
+}
+
+func TestParseRejectsKeyIDConfusion(t *testing.T) {
+	first := &signingKey{id: "first", method: jwt.SigningMethodHS256, signKey: []byte("first-secret"), verifyKey: []byte("first-secret")}
+	second := &signingKey{id: "second", method: jwt.SigningMethodHS256, signKey: []byte("second-secret"), verifyKey: []byte("second-secret")}
+	tm := newTestTokenManager(t)
+	tm.keys = &KeySet{active: second, keys: map[string]*signingKey{first.id: first, second.id: second}}
+
+	// Токен, выданный прежним ключом, проверяется этим ключом
+	if _, err := tm.ParseSession(forge(t, tm, jwt.SigningMethodHS256, first.signKey, "first")); err != nil {
+		t.Fatalf("токен прежнего ключа: %v", err)
+	}
+
+	tests := map[string]string{
+		"чужой kid":       forge(t, tm, jwt.SigningMethodHS256, first.signKey, "second"),
+		"неизвестный kid": forge(t, tm, jwt.SigningMethodHS256, first.signKey, "unknown"),
+		// Без kid токен проверяется ключом DefaultKeyID, которого нет в наборе
+		"без kid": forge(t, tm, jwt.SigningMethodHS256, first.signKey, ""),
+	}
+	for name, tokenString := range tests {
+		if _, err := tm.ParseSession(tokenString); err == nil {
+			t.Errorf("%s: токен принят", name)
+		}
+	}
+}
+
+func TestParseRejectsInvalidClaims(t *testing.T) {
+	tm := newTestTokenManager(t)
+	other, err := NewTokenManager(config.JWTConfig{Issuer: "other"}, "test-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign, _, err := other.IssueSession(1, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.ParseSession(foreign); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("токен другого издателя: ожидалась ErrInvalidToken, получено %v", err)
+	}
+
+	tokenString, _, err := tm.IssueSession(1, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.now = func() time.Time { return time.Now().Add(sessionTTL + time.Minute) }
+	if _, err := tm.ParseSession(tokenString); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("истекший токен: ожидалась ErrInvalidToken, получено %v", err)
+	}
+}
+
+func TestParseSessionRejectsChallenge(t *testing.T) {
+	tm := newTestTokenManager(t)
+	challenge, err := tm.IssueChallenge(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.ParseSession(challenge); err == nil {
+		t.Error("токен второго шага входа принят как токен сессии")
+	}
+	claims, err := tm.ParseChallenge(challenge)
+	if err != nil || claims.UserID != 1 || claims.ID == "" {
+		t.Errorf("ParseChallenge: %+v, %v", claims, err)
+	}
+}
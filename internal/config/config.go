@@ -15,17 +15,270 @@ type DBConfig struct {
 	DBName   string `yaml:"db_name"`
 }
 
+// SMTPConfig представляет параметры подключения к SMTP-серверу.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// MailConfig представляет конфигурацию отправки писем.
+type MailConfig struct {
+	// Driver способ отправки: "smtp" или "log" (запись писем в файл или лог).
+	Driver  string     `yaml:"driver"`
+	From    string     `yaml:"from"`
+	LogFile string     `yaml:"logFile"`
+	SMTP    SMTPConfig `yaml:"smtp"`
+}
+
+// OIDCConfig представляет настройки входа через OpenID Connect провайдера.
+type OIDCConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"clientID"`
+	ClientSecret string   `yaml:"clientSecret"`
+	RedirectURL  string   `yaml:"redirectURL"`
+	Scopes       []string `yaml:"scopes"`
+	// AutoProvision создавать пользователя при первом входе через провайдера.
+	AutoProvision bool `yaml:"autoProvision"`
+	// LinkByEmail привязывать внешнюю учетную запись к пользователю с тем же подтвержденным email.
+	LinkByEmail bool `yaml:"linkByEmail"`
+}
+
+// CookieConfig представляет атрибуты куки с токеном сессии.
+type CookieConfig struct {
+	// Secure отправлять куки только по HTTPS. По умолчанию true.
+	Secure *bool `yaml:"secure"`
+	// SameSite значение атрибута SameSite: strict, lax или none. По умолчанию lax.
+	SameSite string `yaml:"sameSite"`
+	Path     string `yaml:"path"`
+	Domain   string `yaml:"domain"`
+}
+
+// CORSConfig представляет настройки CORS.
+type CORSConfig struct {
+	// AllowedOrigins источники, которым разрешены запросы из браузера.
+	AllowedOrigins []string `yaml:"allowedOrigins"`
+	// MaxAge время кэширования ответа на предварительный запрос в секундах.
+	MaxAge int `yaml:"maxAge"`
+}
+
+// PasswordHashConfig представляет параметры хэширования паролей.
+type PasswordHashConfig struct {
+	// Algorithm алгоритм хэширования новых паролей: argon2id или bcrypt.
+	Algorithm  string `yaml:"algorithm"`
+	BcryptCost int    `yaml:"bcryptCost"`
+	// Argon2Memory объем памяти argon2id в КиБ.
+	Argon2Memory      uint32 `yaml:"argon2Memory"`
+	Argon2Iterations  uint32 `yaml:"argon2Iterations"`
+	Argon2Parallelism uint8  `yaml:"argon2Parallelism"`
+}
+
+// PasswordConfig представляет требования к паролям и параметры их хэширования.
+type PasswordConfig struct {
+	MinLength int `yaml:"minLength"`
+	MaxLength int `yaml:"maxLength"`
+	// AllowUnicode разрешает любые печатные символы Unicode. Иначе допускаются только печатные символы ASCII.
+	AllowUnicode bool `yaml:"allowUnicode"`
+	// BreachedListFile файл со списком скомпрометированных паролей: по одному паролю или SHA-1 хэшу на строку.
+	BreachedListFile string             `yaml:"breachedListFile"`
+	Hashing          PasswordHashConfig `yaml:"hashing"`
+}
+
+// LocalStorageConfig представляет хранилище файлов в локальном каталоге.
+type LocalStorageConfig struct {
+	Dir string `yaml:"dir"`
+}
+
+// S3StorageConfig представляет S3-совместимое хранилище файлов.
+type S3StorageConfig struct {
+	// Endpoint адрес сервиса, например https://s3.eu-central-1.amazonaws.com или http://localhost:9100.
+	Endpoint  string `yaml:"endpoint"`
+	Region    string `yaml:"region"`
+	Bucket    string `yaml:"bucket"`
+	AccessKey string `yaml:"accessKey"`
+	SecretKey string `yaml:"secretKey"`
+}
+
+// StorageConfig представляет хранилище файлов.
+type StorageConfig struct {
+	// Driver тип хранилища: "local" или "s3".
+	Driver string             `yaml:"driver"`
+	Local  LocalStorageConfig `yaml:"local"`
+	S3     S3StorageConfig    `yaml:"s3"`
+}
+
+// AttachmentsConfig представляет ограничения на вложения заметок.
+type AttachmentsConfig struct {
+	// MaxSize максимальный размер файла в байтах.
+	MaxSize int64 `yaml:"maxSize"`
+	// AllowedTypes допустимые типы содержимого. Тип определяется по содержимому файла, а не по заголовку запроса.
+	AllowedTypes []string      `yaml:"allowedTypes"`
+	Storage      StorageConfig `yaml:"storage"`
+}
+
+// WebhookNotifierConfig представляет отправку уведомлений POST-запросом на адрес URL.
+type WebhookNotifierConfig struct {
+	URL string `yaml:"url"`
+	// Secret ключ подписи тела запроса HMAC-SHA256 (заголовок X-Signature-256). Если пуст, запрос не подписывается.
+	Secret string `yaml:"secret"`
+	// Timeout время ожидания ответа в секундах.
+	Timeout int `yaml:"timeout"`
+}
+
+// RemindersConfig представляет настройки отправки напоминаний о заметках.
+type RemindersConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval период проверки наступивших напоминаний в секундах.
+	Interval int `yaml:"interval"`
+	// BatchSize максимальное число напоминаний, отправляемых за одну проверку.
+	BatchSize int `yaml:"batchSize"`
+	// MaxAttempts число попыток отправки напоминания, после которого оно больше не отправляется.
+	MaxAttempts int `yaml:"maxAttempts"`
+	// Backoff задержка перед первой повторной попыткой в секундах; каждая следующая задержка вдвое больше,
+	// но не более MaxBackoff секунд.
+	Backoff    int `yaml:"backoff"`
+	MaxBackoff int `yaml:"maxBackoff"`
+	// Notifiers способы отправки: "log", "email" и "webhook".
+	Notifiers []string              `yaml:"notifiers"`
+	LogFile   string                `yaml:"logFile"`
+	Webhook   WebhookNotifierConfig `yaml:"webhook"`
+}
+
+// TwoFactorConfig представляет ограничения на ввод кодов двухфакторной аутентификации.
+type TwoFactorConfig struct {
+	// MaxAttempts число попыток ввода кода подряд без успешной, после которого ввод кода пользователем
+	// блокируется на Lockout секунд, а токен второго шага входа становится недействительным.
+	MaxAttempts int `yaml:"maxAttempts"`
+	Lockout     int `yaml:"lockout"`
+}
+
+// ImportConfig представляет ограничения на загрузку заметок из файла.
+type ImportConfig struct {
+	// MaxSize максимальный размер загружаемого файла в байтах. Столько же могут занимать вместе
+	// распакованные файлы ZIP-архива.
+	MaxSize int64 `yaml:"maxSize"`
+	// MaxNotes максимальное число заметок в одном файле.
+	MaxNotes int `yaml:"maxNotes"`
+}
+
+// EventsConfig представляет настройки потока событий заметок (GET /events и WebSocket).
+type EventsConfig struct {
+	// BufferSize число последних событий, хранимых для продолжения потока по Last-Event-ID.
+	BufferSize int `yaml:"bufferSize"`
+	// Heartbeat период отправки пустых сообщений, поддерживающих соединение, в секундах.
+	Heartbeat int `yaml:"heartbeat"`
+}
+
+// WebhooksConfig представляет настройки отправки веб-хуков пользователей.
+type WebhooksConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Interval период проверки очереди доставок в секундах.
+	Interval int `yaml:"interval"`
+	// BatchSize максимальное число доставок, отправляемых за одну проверку.
+	BatchSize int `yaml:"batchSize"`
+	// MaxAttempts число попыток доставки, после которого доставка считается неудачной.
+	MaxAttempts int `yaml:"maxAttempts"`
+	// Backoff задержка перед первой повторной попыткой в секундах, каждая следующая задержка вдвое больше,
+	// но не больше MaxBackoff.
+	Backoff    int `yaml:"backoff"`
+	MaxBackoff int `yaml:"maxBackoff"`
+	// Timeout время ожидания ответа в секундах.
+	Timeout int `yaml:"timeout"`
+	// AllowPrivateNetworks разрешает веб-хуки на адреса во внутренней сети (localhost, 10.0.0.0/8 и т. п.).
+	AllowPrivateNetworks bool `yaml:"allowPrivateNetworks"`
+}
+
+// OutboxConfig представляет настройки пересылки событий заметок из таблицы исходящих событий.
+type OutboxConfig struct {
+	// Interval период проверки неотправленных событий в секундах. Изменения заметок, сделанные этим экземпляром
+	// приложения, пересылаются сразу.
+	Interval int `yaml:"interval"`
+	// BatchSize максимальное число событий, пересылаемых за одну проверку.
+	BatchSize int `yaml:"batchSize"`
+	// Retention время хранения отправленных и неудачных событий в часах.
+	Retention int `yaml:"retention"`
+	// MaxAttempts число попыток отправки события, после которого оно отмечается неудачным и следующие события
+	// заметки отправляются без него.
+	MaxAttempts int `yaml:"maxAttempts"`
+	// Backoff задержка перед первой повторной попыткой в секундах, каждая следующая задержка вдвое больше,
+	// но не больше MaxBackoff.
+	Backoff    int `yaml:"backoff"`
+	MaxBackoff int `yaml:"maxBackoff"`
+	// Sinks получатели событий: "bus" (потоки GET /events всех экземпляров приложения через PostgreSQL NOTIFY),
+	// "webhooks" (очередь веб-хуков) и "nats".
+	Sinks []string   `yaml:"sinks"`
+	NATS  NATSConfig `yaml:"nats"`
+}
+
+// NATSConfig представляет настройки отправки событий заметок в NATS.
+type NATSConfig struct {
+	// URL адрес сервера, например nats://localhost:4222.
+	URL string `yaml:"url"`
+	// Subject префикс темы: события отправляются в темы <subject>.created, <subject>.updated и <subject>.deleted.
+	Subject string `yaml:"subject"`
+	// Timeout время ожидания подключения и подтверждения сервера в секундах.
+	Timeout int `yaml:"timeout"`
+}
+
+// JWTKeyConfig представляет ключ подписи JWT.
+type JWTKeyConfig struct {
+	ID string `yaml:"id"`
+	// Algorithm алгоритм подписи: HS256, RS256 или EdDSA.
+	Algorithm string `yaml:"algorithm"`
+	// Secret секрет для HS256.
+	Secret string `yaml:"secret"`
+	// PrivateKeyFile PEM-файл закрытого ключа для RS256 и EdDSA.
+	PrivateKeyFile string `yaml:"privateKeyFile"`
+	// PublicKeyFile PEM-файл открытого ключа, если ключ используется только для проверки.
+	PublicKeyFile string `yaml:"publicKeyFile"`
+}
+
+// JWTConfig представляет параметры выпуска JWT и набор ключей для их подписи и проверки.
+type JWTConfig struct {
+	// Issuer значение iss в выдаваемых токенах. Токены другого издателя отклоняются.
+	Issuer string `yaml:"issuer"`
+	// Audience значение aud в выдаваемых токенах. Токены для другого получателя отклоняются.
+	Audience string `yaml:"audience"`
+	// ClockSkew допустимое расхождение часов в секундах при проверке exp, nbf и iat.
+	ClockSkew int `yaml:"clockSkew"`
+	// ActiveKey идентификатор ключа, которым подписываются новые токены.
+	ActiveKey string         `yaml:"activeKey"`
+	Keys      []JWTKeyConfig `yaml:"keys"`
+}
+
 // Configuration представляет общую конфигурацию приложения.
 type Configuration struct {
-	Port      string   `yaml:"port"`
-	JWTSecret string   `yaml:"jwtSecret"`
-	DB        DBConfig `yaml:"db"`
+	Port        string            `yaml:"port"`
+	AppURL      string            `yaml:"appURL"`
+	JWTSecret   string            `yaml:"jwtSecret"`
+	JWT         JWTConfig         `yaml:"jwt"`
+	Password    PasswordConfig    `yaml:"password"`
+	TOTPIssuer  string            `yaml:"totpIssuer"`
+	TwoFactor   TwoFactorConfig   `yaml:"twoFactor"`
+	Admins      []string          `yaml:"admins"`
+	DB          DBConfig          `yaml:"db"`
+	Mail        MailConfig        `yaml:"mail"`
+	OIDC        OIDCConfig        `yaml:"oidc"`
+	Cookie      CookieConfig      `yaml:"cookie"`
+	CORS        CORSConfig        `yaml:"cors"`
+	Attachments AttachmentsConfig `yaml:"attachments"`
+	Reminders   RemindersConfig   `yaml:"reminders"`
+	Import      ImportConfig      `yaml:"import"`
+	Events      EventsConfig      `yaml:"events"`
+	Webhooks    WebhooksConfig    `yaml:"webhooks"`
+	Outbox      OutboxConfig      `yaml:"outbox"`
+}
+
+// DataSourceName возвращает строку подключения к базе данных.
+func (c *DBConfig) DataSourceName() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", c.Host, c.Port, c.User, c.Password, c.DBName)
 }
 
 // Connect подключается к базе данных и возвращает объект db для выполнения запросов.
 func (c *DBConfig) Connect() (*sql.DB, error) {
-	psqlInfo := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable", c.Host, c.Port, c.User, c.Password, c.DBName)
-	db, err := sql.Open("postgres", psqlInfo)
+	db, err := sql.Open("postgres", c.DataSourceName())
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,135 @@
+package events
+
+import (
+	"fmt"
+	"note_app/internal/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBufferSize число хранимых событий, если оно не задано.
+	defaultBufferSize = 1000
+	// subscriberBuffer число событий, ожидающих отправки подписчику. Подписчик, не успевающий получать
+	// события, отключается и может продолжить поток по Last-Event-ID.
+	subscriberBuffer = 64
+)
+
+// Bus шина событий заметок внутри процесса. Хранит последние события, чтобы подписчик мог продолжить
+// поток после переподключения. Идентификатор события состоит из метки запуска процесса и порядкового номера,
+// поэтому идентификаторы, выданные до перезапуска, распознаются как устаревшие.
+type Bus struct {
+	mu          sync.Mutex
+	epoch       string
+	seq         uint64
+	history     []models.NoteEvent
+	next        int
+	subscribers map[*Subscription]struct{}
+}
+
+// NewBus создает шину, хранящую bufferSize последних событий.
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	return &Bus{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		history:     make([]models.NoteEvent, 0, bufferSize),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription подписка на события шины. Канал C закрывается, если подписчик не успевает получать события.
+type Subscription struct {
+	C   <-chan models.NoteEvent
+	ch  chan models.NoteEvent
+	bus *Bus
+}
+
+// Publish назначает событию идентификатор и время и рассылает его подписчикам.
+func (b *Bus) Publish(event models.NoteEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = b.eventID(b.seq)
+	event.Time = time.Now()
+
+	if len(b.history) < cap(b.history) {
+		b.history = append(b.history, event)
+	} else {
+		b.history[b.next] = event
+		b.next = (b.next + 1) % len(b.history)
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.ch <- event:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Resync рассылает подписчикам событие resync, после которого список заметок нужно запросить заново.
+// Используется, если часть событий могла быть потеряна до попадания в шину.
+func (b *Bus) Resync() {
+	b.Publish(models.NoteEvent{Type: models.EventResync})
+}
+
+// Subscribe подписывается на события и возвращает события после lastEventID, которые нужно отправить
+// до новых. Если часть событий после lastEventID уже не хранится или идентификатор выдан до перезапуска,
+// вместо них возвращается одно событие resync.
+func (b *Bus) Subscribe(lastEventID string) (*Subscription, []models.NoteEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan models.NoteEvent, subscriberBuffer)
+	sub := &Subscription{C: ch, ch: ch, bus: b}
+	b.subscribers[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil
+	}
+	lastSeq, ok := b.parseEventID(lastEventID)
+	if !ok || lastSeq > b.seq || b.seq-lastSeq > uint64(len(b.history)) {
+		return sub, []models.NoteEvent{{ID: b.eventID(b.seq), Type: models.EventResync, Time: time.Now()}}
+	}
+
+	missed := make([]models.NoteEvent, 0, b.seq-lastSeq)
+	for i := 0; i < len(b.history); i++ {
+		event := b.history[(b.next+i)%len(b.history)]
+		if seq, _ := b.parseEventID(event.ID); seq > lastSeq {
+			missed = append(missed, event)
+		}
+	}
+	return sub, missed
+}
+
+// Close отменяет подписку.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subscribers[s]; ok {
+		delete(s.bus.subscribers, s)
+		close(s.ch)
+	}
+}
+
+// eventID возвращает идентификатор события с порядковым номером seq.
+func (b *Bus) eventID(seq uint64) string {
+	return fmt.Sprintf("%s-%d", b.epoch, seq)
+}
+
+// parseEventID возвращает порядковый номер события текущего запуска.
+func (b *Bus) parseEventID(id string) (uint64, bool) {
+	epoch, seq, found := strings.Cut(id, "-")
+	if !found || epoch != b.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
@@ -0,0 +1,92 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"note_app/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader столбцы CSV-выгрузки.
+var csvHeader = []string{
+	"id", "title", "format", "created_at", "due_at", "remind_at", "pinned", "favorite", "archived",
+	"tags", "folder", "visibility", "text",
+}
+
+// CSVWriter записывает заметки в виде CSV-таблицы с заголовком. Пункты списков задач содержатся в тексте,
+// метки перечисляются через запятую.
+type CSVWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// NewCSVWriter создает CSVWriter.
+func NewCSVWriter(w io.Writer) *CSVWriter {
+	return &CSVWriter{w: csv.NewWriter(w)}
+}
+
+// WriteNote добавляет строку с заметкой.
+func (cw *CSVWriter) WriteNote(note *models.ExportedNote) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	record := []string{
+		strconv.Itoa(note.ID),
+		note.Title,
+		note.Format,
+		note.CreatedAt.Format(time.RFC3339),
+		formatOptionalTime(note.DueAt),
+		formatOptionalTime(note.RemindAt),
+		strconv.FormatBool(note.Pinned),
+		strconv.FormatBool(note.Favorite),
+		strconv.FormatBool(note.Archived),
+		strings.Join(note.Tags, ","),
+		note.Folder,
+		note.Visibility,
+		note.Text,
+	}
+	if err := cw.w.Write(record); err != nil {
+		return err
+	}
+	// Строки отправляются клиенту сразу, а не накапливаются в буфере
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// Close записывает заголовок, если заметок не было, и сбрасывает буфер.
+func (cw *CSVWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// ContentType возвращает тип содержимого CSV.
+func (cw *CSVWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+// Extension возвращает расширение файла CSV.
+func (cw *CSVWriter) Extension() string {
+	return "csv"
+}
+
+// writeHeader записывает строку заголовка один раз.
+func (cw *CSVWriter) writeHeader() error {
+	if cw.headerWritten {
+		return nil
+	}
+	cw.headerWritten = true
+	return cw.w.Write(csvHeader)
+}
+
+// formatOptionalTime форматирует время в RFC 3339, пустая строка для незаданного времени.
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,40 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"note_app/internal/models"
+)
+
+// Форматы выгрузки заметок.
+const (
+	FormatJSON        = "json"
+	FormatMarkdownZip = "markdown-zip"
+	FormatCSV         = "csv"
+)
+
+// Writer записывает заметки в выгрузку по одной, не накапливая их в памяти.
+type Writer interface {
+	// WriteNote добавляет заметку в выгрузку.
+	WriteNote(note *models.ExportedNote) error
+	// Close дописывает окончание выгрузки. Поток, в который пишется выгрузка, не закрывается.
+	Close() error
+	// ContentType тип содержимого выгрузки.
+	ContentType() string
+	// Extension расширение файла выгрузки.
+	Extension() string
+}
+
+// New создает Writer выгрузки в формате format, записывающий в w.
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatJSON, "":
+		return NewJSONWriter(w), nil
+	case FormatMarkdownZip:
+		return NewMarkdownZipWriter(w), nil
+	case FormatCSV:
+		return NewCSVWriter(w), nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат выгрузки: %s", format)
+	}
+}
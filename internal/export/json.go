@@ -0,0 +1,52 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"note_app/internal/models"
+)
+
+// JSONWriter записывает заметки в виде JSON-массива.
+type JSONWriter struct {
+	w       io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+// NewJSONWriter создает JSONWriter.
+func NewJSONWriter(w io.Writer) *JSONWriter {
+	return &JSONWriter{w: w, encoder: json.NewEncoder(w)}
+}
+
+// WriteNote добавляет заметку в массив.
+func (jw *JSONWriter) WriteNote(note *models.ExportedNote) error {
+	separator := ","
+	if jw.count == 0 {
+		separator = "["
+	}
+	if _, err := io.WriteString(jw.w, separator); err != nil {
+		return err
+	}
+	jw.count++
+	return jw.encoder.Encode(note)
+}
+
+// Close закрывает массив.
+func (jw *JSONWriter) Close() error {
+	end := "]\n"
+	if jw.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(jw.w, end)
+	return err
+}
+
+// ContentType возвращает тип содержимого JSON.
+func (jw *JSONWriter) ContentType() string {
+	return "application/json; charset=utf-8"
+}
+
+// Extension возвращает расширение файла JSON.
+func (jw *JSONWriter) Extension() string {
+	return "json"
+}
@@ -0,0 +1,89 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"note_app/internal/models"
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength максимальная длина части имени файла, полученной из заголовка заметки, в символах.
+const maxSlugLength = 50
+
+// MarkdownZipWriter записывает заметки в ZIP-архив: каждая заметка — отдельный Markdown-файл с YAML-заголовком
+// (front matter), в котором указаны идентификатор, заголовок, формат, время создания, сроки, отметки, метки, папка
+// и видимость заметки.
+type MarkdownZipWriter struct {
+	zip *zip.Writer
+}
+
+// NewMarkdownZipWriter создает MarkdownZipWriter.
+func NewMarkdownZipWriter(w io.Writer) *MarkdownZipWriter {
+	return &MarkdownZipWriter{zip: zip.NewWriter(w)}
+}
+
+// WriteNote добавляет файл заметки в архив.
+func (mw *MarkdownZipWriter) WriteNote(note *models.ExportedNote) error {
+	frontMatter, err := yaml.Marshal(note)
+	if err != nil {
+		return err
+	}
+	file, err := mw.zip.CreateHeader(&zip.FileHeader{
+		Name:     fmt.Sprintf("%d-%s.md", note.ID, slug(note.Title)),
+		Method:   zip.Deflate,
+		Modified: note.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(file, "---\n%s---\n\n%s\n", frontMatter, note.Text); err != nil {
+		return err
+	}
+	// Файл отправляется клиенту сразу, а не накапливается в буфере архива
+	return mw.zip.Flush()
+}
+
+// Close дописывает оглавление архива.
+func (mw *MarkdownZipWriter) Close() error {
+	return mw.zip.Close()
+}
+
+// ContentType возвращает тип содержимого ZIP-архива.
+func (mw *MarkdownZipWriter) ContentType() string {
+	return "application/zip"
+}
+
+// Extension возвращает расширение файла ZIP-архива.
+func (mw *MarkdownZipWriter) Extension() string {
+	return "zip"
+}
+
+// slug возвращает часть имени файла из заголовка: буквы и цифры в нижнем регистре, остальные символы
+// заменяются дефисом.
+func slug(title string) string {
+	var b strings.Builder
+	length := 0
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if length >= maxSlugLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			length++
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			length++
+			dash = true
+		}
+	}
+	s := strings.TrimSuffix(b.String(), "-")
+	if s == "" {
+		return "note"
+	}
+	return s
+}
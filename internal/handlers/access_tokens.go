@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// AccessTokenHandler обрабатывает запросы на управление персональными токенами доступа.
+// Управлять токенами можно только с токеном сессии, но не с другим персональным токеном.
+type AccessTokenHandler struct {
+	AccessTokenService *services.AccessTokenService
+	Tokens             *auth.TokenManager
+}
+
+// NewAccessTokenHandler создает новый экземпляр AccessTokenHandler.
+func NewAccessTokenHandler(accessTokenService *services.AccessTokenService, tokens *auth.TokenManager) *AccessTokenHandler {
+	return &AccessTokenHandler{
+		AccessTokenService: accessTokenService,
+		Tokens:             tokens,
+	}
+}
+
+// CreateToken создает персональный токен доступа.
+// @Summary Создание токена доступа
+// @Description Создает персональный токен для скриптов и интеграций. Токен передается в заголовке Authorization: Bearer и возвращается только один раз. Области действия: notes:read, notes:write.
+// @Accept json
+// @Produce json
+// @Param body body models.AccessTokenInput true "Название, области действия и срок токена"
+// @Router /me/tokens [post]
+func (h *AccessTokenHandler) CreateToken(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	var input models.AccessTokenInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	token, err := h.AccessTokenService.CreateToken(context.Background(), claims.UserID, input)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAccessTokenInput) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Укажите название токена и хотя бы одну допустимую область действия"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании токена доступа"})
+		return
+	}
+
+	c.JSON(http.StatusOK, token)
+}
+
+// GetTokens возвращает персональные токены доступа текущего пользователя.
+// @Summary Список токенов доступа
+// @Description Возвращает действующие персональные токены текущего пользователя без их значений
+// @Produce json
+// @Router /me/tokens [get]
+func (h *AccessTokenHandler) GetTokens(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.AccessTokenService.GetTokens(context.Background(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении токенов доступа"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// RevokeToken отзывает персональный токен доступа.
+// @Summary Отзыв токена доступа
+// @Description Отзывает персональный токен текущего пользователя
+// @Produce json
+// @Param id path int true "Идентификатор токена"
+// @Router /me/tokens/{id} [delete]
+func (h *AccessTokenHandler) RevokeToken(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор токена"})
+		return
+	}
+
+	if err := h.AccessTokenService.RevokeToken(context.Background(), claims.UserID, tokenID); err != nil {
+		if errors.Is(err, services.ErrAccessTokenNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Токен доступа не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отзыве токена доступа"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Токен доступа отозван"})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"note_app/pkg/utils"
+)
+
+// AccountHandler обрабатывает запросы на подтверждение email и восстановление пароля.
+type AccountHandler struct {
+	AccountService *services.AccountService
+	Tokens         *auth.TokenManager
+}
+
+// NewAccountHandler создает новый экземпляр AccountHandler.
+func NewAccountHandler(accountService *services.AccountService, tokens *auth.TokenManager) *AccountHandler {
+	return &AccountHandler{
+		AccountService: accountService,
+		Tokens:         tokens,
+	}
+}
+
+// ChangeEmail привязывает адрес электронной почты к текущему пользователю.
+// @Summary Изменение email
+// @Description Сохраняет новый адрес электронной почты и отправляет на него письмо для подтверждения
+// @Accept json
+// @Produce json
+// @Param body body models.EmailInput true "Новый адрес электронной почты"
+// @Router /me/email [put]
+func (h *AccountHandler) ChangeEmail(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	var input models.EmailInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := utils.ValidateEmail(input.Email); err != nil {
+		c.JSON(err.Code, gin.H{"error": err.Message})
+		return
+	}
+
+	if err := h.AccountService.ChangeEmail(context.Background(), claims.UserID, input.Email); err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Адрес электронной почты уже используется"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении адреса электронной почты"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Письмо для подтверждения адреса отправлено"})
+}
+
+// ResendVerification повторно отправляет письмо для подтверждения email.
+// @Summary Повторная отправка письма подтверждения
+// @Description Отправляет новое письмо для подтверждения текущего адреса электронной почты
+// @Produce json
+// @Router /me/email/resend [post]
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	if err := h.AccountService.SendEmailVerification(context.Background(), claims.UserID); err != nil {
+		if errors.Is(err, services.ErrEmailNotSet) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Адрес электронной почты не указан"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке письма"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Письмо для подтверждения адреса отправлено"})
+}
+
+// VerifyEmail подтверждает адрес электронной почты по токену из письма.
+// @Summary Подтверждение email
+// @Description Подтверждает адрес электронной почты. Токен передается в параметре token или в теле запроса.
+// @Accept json
+// @Produce json
+// @Param token query string false "Токен из письма"
+// @Param body body models.TokenInput false "Токен из письма"
+// @Router /me/email/verify [get]
+// @Router /me/email/verify [post]
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		var input models.TokenInput
+		if err := c.BindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+			return
+		}
+		token = input.Token
+	}
+
+	if err := h.AccountService.VerifyEmail(context.Background(), token); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Недействительный или просроченный токен"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подтверждении адреса"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Адрес электронной почты подтвержден"})
+}
+
+// ForgotPassword отправляет письмо для восстановления пароля.
+// @Summary Запрос восстановления пароля
+// @Description Отправляет ссылку для сброса пароля на подтвержденный email. Ответ не зависит от существования пользователя.
+// @Accept json
+// @Produce json
+// @Param body body models.ForgotPasswordInput true "Имя пользователя или email"
+// @Router /password/forgot [post]
+func (h *AccountHandler) ForgotPassword(c *gin.Context) {
+	var input models.ForgotPasswordInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.AccountService.RequestPasswordReset(context.Background(), input.Username, input.Email); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отправке письма"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Если учетная запись существует и email подтвержден, на него отправлено письмо"})
+}
+
+// ResetPassword устанавливает новый пароль по токену из письма.
+// @Summary Сброс пароля
+// @Description Устанавливает новый пароль по одноразовому токену из письма. Все сессии и персональные токены доступа пользователя отзываются.
+// @Accept json
+// @Produce json
+// @Param body body models.ResetPasswordInput true "Токен из письма и новый пароль"
+// @Router /password/reset [post]
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var input models.ResetPasswordInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.AccountService.ValidatePassword(input.Password); err != nil {
+		c.JSON(err.Code, gin.H{"error": err.Message})
+		return
+	}
+
+	if err := h.AccountService.ResetPassword(context.Background(), input.Token, input.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Недействительный или просроченный токен"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при сбросе пароля"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Пароль успешно изменен"})
+}
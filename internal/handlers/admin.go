@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// AdminHandler обрабатывает запросы административного API.
+// Все маршруты должны быть защищены middleware RequireRole с ролью администратора.
+type AdminHandler struct {
+	AdminService *services.AdminService
+}
+
+// NewAdminHandler создает новый экземпляр AdminHandler.
+func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
+	return &AdminHandler{AdminService: adminService}
+}
+
+// ListUsers возвращает список пользователей с поиском и постраничной навигацией.
+// @Summary Список пользователей
+// @Description Возвращает пользователей, имя или email которых содержит строку q. Доступно только администраторам.
+// @Produce json
+// @Param q query string false "Строка поиска"
+// @Param page query int false "Номер страницы"
+// @Param limit query int false "Количество записей на странице"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	users, err := h.AdminService.ListUsers(c.Query("q"), (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении списка пользователей"})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// DisableUser блокирует учетную запись пользователя.
+// @Summary Блокировка пользователя
+// @Description Блокирует учетную запись: пользователь не может войти, а выданные токены перестают приниматься. Доступно только администраторам.
+// @Produce json
+// @Param id path int true "Идентификатор пользователя"
+// @Router /admin/users/{id}/disable [post]
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// EnableUser разблокирует учетную запись пользователя.
+// @Summary Разблокировка пользователя
+// @Description Разблокирует учетную запись пользователя. Доступно только администраторам.
+// @Produce json
+// @Param id path int true "Идентификатор пользователя"
+// @Router /admin/users/{id}/enable [post]
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+// setDisabled изменяет статус блокировки пользователя из параметра id.
+func (h *AdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	// Администратор не может заблокировать сам себя и потерять доступ к API
+	if disabled && userID == currentClaims(c).UserID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя заблокировать собственную учетную запись"})
+		return
+	}
+
+	if err := h.AdminService.SetUserDisabled(userID, disabled); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении статуса пользователя"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Статус пользователя изменен"})
+}
+
+// SetUserRole изменяет роль пользователя.
+// @Summary Изменение роли пользователя
+// @Description Назначает пользователю роль user или admin. Доступно только администраторам.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор пользователя"
+// @Param body body models.RoleInput true "Новая роль"
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор пользователя"})
+		return
+	}
+
+	var input models.RoleInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if userID == currentClaims(c).UserID && input.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нельзя снять роль администратора с самого себя"})
+		return
+	}
+
+	if err := h.AdminService.SetUserRole(userID, input.Role); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidRole):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная роль"})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении роли пользователя"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Роль пользователя изменена"})
+}
+
+// DeleteNote удаляет любую заметку.
+// @Summary Удаление заметки администратором
+// @Description Удаляет заметку независимо от автора. Доступно только администраторам.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /admin/notes/{id} [delete]
+func (h *AdminHandler) DeleteNote(c *gin.Context) {
+	noteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор заметки"})
+		return
+	}
+
+	if err := h.AdminService.DeleteNote(context.Background(), noteID); err != nil {
+		if errors.Is(err, services.ErrNoteNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Заметка не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении заметки"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Заметка успешно удалена"})
+}
+
+// GetStats возвращает сводную статистику.
+// @Summary Статистика
+// @Description Возвращает количество пользователей и заметок. Доступно только администраторам.
+// @Produce json
+// @Router /admin/stats [get]
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	stats, err := h.AdminService.GetStats(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении статистики"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"mime"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// multipartOverhead запас к размеру файла на заголовки и границы multipart-запроса.
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler обрабатывает запросы на загрузку, скачивание и удаление вложений заметок.
+type AttachmentHandler struct {
+	AttachmentService *services.AttachmentService
+	NoteService       services.NoteService
+	Tokens            *auth.TokenManager
+}
+
+// NewAttachmentHandler создает новый экземпляр AttachmentHandler.
+func NewAttachmentHandler(attachmentService *services.AttachmentService, noteService services.NoteService, tokens *auth.TokenManager) *AttachmentHandler {
+	return &AttachmentHandler{
+		AttachmentService: attachmentService,
+		NoteService:       noteService,
+		Tokens:            tokens,
+	}
+}
+
+// UploadAttachment прикрепляет файл к заметке.
+// @Summary Загрузка вложения
+// @Description Прикрепляет файл к заметке текущего пользователя. Файл передается в поле file формы multipart/form-data. Тип файла определяется по содержимому и должен входить в список допустимых, размер ограничен настройками.
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param file formData file true "Файл"
+// @Router /notes/{id}/attachments [post]
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := h.ownNote(c, claims)
+	if !ok {
+		return
+	}
+
+	// Читаем тело потоком, не сохраняя форму в память целиком
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.AttachmentService.MaxSize()+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ожидается запрос multipart/form-data"})
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не передан файл в поле file"})
+			return
+		}
+		if err != nil {
+			writeUploadError(c, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		attachment, err := h.AttachmentService.Upload(context.Background(), note.ID, claims.UserID, part.FileName(), part)
+		part.Close()
+		if err != nil {
+			writeUploadError(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, attachment)
+		return
+	}
+}
+
+// writeUploadError отправляет ответ с ошибкой загрузки вложения.
+func writeUploadError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.Is(err, services.ErrAttachmentTooLarge), errors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Размер файла превышает допустимый"})
+	case errors.Is(err, services.ErrAttachmentTypeNotAllowed):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Недопустимый тип файла"})
+	case errors.Is(err, services.ErrAttachmentEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Файл пуст"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке файла"})
+	}
+}
+
+// GetAttachments возвращает вложения заметки.
+// @Summary Список вложений
+// @Description Возвращает имена, типы и размеры файлов, прикрепленных к заметке.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/attachments [get]
+func (h *AttachmentHandler) GetAttachments(c *gin.Context) {
+	if _, ok := authenticate(c, h.Tokens); !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.AttachmentService.GetAttachments(context.Background(), note.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении вложений"})
+		return
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment отдает содержимое вложения.
+// @Summary Скачивание вложения
+// @Description Отдает файл потоком. Поддерживаются запросы части файла с заголовком Range.
+// @Produce octet-stream
+// @Param id path int true "Идентификатор заметки"
+// @Param attachmentId path int true "Идентификатор вложения"
+// @Router /notes/{id}/attachments/{attachmentId} [get]
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	if _, ok := authenticate(c, h.Tokens); !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+	attachmentID, ok := attachmentIDParam(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := h.AttachmentService.Open(c.Request.Context(), note.ID, attachmentID)
+	if err != nil {
+		if errors.Is(err, services.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Вложение не найдено"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении вложения"})
+		return
+	}
+	defer content.Close()
+
+	// Файл всегда скачивается, а не открывается в браузере, и тип не переопределяется браузером
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, "", attachment.CreatedAt, content)
+}
+
+// DeleteAttachment удаляет вложение заметки.
+// @Summary Удаление вложения
+// @Description Удаляет файл, прикрепленный к заметке текущего пользователя.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param attachmentId path int true "Идентификатор вложения"
+// @Router /notes/{id}/attachments/{attachmentId} [delete]
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := h.ownNote(c, claims)
+	if !ok {
+		return
+	}
+	attachmentID, ok := attachmentIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.AttachmentService.DeleteAttachment(context.Background(), note.ID, attachmentID); err != nil {
+		if errors.Is(err, services.ErrAttachmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Вложение не найдено"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при удалении вложения"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Вложение удалено"})
+}
+
+// ownNote возвращает заметку из параметра id, если она принадлежит текущему пользователю.
+func (h *AttachmentHandler) ownNote(c *gin.Context, claims *models.Claims) (*models.Note, bool) {
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return nil, false
+	}
+	if note.UserID != claims.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Изменять вложения может только автор заметки"})
+		return nil, false
+	}
+	return note, true
+}
+
+// attachmentIDParam возвращает идентификатор вложения из параметра attachmentId.
+func attachmentIDParam(c *gin.Context) (int, bool) {
+	attachmentID, err := strconv.Atoi(c.Param("attachmentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор вложения"})
+		return 0, false
+	}
+	return attachmentID, true
+}
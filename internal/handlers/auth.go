@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"note_app/pkg/utils"
+	"strings"
+)
+
+// Способы передачи токена в запросе.
+const (
+	authSourceCookie = "cookie"
+	authSourceBearer = "bearer"
+)
+
+// authenticate возвращает claims пользователя, выполнившего запрос.
+// Если запрос уже проверен middleware Authenticate, используются его результаты,
+// иначе проверяется токен сессии из заголовка Authorization или куки.
+// Изменяющие запросы с токеном из куки дополнительно должны содержать CSRF-токен.
+// При ошибке записывает ответ 401 (или 403) и возвращает false.
+func authenticate(c *gin.Context, tokens *auth.TokenManager) (*models.Claims, bool) {
+	if value, ok := c.Get(claimsKey); ok {
+		// Персональный токен доступа действует только на маршрутах, явно разрешивших его через RequireScope
+		if _, viaAccessToken := c.Get(accessTokenKey); viaAccessToken && !c.GetBool(scopeCheckedKey) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Токен доступа не дает права на это действие"})
+			return nil, false
+		}
+		if !checkCSRF(c, c.GetString(authSourceKey)) {
+			return nil, false
+		}
+		return value.(*models.Claims), true
+	}
+
+	tokenString, source := requestToken(c)
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+		return nil, false
+	}
+
+	claims, err := tokens.ParseSession(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+		return nil, false
+	}
+
+	if !checkCSRF(c, source) {
+		return nil, false
+	}
+	return claims, true
+}
+
+// checkCSRF проверяет CSRF-токен (double-submit) для изменяющих запросов с токеном из куки.
+// Запросы с заголовком Authorization не проверяются: браузер не добавляет его автоматически.
+func checkCSRF(c *gin.Context, source string) bool {
+	if source != authSourceCookie {
+		return true
+	}
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := c.Cookie(utils.CSRFCookieName)
+	header := c.GetHeader(utils.CSRFHeaderName)
+	if err != nil || cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Неверный или отсутствующий CSRF-токен"})
+		return false
+	}
+	return true
+}
+
+// requestToken извлекает токен из заголовка Authorization: Bearer или, если его нет, из куки.
+// Возвращает токен и способ его передачи.
+func requestToken(c *gin.Context) (string, string) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		const prefix = "Bearer "
+		if len(header) > len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+			return strings.TrimSpace(header[len(prefix):]), authSourceBearer
+		}
+		return "", ""
+	}
+
+	tokenString, err := c.Cookie(utils.TokenCookieName)
+	if err != nil {
+		return "", ""
+	}
+	return tokenString, authSourceCookie
+}
+
+// issueSession выдает пользователю токен сессии: сохраняет сессию, устанавливает куки с токеном и CSRF-токеном
+// и возвращает оба значения в ответе.
+func issueSession(c *gin.Context, user *models.User, tokens *auth.TokenManager, sessions *services.SessionService, cookie utils.CookieOptions) {
+	tokenString, claims, err := tokens.IssueSession(user.ID, user.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
+		return
+	}
+
+	_, err = sessions.StartSession(context.Background(), user.ID, claims.ID, claims.ExpiresAt.Time, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при создании сессии"})
+		return
+	}
+
+	csrfToken, err := utils.GenerateCSRFToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
+		return
+	}
+
+	utils.SetTokenCookie(c.Writer, tokenString, cookie)
+	utils.SetCSRFCookie(c.Writer, csrfToken, cookie)
+
+	c.JSON(http.StatusOK, gin.H{"token": tokenString, "csrf_token": csrfToken})
+}
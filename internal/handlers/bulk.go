@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+)
+
+// BulkHandler обрабатывает запросы на операции над несколькими заметками.
+type BulkHandler struct {
+	BulkService *services.BulkService
+	Tokens      *auth.TokenManager
+}
+
+// NewBulkHandler создает новый экземпляр BulkHandler.
+func NewBulkHandler(bulkService *services.BulkService, tokens *auth.TokenManager) *BulkHandler {
+	return &BulkHandler{
+		BulkService: bulkService,
+		Tokens:      tokens,
+	}
+}
+
+// ApplyBulk выполняет операции над несколькими заметками.
+// @Summary Операции над несколькими заметками
+// @Description Выполняет операции delete, archive, unarchive, tag (tags — добавляемые теги), move (folder — папка, пустая строка убирает заметку из папки) и visibility (visibility — public или private) над списками заметок по порядку в одной транзакции. Удалять и изменять можно только свои заметки, архив личный и доступен для любых видимых заметок. Для каждой заметки каждой операции возвращается результат: ok, not_found или forbidden. Если хотя бы один результат не ok, ни одна операция не выполняется и возвращается код 409.
+// @Accept json
+// @Produce json
+// @Param body body models.BulkInput true "Операции"
+// @Router /notes/bulk [post]
+func (h *BulkHandler) ApplyBulk(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	var input models.BulkInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	report, err := h.BulkService.Apply(context.Background(), claims.UserID, input.Operations)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidBulkOperation) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при выполнении операций над заметками"})
+		return
+	}
+	if !report.Applied {
+		c.JSON(http.StatusConflict, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
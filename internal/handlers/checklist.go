@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"note_app/pkg/utils"
+	"strconv"
+	"time"
+)
+
+// ChecklistHandler обрабатывает запросы на изменение пунктов заметок-списков задач.
+type ChecklistHandler struct {
+	ChecklistService *services.ChecklistService
+	NoteService      services.NoteService
+	Tokens           *auth.TokenManager
+}
+
+// NewChecklistHandler создает новый экземпляр ChecklistHandler.
+func NewChecklistHandler(checklistService *services.ChecklistService, noteService services.NoteService, tokens *auth.TokenManager) *ChecklistHandler {
+	return &ChecklistHandler{
+		ChecklistService: checklistService,
+		NoteService:      noteService,
+		Tokens:           tokens,
+	}
+}
+
+// AddItem добавляет пункт в список задач.
+// @Summary Добавление пункта списка задач
+// @Description Добавляет пункт в конец заметки-списка задач текущего пользователя. Доступно в течение срока редактирования заметки (1 день). Возвращает все пункты списка и прогресс.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param item body models.ChecklistItemInput true "Пункт списка"
+// @Router /notes/{id}/items [post]
+func (h *ChecklistHandler) AddItem(c *gin.Context) {
+	note, ok := h.editableNote(c)
+	if !ok {
+		return
+	}
+
+	var input models.ChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	items, err := h.ChecklistService.AddItem(context.Background(), note.ID, input)
+	if err != nil {
+		writeChecklistError(c, err, "Ошибка при добавлении пункта списка")
+		return
+	}
+	c.JSON(http.StatusCreated, checklistResponse(items))
+}
+
+// CheckItem отмечает пункт списка задач выполненным.
+// @Summary Отметка пункта списка задач
+// @Description Отмечает пункт заметки-списка задач текущего пользователя выполненным.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param itemId path int true "Идентификатор пункта"
+// @Router /notes/{id}/items/{itemId}/check [put]
+func (h *ChecklistHandler) CheckItem(c *gin.Context) {
+	h.setChecked(c, true)
+}
+
+// UncheckItem снимает отметку о выполнении с пункта списка задач.
+// @Summary Снятие отметки с пункта списка задач
+// @Description Снимает отметку о выполнении с пункта заметки-списка задач текущего пользователя.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param itemId path int true "Идентификатор пункта"
+// @Router /notes/{id}/items/{itemId}/check [delete]
+func (h *ChecklistHandler) UncheckItem(c *gin.Context) {
+	h.setChecked(c, false)
+}
+
+// setChecked устанавливает отметку о выполнении пункта списка задач. Отметка не ограничена сроком
+// редактирования: список задач ведется и после него, а отметка меняет только состояние пункта, но не состав
+// и формулировки пунктов.
+func (h *ChecklistHandler) setChecked(c *gin.Context, checked bool) {
+	note, ok := h.ownNote(c)
+	if !ok {
+		return
+	}
+	itemID, ok := itemIDParam(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.ChecklistService.SetChecked(context.Background(), note.ID, itemID, checked)
+	if err != nil {
+		writeChecklistError(c, err, "Ошибка при изменении пункта списка")
+		return
+	}
+	c.JSON(http.StatusOK, checklistResponse(items))
+}
+
+// DeleteItem удаляет пункт из списка задач.
+// @Summary Удаление пункта списка задач
+// @Description Удаляет пункт из заметки-списка задач текущего пользователя. Доступно в течение срока редактирования заметки (1 день).
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param itemId path int true "Идентификатор пункта"
+// @Router /notes/{id}/items/{itemId} [delete]
+func (h *ChecklistHandler) DeleteItem(c *gin.Context) {
+	note, ok := h.editableNote(c)
+	if !ok {
+		return
+	}
+	itemID, ok := itemIDParam(c)
+	if !ok {
+		return
+	}
+
+	items, err := h.ChecklistService.DeleteItem(context.Background(), note.ID, itemID)
+	if err != nil {
+		writeChecklistError(c, err, "Ошибка при удалении пункта списка")
+		return
+	}
+	c.JSON(http.StatusOK, checklistResponse(items))
+}
+
+// ReorderItems меняет порядок пунктов списка задач.
+// @Summary Изменение порядка пунктов списка задач
+// @Description Меняет порядок пунктов заметки-списка задач текущего пользователя в течение срока редактирования заметки (1 день). Передаются идентификаторы всех пунктов в новом порядке.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param order body models.ChecklistOrderInput true "Новый порядок пунктов"
+// @Router /notes/{id}/items/order [put]
+func (h *ChecklistHandler) ReorderItems(c *gin.Context) {
+	note, ok := h.editableNote(c)
+	if !ok {
+		return
+	}
+
+	var input models.ChecklistOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	items, err := h.ChecklistService.Reorder(context.Background(), note.ID, input.ItemIDs)
+	if err != nil {
+		writeChecklistError(c, err, "Ошибка при изменении порядка пунктов списка")
+		return
+	}
+	c.JSON(http.StatusOK, checklistResponse(items))
+}
+
+// ownNote возвращает заметку из параметра id, если она принадлежит текущему пользователю.
+func (h *ChecklistHandler) ownNote(c *gin.Context) (*models.Note, bool) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return nil, false
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return nil, false
+	}
+	if note.UserID != claims.UserID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Изменять список задач может только автор заметки"})
+		return nil, false
+	}
+	return note, true
+}
+
+// editableNote возвращает заметку из параметра id, если она принадлежит текущему пользователю и срок ее
+// редактирования не истек. Добавление, удаление и перестановка пунктов меняют содержимое заметки, поэтому
+// ограничены тем же сроком, что и PUT /notes/{id}.
+func (h *ChecklistHandler) editableNote(c *gin.Context) (*models.Note, bool) {
+	note, ok := h.ownNote(c)
+	if !ok {
+		return nil, false
+	}
+	if time.Since(note.CreatedAt) > noteEditWindow {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Заметка не может быть отредактирована по истечении 1 дня"})
+		return nil, false
+	}
+	return note, true
+}
+
+// checklistResponse формирует ответ с пунктами списка задач и прогрессом.
+func checklistResponse(items []models.ChecklistItem) gin.H {
+	return gin.H{"items": items, "progress": utils.ChecklistProgress(items)}
+}
+
+// writeChecklistError отправляет ответ с ошибкой операции над списком задач.
+func writeChecklistError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrNotChecklist):
+		c.JSON(http.StatusConflict, gin.H{"error": "Заметка не является списком задач"})
+	case errors.Is(err, services.ErrInvalidChecklistItem), errors.Is(err, services.ErrInvalidItemOrder):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrChecklistItemNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Пункт списка не найден"})
+	case errors.Is(err, services.ErrNoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заметка не найдена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// itemIDParam возвращает идентификатор пункта списка из параметра itemId.
+func itemIDParam(c *gin.Context) (int, bool) {
+	itemID, err := strconv.Atoi(c.Param("itemId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор пункта списка"})
+		return 0, false
+	}
+	return itemID, true
+}
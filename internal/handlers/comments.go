@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// CommentHandler обрабатывает запросы на работу с комментариями к заметкам.
+type CommentHandler struct {
+	CommentService *services.CommentService
+	NoteService    services.NoteService
+	Tokens         *auth.TokenManager
+}
+
+// NewCommentHandler создает новый экземпляр CommentHandler.
+func NewCommentHandler(commentService *services.CommentService, noteService services.NoteService, tokens *auth.TokenManager) *CommentHandler {
+	return &CommentHandler{
+		CommentService: commentService,
+		NoteService:    noteService,
+		Tokens:         tokens,
+	}
+}
+
+// AddComment добавляет комментарий к заметке.
+// @Summary Добавление комментария
+// @Description Добавляет комментарий текущего пользователя к заметке.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param comment body models.CommentInput true "Текст комментария"
+// @Router /notes/{id}/comments [post]
+func (h *CommentHandler) AddComment(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	var input models.CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	comment, err := h.CommentService.AddComment(context.Background(), note.ID, claims.UserID, input.Text)
+	if err != nil {
+		writeCommentError(c, err, "Ошибка при добавлении комментария")
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// GetComments возвращает комментарии к заметке.
+// @Summary Комментарии к заметке
+// @Description Возвращает комментарии к заметке в порядке добавления с постраничной навигацией.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param page query int false "Номер страницы"
+// @Param limit query int false "Количество записей на странице"
+// @Router /notes/{id}/comments [get]
+func (h *CommentHandler) GetComments(c *gin.Context) {
+	if _, ok := authenticate(c, h.Tokens); !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	comments, err := h.CommentService.GetComments(context.Background(), note.ID, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении комментариев"})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// EditComment изменяет комментарий.
+// @Summary Изменение комментария
+// @Description Изменяет текст комментария. Доступно только автору комментария.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param commentId path int true "Идентификатор комментария"
+// @Param comment body models.CommentInput true "Новый текст комментария"
+// @Router /notes/{id}/comments/{commentId} [put]
+func (h *CommentHandler) EditComment(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input models.CommentInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	comment, err := h.CommentService.UpdateComment(context.Background(), note.ID, commentID, claims.UserID, input.Text)
+	if err != nil {
+		writeCommentError(c, err, "Ошибка при изменении комментария")
+		return
+	}
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment удаляет комментарий.
+// @Summary Удаление комментария
+// @Description Удаляет комментарий. Доступно автору комментария и автору заметки.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param commentId path int true "Идентификатор комментария"
+// @Router /notes/{id}/comments/{commentId} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+	commentID, ok := commentIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.CommentService.DeleteComment(context.Background(), note, commentID, claims.UserID); err != nil {
+		writeCommentError(c, err, "Ошибка при удалении комментария")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Комментарий удален"})
+}
+
+// writeCommentError отправляет ответ с ошибкой операции над комментарием.
+func writeCommentError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidComment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Комментарий не найден"})
+	case errors.Is(err, services.ErrCommentForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав для этого комментария"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// commentIDParam возвращает идентификатор комментария из параметра commentId.
+func commentIDParam(c *gin.Context) (int, bool) {
+	commentID, err := strconv.Atoi(c.Param("commentId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор комментария"})
+		return 0, false
+	}
+	return commentID, true
+}
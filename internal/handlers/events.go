@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/url"
+	"note_app/internal/auth"
+	"note_app/internal/config"
+	"note_app/internal/events"
+	"note_app/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultHeartbeat период пустых сообщений, если он не задан в конфигурации.
+	defaultHeartbeat = 15 * time.Second
+	// wsWriteTimeout время на отправку одного сообщения WebSocket.
+	wsWriteTimeout = 10 * time.Second
+)
+
+// EventHandler обрабатывает подписки на события заметок по Server-Sent Events и WebSocket.
+type EventHandler struct {
+	Bus       *events.Bus
+	Tokens    *auth.TokenManager
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+// NewEventHandler создает новый экземпляр EventHandler. Соединения WebSocket принимаются без заголовка Origin,
+// с того же хоста или с источников из cors.allowedOrigins.
+func NewEventHandler(bus *events.Bus, tokens *auth.TokenManager, cfg config.EventsConfig, cors config.CORSConfig) *EventHandler {
+	heartbeat := time.Duration(cfg.Heartbeat) * time.Second
+	if heartbeat <= 0 {
+		heartbeat = defaultHeartbeat
+	}
+
+	allowed := make(map[string]bool, len(cors.AllowedOrigins))
+	for _, origin := range cors.AllowedOrigins {
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+
+	return &EventHandler{
+		Bus:       bus,
+		Tokens:    tokens,
+		heartbeat: heartbeat,
+		upgrader: websocket.Upgrader{
+			// Куки сессии отправляются браузером с любого сайта, поэтому чужие источники не допускаются
+			CheckOrigin: func(r *http.Request) bool {
+				origin := r.Header.Get("Origin")
+				if origin == "" || allowed[origin] {
+					return true
+				}
+				u, err := url.Parse(origin)
+				return err == nil && strings.EqualFold(u.Host, r.Host)
+			},
+		},
+	}
+}
+
+// eventFilter отбирает события для подписчика. Как и в GET /notes, подписчик получает события всех заметок,
+// кроме личных заметок других пользователей, или только заметок автора userID, если он указан.
+type eventFilter struct {
+	viewerID int
+	userID   int
+}
+
+// match сообщает, нужно ли отправить событие подписчику. Событие resync отправляется всегда.
+func (f eventFilter) match(event models.NoteEvent) bool {
+	if event.Type == models.EventResync {
+		return true
+	}
+	if event.Private && event.UserID != f.viewerID {
+		return false
+	}
+	return f.userID == 0 || event.UserID == f.userID
+}
+
+// subscribe проверяет параметры запроса и подписывает пользователя на события. Последнее полученное событие
+// берется из заголовка Last-Event-ID или параметра last_event_id.
+func (h *EventHandler) subscribe(c *gin.Context, viewerID int) (*events.Subscription, []models.NoteEvent, eventFilter, bool) {
+	filter := eventFilter{viewerID: viewerID}
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.Atoi(userID)
+		if err != nil || id <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор пользователя"})
+			return nil, nil, filter, false
+		}
+		filter.userID = id
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	sub, missed := h.Bus.Subscribe(lastEventID)
+	return sub, missed, filter, true
+}
+
+// StreamEvents передает события заметок в формате Server-Sent Events.
+// @Summary Поток событий заметок (SSE)
+// @Description Передает события note.created, note.updated и note.deleted в формате text/event-stream. События личных заметок передаются только их автору. После переподключения с заголовком Last-Event-ID (или параметром last_event_id) передаются пропущенные события; если они уже недоступны, передается событие resync, и список заметок нужно запросить заново. Соединение поддерживается комментариями ": ping".
+// @Produce text/event-stream
+// @Param user_id query int false "Только события заметок этого автора"
+// @Param last_event_id query string false "Идентификатор последнего полученного события"
+// @Param Last-Event-ID header string false "Идентификатор последнего полученного события"
+// @Router /events [get]
+func (h *EventHandler) StreamEvents(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	sub, missed, filter, ok := h.subscribe(c, claims.UserID)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// Отключаем буферизацию ответа обратным прокси nginx
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	for _, event := range missed {
+		if filter.match(event) {
+			if err := writeSSEEvent(c.Writer, event); err != nil {
+				return
+			}
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+		case event, open := <-sub.C:
+			// Подписка закрыта, потому что клиент не успевал получать события: он переподключится
+			// с Last-Event-ID и получит пропущенные события
+			if !open {
+				return
+			}
+			if !filter.match(event) {
+				continue
+			}
+			if err := writeSSEEvent(c.Writer, event); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeSSEEvent записывает событие в формате Server-Sent Events.
+func writeSSEEvent(w gin.ResponseWriter, event models.NoteEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+// StreamEventsWebSocket передает события заметок по WebSocket.
+// @Summary Поток событий заметок (WebSocket)
+// @Description Устанавливает соединение WebSocket и передает события заметок JSON-сообщениями в том же формате, что и GET /events. Пропущенные события передаются по параметру last_event_id. Соединение поддерживается управляющими кадрами ping.
+// @Param user_id query int false "Только события заметок этого автора"
+// @Param last_event_id query string false "Идентификатор последнего полученного события"
+// @Router /events/ws [get]
+func (h *EventHandler) StreamEventsWebSocket(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	if !websocket.IsWebSocketUpgrade(c.Request) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ожидается запрос на установку соединения WebSocket"})
+		return
+	}
+	sub, missed, filter, ok := h.subscribe(c, claims.UserID)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	// При ошибке Upgrade сам отправляет ответ клиенту
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// Сообщения клиента не ожидаются: читаем соединение, чтобы обрабатывать pong и закрытие
+	pongWait := 2 * h.heartbeat
+	conn.SetReadLimit(512)
+	_ = conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range missed {
+		if filter.match(event) {
+			if err := writeWSEvent(conn, event); err != nil {
+				return
+			}
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return
+			}
+		case event, open := <-sub.C:
+			if !open {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "отставание от потока событий"),
+					time.Now().Add(wsWriteTimeout))
+				return
+			}
+			if !filter.match(event) {
+				continue
+			}
+			if err := writeWSEvent(conn, event); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeWSEvent отправляет событие JSON-сообщением WebSocket.
+func writeWSEvent(conn *websocket.Conn, event models.NoteEvent) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(event)
+}
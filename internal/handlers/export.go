@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log"
+	"mime"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/export"
+	"note_app/internal/services"
+	"time"
+)
+
+// ExportHandler обрабатывает запросы на выгрузку заметок.
+type ExportHandler struct {
+	ExportService *services.ExportService
+	Tokens        *auth.TokenManager
+}
+
+// NewExportHandler создает новый экземпляр ExportHandler.
+func NewExportHandler(exportService *services.ExportService, tokens *auth.TokenManager) *ExportHandler {
+	return &ExportHandler{
+		ExportService: exportService,
+		Tokens:        tokens,
+	}
+}
+
+// ExportNotes выгружает все заметки текущего пользователя.
+// @Summary Выгрузка заметок
+// @Description Выгружает все заметки текущего пользователя файлом: json (массив заметок с пунктами списков задач), markdown-zip (ZIP-архив, каждая заметка — Markdown-файл с YAML-заголовком) или csv. Выгрузка включает метки, папку и видимость заметок. Выгрузка передается потоком по мере чтения заметок.
+// @Produce json
+// @Produce application/zip
+// @Produce text/csv
+// @Param format query string false "Формат: json (по умолчанию), markdown-zip или csv"
+// @Router /me/export [get]
+func (h *ExportHandler) ExportNotes(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	writer, err := export.New(c.Query("format"), c.Writer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат выгрузки. Допустимые значения: json, markdown-zip, csv"})
+		return
+	}
+
+	filename := fmt.Sprintf("notes-%s.%s", time.Now().Format("2006-01-02"), writer.Extension())
+	c.Header("Content-Type", writer.ContentType())
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Status(http.StatusOK)
+
+	// Ответ уже начат, поэтому при ошибке выгрузка обрывается, а ошибка только записывается в журнал
+	if err := h.ExportService.Export(c.Request.Context(), claims.UserID, writer); err != nil {
+		log.Printf("Ошибка при выгрузке заметок пользователя %d: %v", claims.UserID, err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/importer"
+	"note_app/internal/services"
+)
+
+// ImportHandler обрабатывает запросы на загрузку заметок из файлов.
+type ImportHandler struct {
+	ImportService *services.ImportService
+	UserService   *services.UserService
+	Tokens        *auth.TokenManager
+}
+
+// NewImportHandler создает новый экземпляр ImportHandler.
+func NewImportHandler(importService *services.ImportService, userService *services.UserService, tokens *auth.TokenManager) *ImportHandler {
+	return &ImportHandler{
+		ImportService: importService,
+		UserService:   userService,
+		Tokens:        tokens,
+	}
+}
+
+// ImportNotes загружает заметки текущего пользователя из файла.
+// @Summary Загрузка заметок из файла
+// @Description Загружает заметки из файла в поле file формы multipart/form-data: JSON-массива (как в выгрузке GET /me/export), ZIP-архива Markdown-файлов с YAML-заголовком или файла Evernote .enex. Формат задается параметром format или определяется по расширению файла. Каждая заметка проверяется по правилам создания заметок, допустимые заметки сохраняются в одной транзакции. Время создания из будущего заменяется временем загрузки. Метки, папка и видимость сохраняются из файла, заметка без видимости становится общедоступной. В ответе для каждой записи файла указывается результат: created, skipped (повтор существующей заметки или не заметка) или failed.
+// @Accept multipart/form-data
+// @Produce json
+// @Param format query string false "Формат: json, markdown-zip или enex"
+// @Param file formData file true "Файл"
+// @Router /me/import [post]
+func (h *ImportHandler) ImportNotes(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	maxSize := h.ImportService.MaxSize()
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+multipartOverhead)
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Ожидается запрос multipart/form-data"})
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Не передан файл в поле file"})
+			return
+		}
+		if err != nil {
+			writeImportError(c, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		format, err := importer.DetectFormat(c.Query("format"), part.FileName())
+		if err != nil {
+			part.Close()
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат файла. Допустимые значения: json, markdown-zip, enex"})
+			return
+		}
+
+		// Архив ZIP читается с произвольной позиции, поэтому файл загружается в память целиком
+		data, err := io.ReadAll(io.LimitReader(part, maxSize+1))
+		part.Close()
+		if err != nil {
+			writeImportError(c, err)
+			return
+		}
+		if int64(len(data)) > maxSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Размер файла превышает допустимый"})
+			return
+		}
+
+		user, err := h.UserService.GetUserByID(claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении информации о пользователе"})
+			return
+		}
+
+		report, err := h.ImportService.Import(context.Background(), user, format, data)
+		if err != nil {
+			writeImportError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+		return
+	}
+}
+
+// writeImportError отправляет ответ с ошибкой загрузки заметок.
+func writeImportError(c *gin.Context, err error) {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Размер файла превышает допустимый"})
+	case errors.Is(err, importer.ErrInvalidFile), errors.Is(err, importer.ErrTooManyNotes):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при загрузке заметок"})
+	}
+}
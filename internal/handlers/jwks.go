@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+)
+
+// JWKSHandler возвращает открытые ключи, которыми подписываются токены, в формате JWK Set.
+// @Summary Открытые ключи JWT
+// @Description Возвращает открытые ключи RS256 и EdDSA для проверки токенов другими сервисами. Ключи HS256 не публикуются.
+// @Produce json
+// @Router /.well-known/jwks.json [get]
+func JWKSHandler(keys *auth.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, gin.H{"keys": keys.JWKS()})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// LinkHandler обрабатывает запросы на получение ссылок между заметками.
+type LinkHandler struct {
+	LinkService *services.LinkService
+	NoteService services.NoteService
+	Tokens      *auth.TokenManager
+}
+
+// NewLinkHandler создает новый экземпляр LinkHandler.
+func NewLinkHandler(linkService *services.LinkService, noteService services.NoteService, tokens *auth.TokenManager) *LinkHandler {
+	return &LinkHandler{
+		LinkService: linkService,
+		NoteService: noteService,
+		Tokens:      tokens,
+	}
+}
+
+// GetLinks возвращает ссылки заметки на другие заметки.
+// @Summary Ссылки заметки
+// @Description Возвращает ссылки [[заголовок]] и [[#id]] из текста заметки. Для каждой ссылки возвращается заметка, на которую она указывает, и ее текущий заголовок; ссылка без такой заметки отмечается как битая (broken).
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/links [get]
+func (h *LinkHandler) GetLinks(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	links, err := h.LinkService.GetLinks(context.Background(), note.ID, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении ссылок"})
+		return
+	}
+	c.JSON(http.StatusOK, links)
+}
+
+// GetBacklinks возвращает заметки, ссылающиеся на заметку.
+// @Summary Обратные ссылки
+// @Description Возвращает заметки, которые ссылаются на заметку, сначала новые, с постраничной навигацией.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param page query int false "Номер страницы"
+// @Param limit query int false "Количество записей на странице"
+// @Router /notes/{id}/backlinks [get]
+func (h *LinkHandler) GetBacklinks(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	backlinks, err := h.LinkService.GetBacklinks(context.Background(), note.ID, claims.UserID, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении обратных ссылок"})
+		return
+	}
+	c.JSON(http.StatusOK, backlinks)
+}
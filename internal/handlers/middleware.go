@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"note_app/pkg/utils"
+	"strconv"
+	"strings"
+)
+
+// Ключи, под которыми middleware сохраняют результаты аутентификации в контексте Gin.
+const (
+	claimsKey       = "claims"
+	authSourceKey   = "auth_source"
+	accessTokenKey  = "access_token"
+	scopeCheckedKey = "scope_checked"
+)
+
+// RequireRole пропускает запрос только для активных пользователей с одной из указанных ролей.
+// Роль проверяется по базе данных, поэтому изменение роли вступает в силу без перевыпуска токена.
+func RequireRole(tokens *auth.TokenManager, userService *services.UserService, roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := authenticate(c, tokens)
+		if !ok {
+			c.Abort()
+			return
+		}
+
+		user, err := userService.GetUserByID(claims.UserID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+			return
+		}
+		if user.Disabled {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Учетная запись заблокирована"})
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				claims.Role = user.Role
+				c.Set(claimsKey, claims)
+				c.Next()
+				return
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+	}
+}
+
+// Authenticate определяет пользователя по токену сессии (куки или Authorization: Bearer)
+// либо по персональному токену доступа и сохраняет результат в контексте запроса.
+// Запросы без токена или с недействительным токеном пропускаются: их отклоняют сами обработчики.
+// Запросы заблокированных пользователей отклоняются сразу.
+func Authenticate(tokens *auth.TokenManager, userService *services.UserService, accessTokenService *services.AccessTokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString, source := requestToken(c)
+		if tokenString == "" {
+			c.Next()
+			return
+		}
+
+		var claims *models.Claims
+		if strings.HasPrefix(tokenString, models.AccessTokenPrefix) {
+			accessToken, err := accessTokenService.Authenticate(context.Background(), tokenString)
+			if err != nil {
+				c.Next()
+				return
+			}
+			claims = &models.Claims{UserID: accessToken.UserID}
+			c.Set(accessTokenKey, accessToken)
+		} else {
+			parsed, err := tokens.ParseSession(tokenString)
+			if err != nil {
+				c.Next()
+				return
+			}
+			claims = parsed
+		}
+
+		user, err := userService.GetUserByID(claims.UserID)
+		if err != nil {
+			c.Next()
+			return
+		}
+		if user.Disabled {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Учетная запись заблокирована"})
+			return
+		}
+
+		c.Set(claimsKey, claims)
+		c.Set(authSourceKey, source)
+		c.Next()
+	}
+}
+
+// RequireScope разрешает доступ по персональному токену, если он выдан на указанную область действия.
+// Токены сессии имеют доступ ко всем областям.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if value, ok := c.Get(accessTokenKey); ok {
+			if !value.(*models.AccessToken).HasScope(scope) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Токен доступа не выдан на область " + scope})
+				return
+			}
+			c.Set(scopeCheckedKey, true)
+		}
+		c.Next()
+	}
+}
+
+// CORS разрешает запросы из браузера с источников из списка allowedOrigins. Источник "*" не поддерживается:
+// ответы разрешают куки, поэтому источники перечисляются явно.
+// Ответы на предварительные запросы (OPTIONS) кэшируются браузером на maxAge секунд.
+func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(cfg.AllowedOrigins))
+	for _, origin := range cfg.AllowedOrigins {
+		allowed[strings.TrimRight(origin, "/")] = true
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !allowed[origin] {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Передаем конкретный источник, а не "*": иначе браузер не отправит куки
+		header := c.Writer.Header()
+		header.Set("Access-Control-Allow-Origin", origin)
+		header.Set("Access-Control-Allow-Credentials", "true")
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Authorization, Content-Type, "+utils.CSRFHeaderName)
+			if cfg.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// currentUserID возвращает идентификатор пользователя, определенного middleware Authenticate, или 0.
+func currentUserID(c *gin.Context) int {
+	if value, ok := c.Get(claimsKey); ok {
+		return value.(*models.Claims).UserID
+	}
+	return 0
+}
+
+// currentClaims возвращает claims, сохраненные middleware RequireRole.
+func currentClaims(c *gin.Context) *models.Claims {
+	return c.MustGet(claimsKey).(*models.Claims)
+}
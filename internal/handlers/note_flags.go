@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// NoteFlagHandler обрабатывает запросы на закрепление заметок, добавление их в избранное и в архив.
+// Отметки личные: они видны и действуют только для пользователя, который их поставил.
+type NoteFlagHandler struct {
+	NoteService     services.NoteService
+	ReactionService *services.ReactionService
+	Tokens          *auth.TokenManager
+}
+
+// NewNoteFlagHandler создает новый экземпляр NoteFlagHandler.
+func NewNoteFlagHandler(noteService services.NoteService, reactionService *services.ReactionService, tokens *auth.TokenManager) *NoteFlagHandler {
+	return &NoteFlagHandler{
+		NoteService:     noteService,
+		ReactionService: reactionService,
+		Tokens:          tokens,
+	}
+}
+
+// Pin закрепляет заметку.
+// @Summary Закрепление заметки
+// @Description Закрепляет заметку для текущего пользователя: в его списке заметок она идет первой.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/pin [put]
+func (h *NoteFlagHandler) Pin(c *gin.Context) { h.setFlag(c, models.NoteFlagPinned, true) }
+
+// Unpin открепляет заметку.
+// @Summary Открепление заметки
+// @Description Снимает закрепление заметки для текущего пользователя.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/pin [delete]
+func (h *NoteFlagHandler) Unpin(c *gin.Context) { h.setFlag(c, models.NoteFlagPinned, false) }
+
+// Favorite добавляет заметку в избранное.
+// @Summary Добавление в избранное
+// @Description Добавляет заметку в избранное текущего пользователя (GET /favorites).
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/favorite [put]
+func (h *NoteFlagHandler) Favorite(c *gin.Context) { h.setFlag(c, models.NoteFlagFavorite, true) }
+
+// Unfavorite убирает заметку из избранного.
+// @Summary Удаление из избранного
+// @Description Убирает заметку из избранного текущего пользователя.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/favorite [delete]
+func (h *NoteFlagHandler) Unfavorite(c *gin.Context) { h.setFlag(c, models.NoteFlagFavorite, false) }
+
+// Archive переносит заметку в архив.
+// @Summary Перенос в архив
+// @Description Переносит заметку в архив текущего пользователя: она перестает показываться в GET /notes и доступна с параметром archived=true.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/archive [put]
+func (h *NoteFlagHandler) Archive(c *gin.Context) { h.setFlag(c, models.NoteFlagArchived, true) }
+
+// Unarchive возвращает заметку из архива.
+// @Summary Возврат из архива
+// @Description Возвращает заметку из архива текущего пользователя.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Router /notes/{id}/archive [delete]
+func (h *NoteFlagHandler) Unarchive(c *gin.Context) { h.setFlag(c, models.NoteFlagArchived, false) }
+
+// setFlag устанавливает или снимает отметку текущего пользователя и возвращает все его отметки на заметке.
+func (h *NoteFlagHandler) setFlag(c *gin.Context, flag models.NoteFlag, value bool) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	if err := h.NoteService.SetNoteFlag(context.Background(), note.ID, claims.UserID, flag, value); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при изменении отметки заметки"})
+		return
+	}
+	flags, err := h.NoteService.GetNoteFlags(context.Background(), note.ID, claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отметок заметки"})
+		return
+	}
+	c.JSON(http.StatusOK, flags)
+}
+
+// GetFavorites возвращает избранные заметки текущего пользователя.
+// @Summary Избранные заметки
+// @Description Возвращает заметки, добавленные текущим пользователем в избранное, в том же формате, что и GET /notes.
+// @Produce json
+// @Param page query int false "Номер страницы"
+// @Param limit query int false "Количество записей на странице"
+// @Param full query bool false "Вернуть полный текст заметок"
+// @Param archived query bool false "Вернуть избранные заметки из архива"
+// @Router /favorites [get]
+func (h *NoteFlagHandler) GetFavorites(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+
+	opts := models.NoteListOptions{ViewerID: claims.UserID, Sort: models.NoteSortNew, Archived: c.Query("archived") == "true"}
+	notes, err := h.NoteService.GetFavoriteNotes(context.Background(), opts, (page-1)*limit, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заметок"})
+		return
+	}
+	if err := h.ReactionService.FillReactions(context.Background(), notes, claims.UserID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении реакций"})
+		return
+	}
+
+	c.JSON(http.StatusOK, noteListResponse(notes, claims.UserID, c.Query("full") == "true"))
+}
@@ -2,10 +2,12 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"io"
 	"net/http"
+	"note_app/internal/auth"
 	"note_app/internal/models"
 	"note_app/internal/services"
 	"note_app/pkg/utils"
@@ -13,35 +15,47 @@ import (
 	"time"
 )
 
+// noteEditWindow время после создания заметки, в течение которого ее можно редактировать.
+const noteEditWindow = 24 * time.Hour
+
 // NoteHandler обрабатывает запросы, связанные с заметками.
 type NoteHandler struct {
-	NoteService services.NoteService
-	UserService *services.UserService
-	JWTSecret   string
+	NoteService     services.NoteService
+	UserService     *services.UserService
+	TemplateService *services.TemplateService
+	Tokens          *auth.TokenManager
 }
 
 // NewNoteHandler создает новый экземпляр NoteHandler для обработки запросов, связанных с заметками.
-func NewNoteHandler(noteService services.NoteService, userService *services.UserService, jwtSecret string) *NoteHandler {
+func NewNoteHandler(noteService services.NoteService, userService *services.UserService, templateService *services.TemplateService, tokens *auth.TokenManager) *NoteHandler {
 	return &NoteHandler{
-		NoteService: noteService,
-		UserService: userService,
-		JWTSecret:   jwtSecret,
+		NoteService:     noteService,
+		UserService:     userService,
+		TemplateService: templateService,
+		Tokens:          tokens,
 	}
 }
 
 // AddNote обрабатывает запрос на добавление новой заметки.
 // @Summary Добавление новой заметки
-// @Description Обрабатывает запрос на добавление новой заметки.
+// @Description Обрабатывает запрос на добавление новой заметки. С параметром template заметка создается по шаблону: тело запроса в этом случае имеет вид models.TemplateNoteInput и может быть пустым.
 // @Accept json
 // @Produce json
+// @Param template query int false "Идентификатор шаблона"
 // @Param body body models.NoteInput true "Данные новой заметки"
 // @Router /notes [post]
 func (noteHandler *NoteHandler) AddNote(c *gin.Context) {
-	var note models.Note
-	if err := c.BindJSON(&note); err != nil {
+	if c.Query("template") != "" {
+		noteHandler.addNoteFromTemplate(c)
+		return
+	}
+
+	var input models.NoteInput
+	if err := c.BindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
 		return
 	}
+	note := noteFromInput(input)
 
 	// Проверяем длину заголовка и текста.
 	if !utils.CheckNoteLength(note.Title, note.Text) {
@@ -49,26 +63,58 @@ func (noteHandler *NoteHandler) AddNote(c *gin.Context) {
 		return
 	}
 
-	// Получаем токен из куки запроса
-	tokenString, err := c.Cookie("token")
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неавторизован"})
+	format, valid := utils.NormalizeNoteFormat(note.Format)
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат заметки. Допустимые значения: plain, markdown, checklist"})
+		return
+	}
+	note.Format = format
+
+	// Текст заметки-списка задач формируется из пунктов, у остальных заметок пунктов нет
+	if note.Format == models.NoteFormatChecklist {
+		items, text, httpErr := utils.PrepareChecklist(note.Title, note.Items)
+		if httpErr != nil {
+			c.JSON(httpErr.Code, gin.H{"error": httpErr.Message})
+			return
+		}
+		note.Items, note.Text = items, text
+		note.Progress = utils.ChecklistProgress(items)
+	} else {
+		note.Items, note.Progress = nil, nil
+	}
+
+	// Проверяем токен сессии
+	claims, ok := authenticate(c, noteHandler.Tokens)
+	if !ok {
 		return
 	}
 
-	// Проверяем и расшифровываем токен
-	token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(noteHandler.JWTSecret), nil
-	})
-	if err != nil || !token.Valid {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неавторизован"})
+	user, err := noteHandler.UserService.GetUserByID(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении информации о пользователе"})
 		return
 	}
 
-	// Получаем утверждения (claims) из токена
-	claims, ok := token.Claims.(*models.Claims)
+	noteHandler.createNote(c, &note, user)
+}
+
+// addNoteFromTemplate создает заметку по шаблону из параметра template с подстановкой значений из тела запроса.
+func (noteHandler *NoteHandler) addNoteFromTemplate(c *gin.Context) {
+	claims, ok := authenticate(c, noteHandler.Tokens)
 	if !ok {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неавторизован"})
+		return
+	}
+
+	templateID, err := strconv.Atoi(c.Query("template"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор шаблона"})
+		return
+	}
+
+	// Тело запроса необязательно: без него используются только встроенные подстановки
+	var input models.TemplateNoteInput
+	if err := c.ShouldBindJSON(&input); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
 		return
 	}
 
@@ -78,19 +124,55 @@ func (noteHandler *NoteHandler) AddNote(c *gin.Context) {
 		return
 	}
 
-	note.UserID = claims.UserID
+	note, err := noteHandler.TemplateService.Instantiate(context.Background(), templateID, user, input.Variables)
+	if err != nil {
+		writeTemplateError(c, err, "Ошибка при создании заметки по шаблону")
+		return
+	}
+	note.DueAt, note.RemindAt = input.DueAt, input.RemindAt
+
+	noteHandler.createNote(c, note, user)
+}
+
+// noteFromInput возвращает заметку с данными из запроса на создание или редактирование. Пункты списка задач
+// остаются nil, если они не переданы.
+func noteFromInput(input models.NoteInput) models.Note {
+	note := models.Note{
+		Title:    input.Title,
+		Text:     input.Text,
+		Format:   input.Format,
+		DueAt:    input.DueAt,
+		RemindAt: input.RemindAt,
+	}
+	if input.Items != nil {
+		note.Items = make([]models.ChecklistItem, len(input.Items))
+		for i, item := range input.Items {
+			note.Items[i] = models.ChecklistItem{Text: item.Text, Checked: item.Checked}
+		}
+	}
+	return note
+}
+
+// createNote сохраняет новую заметку пользователя и отправляет в ответе сохраненную заметку.
+func (noteHandler *NoteHandler) createNote(c *gin.Context, note *models.Note, user *models.User) {
+	note.UserID = user.ID
 	note.CreatedAt = time.Now()
 	note.Author = user.Username
 
-	id, err := noteHandler.NoteService.AddNote(context.Background(), &note)
+	id, err := noteHandler.NoteService.AddNote(context.Background(), note)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при добавлении заметки"})
 		return
 	}
 
-	note.ID = id
+	created, err := noteHandler.NoteService.GetNoteByID(context.Background(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заметки"})
+		return
+	}
+	created.Author = user.Username
 
-	c.JSON(http.StatusOK, note)
+	c.JSON(http.StatusOK, created)
 }
 
 // EditNoteHandler обрабатывает запрос на редактирование заметки.
@@ -101,27 +183,11 @@ func (noteHandler *NoteHandler) AddNote(c *gin.Context) {
 // @Param id path int true "Идентификатор заметки"
 // @Param body body models.NoteInput true "Новые данные заметки"
 // @Router /notes/{id} [put]
-func EditNoteHandler(ns services.NoteService, us *services.UserService, jwtKey string) gin.HandlerFunc {
+func EditNoteHandler(ns services.NoteService, us *services.UserService, tokens *auth.TokenManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Извлечение токена из куки запроса
-		tokenString, err := c.Cookie("token")
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
-			return
-		}
-
-		// Проверка токена
-		token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
-			return
-		}
-
-		claims, ok := token.Claims.(*models.Claims)
+		// Проверка токена сессии
+		claims, ok := authenticate(c, tokens)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
 			return
 		}
 		userID := claims.UserID
@@ -148,16 +214,17 @@ func EditNoteHandler(ns services.NoteService, us *services.UserService, jwtKey s
 		}
 
 		// Проверка, можно ли редактировать заметку (в течение 24 часов с момента создания).
-		if time.Since(note.CreatedAt) > 24*time.Hour {
+		if time.Since(note.CreatedAt) > noteEditWindow {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Заметка не может быть отредактирована по истечении 1 дня"})
 			return
 		}
 
-		var updatedNote models.Note
-		if err := c.BindJSON(&updatedNote); err != nil {
+		var input models.NoteInput
+		if err := c.BindJSON(&input); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Недопустимый формат запроса"})
 			return
 		}
+		updatedNote := noteFromInput(input)
 
 		// Проверяем длину заголовка и текста.
 		if !utils.CheckNoteLength(updatedNote.Title, updatedNote.Text) {
@@ -165,6 +232,36 @@ func EditNoteHandler(ns services.NoteService, us *services.UserService, jwtKey s
 			return
 		}
 
+		// Если формат не указан, сохраняется прежний
+		if updatedNote.Format == "" {
+			updatedNote.Format = note.Format
+		}
+		format, valid := utils.NormalizeNoteFormat(updatedNote.Format)
+		if !valid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат заметки. Допустимые значения: plain, markdown, checklist"})
+			return
+		}
+		updatedNote.Format = format
+
+		// Если пункты списка задач не переданы, сохраняются прежние
+		if updatedNote.Format == models.NoteFormatChecklist {
+			items := updatedNote.Items
+			if items == nil {
+				items = note.Items
+			}
+			prepared, text, httpErr := utils.PrepareChecklist(updatedNote.Title, items)
+			if httpErr != nil {
+				c.JSON(httpErr.Code, gin.H{"error": httpErr.Message})
+				return
+			}
+			if updatedNote.Items != nil {
+				updatedNote.Items = prepared
+			}
+			updatedNote.Text = text
+		} else {
+			updatedNote.Items = nil
+		}
+
 		// Получение информации об авторе заметки
 		author, err := us.GetUserByID(note.UserID)
 		if err != nil {
@@ -182,7 +279,14 @@ func EditNoteHandler(ns services.NoteService, us *services.UserService, jwtKey s
 			return
 		}
 
-		c.JSON(http.StatusOK, updatedNote)
+		stored, err := ns.GetNoteByID(context.Background(), noteID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении заметки"})
+			return
+		}
+		stored.Author = author.Username
+
+		c.JSON(http.StatusOK, stored)
 	}
 }
 
@@ -192,27 +296,11 @@ func EditNoteHandler(ns services.NoteService, us *services.UserService, jwtKey s
 // @Produce json
 // @Param id path int true "Идентификатор заметки"
 // @Router /notes/{id} [delete]
-func DeleteNoteHandler(ns services.NoteService, jwtKey string) gin.HandlerFunc {
+func DeleteNoteHandler(ns services.NoteService, tokens *auth.TokenManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Извлечение токена из куки запроса
-		tokenString, err := c.Cookie("token")
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
-			return
-		}
-
-		// Проверка токена
-		token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
-			return
-		}
-
-		claims, ok := token.Claims.(*models.Claims)
+		// Проверка токена сессии
+		claims, ok := authenticate(c, tokens)
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
 			return
 		}
 		userID := claims.UserID
@@ -248,9 +336,72 @@ func DeleteNoteHandler(ns services.NoteService, jwtKey string) gin.HandlerFunc {
 	}
 }
 
+// GetNoteHandler обрабатывает запрос на получение заметки.
+// @Summary Получение заметки
+// @Description Возвращает заметку целиком. С параметром render=html дополнительно возвращает текст, преобразованный в безопасный HTML (Markdown для заметок в формате markdown). Реакции на заметку возвращаются в полях reactions и my_reactions, отметки текущего пользователя — в полях pinned, favorite и archived.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param render query string false "Формат отображения: html"
+// @Router /notes/{id} [get]
+func GetNoteHandler(ns services.NoteService, us *services.UserService, rs *services.ReactionService, tokens *auth.TokenManager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Проверка токена сессии
+		claims, ok := authenticate(c, tokens)
+		if !ok {
+			return
+		}
+
+		noteID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор заметки"})
+			return
+		}
+
+		render := c.Query("render")
+		if render != "" && render != "html" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный формат отображения. Допустимое значение: html"})
+			return
+		}
+
+		note, err := ns.GetNoteByID(context.Background(), noteID)
+		if err != nil || !note.VisibleTo(claims.UserID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Заметка не найдена"})
+			return
+		}
+
+		author, err := us.GetUserByID(note.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении информации об авторе"})
+			return
+		}
+		note.Author = author.Username
+		note.BelongsToCurrentUser = note.UserID == claims.UserID
+
+		reactions, err := rs.GetSummary(context.Background(), note.ID, claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении реакций"})
+			return
+		}
+		note.Reactions = reactions.Counts
+		note.MyReactions = reactions.Mine
+
+		note.NoteFlags, err = ns.GetNoteFlags(context.Background(), note.ID, claims.UserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении отметок заметки"})
+			return
+		}
+
+		if render == "html" {
+			c.JSON(http.StatusOK, gin.H{"note": note, "html": utils.RenderNoteHTML(note.Text, note.Format)})
+			return
+		}
+		c.JSON(http.StatusOK, note)
+	}
+}
+
 // GetNotesHandler обрабатывает запрос на получение заметок с возможностью фильтрации.
 // @Summary Получение заметок
-// @Description Обрабатывает запрос на получение заметок с возможностью фильтрации.
+// @Description Обрабатывает запрос на получение заметок с возможностью фильтрации. Вместо полного текста возвращается краткое содержание без разметки (excerpt), полный текст — с параметром full=true. Для каждой заметки возвращается количество комментариев (comment_count), количество реакций каждого типа (reactions) и реакции текущего пользователя (my_reactions). Заметки, закрепленные текущим пользователем, идут первыми, заметки из его архива по умолчанию не возвращаются.
 // @Accept json
 // @Produce json
 // @Param start_date query string false "Дата начала в формате 'ГГГГ-ММ-ДД'"
@@ -259,31 +410,37 @@ func DeleteNoteHandler(ns services.NoteService, jwtKey string) gin.HandlerFunc {
 // @Param date query string false "Дата в формате 'ГГГГ-ММ-ДД'"
 // @Param page query int false "Номер страницы"
 // @Param limit query int false "Количество записей на странице"
+// @Param full query bool false "Вернуть полный текст заметок"
+// @Param sort query string false "Сортировка: new (по умолчанию), popular (по числу реакций) или trending (по числу реакций с учетом возраста заметки)"
+// @Param archived query bool false "Вернуть заметки из архива текущего пользователя вместо остальных"
+// @Param due_before query string false "Только заметки со сроком выполнения раньше указанного времени (RFC 3339 или 'ГГГГ-ММ-ДД')"
 // @Router /notes [get]
-func GetNotesHandler(ns services.NoteService, us services.UserService, jwtKey string) gin.HandlerFunc {
+func GetNotesHandler(ns services.NoteService, us services.UserService, rs *services.ReactionService, tokens *auth.TokenManager) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Извлечение токена из куки запроса
-		tokenString, err := c.Cookie("token")
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+		// Проверка токена сессии
+		claims, ok := authenticate(c, tokens)
+		if !ok {
 			return
 		}
+		currentUserID := claims.UserID
+		full := c.Query("full") == "true"
 
-		// Проверка и расшифровка токена
-		token, err := jwt.ParseWithClaims(tokenString, &models.Claims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(jwtKey), nil
-		})
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
+		sort := models.NoteSort(c.DefaultQuery("sort", string(models.NoteSortNew)))
+		switch sort {
+		case models.NoteSortNew, models.NoteSortPopular, models.NoteSortTrending:
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный порядок сортировки. Допустимые значения: new, popular, trending"})
 			return
 		}
-
-		claims, ok := token.Claims.(*models.Claims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не авторизован"})
-			return
+		opts := models.NoteListOptions{ViewerID: currentUserID, Sort: sort, Archived: c.Query("archived") == "true"}
+		if dueBeforeStr := c.Query("due_before"); dueBeforeStr != "" {
+			dueBefore, err := parseDueBefore(dueBeforeStr)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат due_before. Используйте RFC 3339 или 'ГГГГ-ММ-ДД'"})
+				return
+			}
+			opts.DueBefore = &dueBefore
 		}
-		currentUserID := claims.UserID
 
 		// Извлечение параметров фильтрации из URL-запроса
 		startDateStr := c.Query("start_date")
@@ -298,6 +455,7 @@ func GetNotesHandler(ns services.NoteService, us services.UserService, jwtKey st
 
 		// Преобразование параметров фильтрации
 		var startDate, endDate, date time.Time
+		var err error
 		if startDateStr != "" {
 			startDate, err = time.Parse("2006-01-02", startDateStr)
 			if err != nil {
@@ -351,17 +509,17 @@ func GetNotesHandler(ns services.NoteService, us services.UserService, jwtKey st
 		var errorGetNotes error
 		switch {
 		case !startDate.IsZero() && !endDate.IsZero() && username != "":
-			notes, errorGetNotes = ns.GetNotesByUserIDAndDateRange(context.Background(), filterUserID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), offset, limit)
+			notes, errorGetNotes = ns.GetNotesByUserIDAndDateRange(context.Background(), filterUserID, startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), opts, offset, limit)
 		case !startDate.IsZero() && !endDate.IsZero():
-			notes, errorGetNotes = ns.GetNotesByDateRange(context.Background(), startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), offset, limit)
+			notes, errorGetNotes = ns.GetNotesByDateRange(context.Background(), startDate.Format("2006-01-02"), endDate.Format("2006-01-02"), opts, offset, limit)
 		case !date.IsZero() && username != "":
-			notes, errorGetNotes = ns.GetNotesByUserIDAndDay(context.Background(), filterUserID, date.Format("2006-01-02"), offset, limit)
+			notes, errorGetNotes = ns.GetNotesByUserIDAndDay(context.Background(), filterUserID, date.Format("2006-01-02"), opts, offset, limit)
 		case !date.IsZero():
-			notes, errorGetNotes = ns.GetNotesByDay(context.Background(), date.Format("2006-01-02"), offset, limit)
+			notes, errorGetNotes = ns.GetNotesByDay(context.Background(), date.Format("2006-01-02"), opts, offset, limit)
 		case username != "":
-			notes, errorGetNotes = ns.GetNotesByUserID(context.Background(), filterUserID, offset, limit)
+			notes, errorGetNotes = ns.GetNotesByUserID(context.Background(), filterUserID, opts, offset, limit)
 		default:
-			notes, errorGetNotes = ns.GetNotes(context.Background(), offset, limit)
+			notes, errorGetNotes = ns.GetNotes(context.Background(), opts, offset, limit)
 		}
 
 		if errorGetNotes != nil {
@@ -371,29 +529,84 @@ func GetNotesHandler(ns services.NoteService, us services.UserService, jwtKey st
 
 		fmt.Println("Number of Notes:", len(notes))
 
-		// Создание списка для ответа
-		response := make([]gin.H, 0, len(notes))
-		for _, note := range notes {
-			author, err := us.GetUserByID(note.UserID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении информации об авторе заметки"})
-				return
-			}
+		if err := rs.FillReactions(context.Background(), notes, currentUserID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении реакций"})
+			return
+		}
 
-			noteData := gin.H{
-				"title":  note.Title,
-				"text":   note.Text,
-				"author": author.Username,
-			}
+		c.JSON(http.StatusOK, noteListResponse(notes, currentUserID, full))
+	}
+}
 
-			// Добавление признака belongsToCurrentUser только если он равен true
-			if note.UserID == currentUserID {
-				noteData["belongsToCurrentUser"] = true
-			}
+// noteListResponse формирует элементы списка заметок: краткое содержание вместо текста (полный текст — при full),
+// автора, количество комментариев, реакции и отметки текущего пользователя.
+func noteListResponse(notes []models.Note, currentUserID int, full bool) []gin.H {
+	response := make([]gin.H, 0, len(notes))
+	// Автор, количество комментариев и отметки получены тем же запросом, что и заметки
+	for _, note := range notes {
+		noteData := gin.H{
+			"id":            note.ID,
+			"title":         note.Title,
+			"excerpt":       utils.NoteExcerpt(note.Text, note.Format, utils.ExcerptLength),
+			"format":        note.Format,
+			"author":        note.Author,
+			"comment_count": note.CommentCount,
+			"reactions":     note.Reactions,
+			"my_reactions":  note.MyReactions,
+			"pinned":        note.Pinned,
+			"favorite":      note.Favorite,
+			"archived":      note.Archived,
+			"visibility":    note.Visibility,
+		}
+		if note.DueAt != nil {
+			noteData["due_at"] = note.DueAt
+		}
+		if note.RemindAt != nil {
+			noteData["remind_at"] = note.RemindAt
+		}
+		if note.Progress != nil {
+			noteData["progress"] = note.Progress
+		}
+		if len(note.Tags) > 0 {
+			noteData["tags"] = note.Tags
+		}
+		if note.Folder != "" {
+			noteData["folder"] = note.Folder
+		}
+		if full {
+			noteData["text"] = note.Text
+		}
 
-			response = append(response, noteData)
+		// Добавление признака belongsToCurrentUser только если он равен true
+		if note.UserID == currentUserID {
+			noteData["belongsToCurrentUser"] = true
 		}
 
-		c.JSON(http.StatusOK, response)
+		response = append(response, noteData)
+	}
+	return response
+}
+
+// parseDueBefore разбирает параметр due_before: время в формате RFC 3339 или дату (начало дня по UTC).
+func parseDueBefore(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// noteFromParam возвращает заметку из параметра id, если она видна текущему пользователю: личная заметка
+// другого пользователя считается ненайденной. При ошибке ответ уже отправлен.
+func noteFromParam(c *gin.Context, ns services.NoteService) (*models.Note, bool) {
+	noteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор заметки"})
+		return nil, false
+	}
+	note, err := ns.GetNoteByID(context.Background(), noteID)
+	if err != nil || !note.VisibleTo(currentUserID(c)) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Заметка не найдена"})
+		return nil, false
 	}
+	return note, true
 }
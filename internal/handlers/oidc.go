@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/oidc"
+	"note_app/internal/services"
+	"note_app/pkg/utils"
+)
+
+const (
+	// oidcStateCookie имя куки, в которой хранится состояние входа между редиректами.
+	oidcStateCookie = "oidc_state"
+	// oidcCookiePath путь, для которого отправляется куки состояния.
+	oidcCookiePath = "/auth/oidc"
+)
+
+// OIDCHandler обрабатывает вход через OpenID Connect провайдера.
+type OIDCHandler struct {
+	OIDCService    *services.OIDCService
+	SessionService *services.SessionService
+	Tokens         *auth.TokenManager
+	Cookie         utils.CookieOptions
+}
+
+// NewOIDCHandler создает новый экземпляр OIDCHandler.
+func NewOIDCHandler(oidcService *services.OIDCService, sessionService *services.SessionService, tokens *auth.TokenManager, cookie utils.CookieOptions) *OIDCHandler {
+	return &OIDCHandler{
+		OIDCService:    oidcService,
+		SessionService: sessionService,
+		Tokens:         tokens,
+		Cookie:         cookie,
+	}
+}
+
+// Login перенаправляет пользователя на страницу входа провайдера.
+// @Summary Вход через OpenID Connect
+// @Description Перенаправляет на страницу входа провайдера (authorization code + PKCE). С параметром link=true привязывает внешнюю учетную запись к текущему пользователю.
+// @Param link query bool false "Привязать учетную запись к текущему пользователю"
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *gin.Context) {
+	state := &models.OIDCStateClaims{}
+	if c.Query("link") == "true" {
+		claims, ok := authenticate(c, h.Tokens)
+		if !ok {
+			return
+		}
+		state.LinkUserID = claims.UserID
+	}
+
+	var err error
+	if state.State, err = oidc.RandomString(); err == nil {
+		if state.Nonce, err = oidc.RandomString(); err == nil {
+			state.Verifier, err = oidc.RandomString()
+		}
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подготовке входа"})
+		return
+	}
+
+	authURL, err := h.OIDCService.AuthURL(context.Background(), state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		if errors.Is(err, services.ErrOIDCDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Вход через OpenID Connect выключен"})
+			return
+		}
+		log.Printf("Ошибка при обращении к провайдеру OpenID Connect: %v", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Провайдер OpenID Connect недоступен"})
+		return
+	}
+
+	stateToken, err := h.Tokens.IssueOIDCState(state)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подготовке входа"})
+		return
+	}
+
+	// Куки состояния должна приходить при возврате с сайта провайдера, поэтому SameSite=Lax
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, stateToken, 600, oidcCookiePath, h.Cookie.Domain, h.Cookie.Secure, true)
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback завершает вход через OpenID Connect и выдает токен доступа.
+// @Summary Завершение входа через OpenID Connect
+// @Description Обменивает код авторизации на id_token, находит или создает пользователя и выдает токен доступа
+// @Produce json
+// @Param code query string true "Код авторизации"
+// @Param state query string true "Состояние"
+// @Router /auth/oidc/callback [get]
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	stateToken, err := c.Cookie(oidcStateCookie)
+	c.SetCookie(oidcStateCookie, "", -1, oidcCookiePath, h.Cookie.Domain, h.Cookie.Secure, true)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Вход не был начат или время ожидания истекло"})
+		return
+	}
+
+	state, err := h.Tokens.ParseOIDCState(stateToken)
+	if err != nil || state.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное состояние входа"})
+		return
+	}
+
+	if providerErr := c.Query("error"); providerErr != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Провайдер отклонил вход: " + providerErr})
+		return
+	}
+
+	user, err := h.OIDCService.Login(context.Background(), c.Query("code"), state.Verifier, state.Nonce, state.LinkUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrOIDCDisabled):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Вход через OpenID Connect выключен"})
+		case errors.Is(err, services.ErrIdentityNotLinked):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Внешняя учетная запись не привязана к пользователю"})
+		case errors.Is(err, services.ErrIdentityLinkedToOtherUser):
+			c.JSON(http.StatusConflict, gin.H{"error": "Внешняя учетная запись уже привязана к другому пользователю"})
+		default:
+			log.Printf("Ошибка входа через OpenID Connect: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Не удалось выполнить вход через OpenID Connect"})
+		}
+		return
+	}
+
+	if user.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Учетная запись заблокирована"})
+		return
+	}
+
+	if state.LinkUserID != 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "Внешняя учетная запись привязана"})
+		return
+	}
+
+	// Как и при входе по паролю, при включенной 2FA требуется второй шаг
+	if user.TOTPEnabled {
+		challengeToken, err := h.Tokens.IssueChallenge(user.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"two_factor_required": true, "challenge_token": challengeToken})
+		return
+	}
+
+	issueSession(c, user, h.Tokens, h.SessionService, h.Cookie)
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+)
+
+// ReactionHandler обрабатывает запросы на добавление и удаление реакций на заметки.
+type ReactionHandler struct {
+	ReactionService *services.ReactionService
+	NoteService     services.NoteService
+	Tokens          *auth.TokenManager
+}
+
+// NewReactionHandler создает новый экземпляр ReactionHandler.
+func NewReactionHandler(reactionService *services.ReactionService, noteService services.NoteService, tokens *auth.TokenManager) *ReactionHandler {
+	return &ReactionHandler{
+		ReactionService: reactionService,
+		NoteService:     noteService,
+		Tokens:          tokens,
+	}
+}
+
+// AddReaction добавляет реакцию текущего пользователя на заметку.
+// @Summary Добавление реакции
+// @Description Добавляет реакцию на заметку: like 👍, love ❤️, laugh 😂, wow 😮, sad 😢, party 🎉 или fire 🔥. Каждый тип реакции пользователь может поставить один раз. Возвращает реакции на заметку.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param reaction body models.ReactionInput true "Тип реакции"
+// @Router /notes/{id}/reactions [post]
+func (h *ReactionHandler) AddReaction(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	var input models.ReactionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	if err := h.ReactionService.AddReaction(context.Background(), note.ID, claims.UserID, input.Reaction); err != nil {
+		writeReactionError(c, err, "Ошибка при добавлении реакции")
+		return
+	}
+	h.writeSummary(c, note.ID, claims.UserID)
+}
+
+// RemoveReaction удаляет реакцию текущего пользователя на заметку.
+// @Summary Удаление реакции
+// @Description Удаляет реакцию текущего пользователя на заметку. Возвращает реакции на заметку.
+// @Produce json
+// @Param id path int true "Идентификатор заметки"
+// @Param reaction path string true "Тип реакции"
+// @Router /notes/{id}/reactions/{reaction} [delete]
+func (h *ReactionHandler) RemoveReaction(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	note, ok := noteFromParam(c, h.NoteService)
+	if !ok {
+		return
+	}
+
+	if err := h.ReactionService.RemoveReaction(context.Background(), note.ID, claims.UserID, c.Param("reaction")); err != nil {
+		writeReactionError(c, err, "Ошибка при удалении реакции")
+		return
+	}
+	h.writeSummary(c, note.ID, claims.UserID)
+}
+
+// writeSummary отправляет реакции на заметку.
+func (h *ReactionHandler) writeSummary(c *gin.Context, noteID, userID int) {
+	summary, err := h.ReactionService.GetSummary(context.Background(), noteID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении реакций"})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
+// writeReactionError отправляет ответ с ошибкой операции над реакцией.
+func writeReactionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrUnknownReaction):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный тип реакции"})
+	case errors.Is(err, services.ErrReactionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Реакция не найдена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// SessionHandler обрабатывает запросы на просмотр и отзыв сессий пользователя.
+type SessionHandler struct {
+	SessionService *services.SessionService
+	Tokens         *auth.TokenManager
+}
+
+// NewSessionHandler создает новый экземпляр SessionHandler.
+func NewSessionHandler(sessionService *services.SessionService, tokens *auth.TokenManager) *SessionHandler {
+	return &SessionHandler{
+		SessionService: sessionService,
+		Tokens:         tokens,
+	}
+}
+
+// GetSessions возвращает активные сессии текущего пользователя.
+// @Summary Список сессий
+// @Description Возвращает устройства, на которых выполнен вход: браузер и система, User-Agent, IP-адрес, время входа и последней активности. Текущая сессия отмечена признаком current.
+// @Produce json
+// @Router /me/sessions [get]
+func (h *SessionHandler) GetSessions(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.SessionService.GetSessions(context.Background(), claims.UserID, claims.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении сессий"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession отзывает сессию текущего пользователя.
+// @Summary Отзыв сессии
+// @Description Завершает сессию на другом устройстве или текущую сессию. Токен сессии перестает приниматься сразу.
+// @Produce json
+// @Param id path int true "Идентификатор сессии"
+// @Router /me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор сессии"})
+		return
+	}
+
+	if err := h.SessionService.RevokeSession(context.Background(), claims.UserID, sessionID); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Сессия не найдена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при отзыве сессии"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Сессия завершена"})
+}
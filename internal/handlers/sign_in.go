@@ -1,9 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
-	"golang.org/x/crypto/bcrypt"
 	"net/http"
+	"note_app/internal/auth"
 	"note_app/internal/models"
 	"note_app/internal/services"
 	"note_app/pkg/utils"
@@ -11,24 +12,28 @@ import (
 
 // LoginHandler обрабатывает запросы на аутентификацию пользователя.
 type LoginHandler struct {
-	UserService *services.UserService
-	JWTKey      []byte
+	UserService    *services.UserService
+	SessionService *services.SessionService
+	Tokens         *auth.TokenManager
+	Cookie         utils.CookieOptions
 }
 
 // NewSignInHandler создает новый экземпляр LoginHandler для обработки запросов на аутентификацию.
-func NewSignInHandler(userService *services.UserService, jwtKey string) *LoginHandler {
+func NewSignInHandler(userService *services.UserService, sessionService *services.SessionService, tokens *auth.TokenManager, cookie utils.CookieOptions) *LoginHandler {
 	return &LoginHandler{
-		UserService: userService,
-		JWTKey:      []byte(jwtKey),
+		UserService:    userService,
+		SessionService: sessionService,
+		Tokens:         tokens,
+		Cookie:         cookie,
 	}
 }
 
 // SignIn выполняет вход пользователя.
 // @Summary Вход пользователя
-// @Description Аутентифицирует пользователя и генерирует токен доступа
+// @Description Аутентифицирует пользователя и генерирует токен доступа. Токен устанавливается в куки вместе с CSRF-токеном, который нужно передавать в заголовке X-CSRF-Token в изменяющих запросах. Если у пользователя включена 2FA, возвращает challenge_token для второго шага входа.
 // @Accept json
 // @Produce json
-// @Param body body models.UserInput true "Данные пользователя для входа"
+// @Param body body models.SignInInput true "Данные пользователя для входа"
 // @Router /signin [post]
 func (loginHandler *LoginHandler) SignIn(c *gin.Context) {
 	var user models.User
@@ -43,18 +48,71 @@ func (loginHandler *LoginHandler) SignIn(c *gin.Context) {
 		return
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(dbUser.Password), []byte(user.Password)); err != nil {
+	if !loginHandler.UserService.CheckPassword(dbUser, user.Password) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверное имя пользователя или пароль"})
 		return
 	}
 
-	tokenString, err := utils.GenerateToken(dbUser.ID, loginHandler.JWTKey)
+	if dbUser.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Учетная запись заблокирована"})
+		return
+	}
+
+	// При включенной 2FA выдаем только токен второго шага, токен сессии выдается после проверки кода
+	if dbUser.TOTPEnabled {
+		challengeToken, err := loginHandler.Tokens.IssueChallenge(dbUser.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"two_factor_required": true, "challenge_token": challengeToken})
+		return
+	}
+
+	issueSession(c, dbUser, loginHandler.Tokens, loginHandler.SessionService, loginHandler.Cookie)
+}
+
+// SignInTwoFactor завершает вход пользователя с включенной 2FA.
+// @Summary Второй шаг входа
+// @Description Проверяет код TOTP или код восстановления и генерирует токен доступа. Токен первого шага действует для одного входа. После нескольких неверных кодов подряд токен первого шага становится недействительным, а ввод кода пользователем блокируется на время; в этом случае возвращается 429.
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorSignInInput true "Токен первого шага и код подтверждения"
+// @Router /signin/2fa [post]
+func (loginHandler *LoginHandler) SignInTwoFactor(c *gin.Context) {
+	var input models.TwoFactorSignInInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверное тело запроса"})
+		return
+	}
+
+	challenge, err := loginHandler.Tokens.ParseChallenge(input.ChallengeToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный или просроченный токен входа"})
+		return
+	}
+
+	if err := loginHandler.UserService.VerifySignInChallenge(challenge, input.Code, input.RecoveryCode); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTooManyTwoFactorAttempts):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много попыток ввода кода, повторите вход позже"})
+		case errors.Is(err, services.ErrTwoFactorChallengeUsed):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный или просроченный токен входа"})
+		default:
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный код подтверждения"})
+		}
 		return
 	}
 
-	utils.SetTokenCookie(c.Writer, tokenString)
+	dbUser, err := loginHandler.UserService.GetUserByID(challenge.UserID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный или просроченный токен входа"})
+		return
+	}
+	if dbUser.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Учетная запись заблокирована"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"token": tokenString})
+	issueSession(c, dbUser, loginHandler.Tokens, loginHandler.SessionService, loginHandler.Cookie)
 }
@@ -1,27 +1,29 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"note_app/internal/models"
 	"note_app/internal/services"
-	"note_app/pkg/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UserHandler обрабатывает запросы пользователя.
 type UserHandler struct {
-	UserService *services.UserService
+	UserService    *services.UserService
+	AccountService *services.AccountService
 }
 
 // NewSignupHandler создает новый экземпляр UserHandler для обработки запросов на регистрацию пользователя.
-func NewSignupHandler(userService *services.UserService) *UserHandler {
-	return &UserHandler{UserService: userService}
+func NewSignupHandler(userService *services.UserService, accountService *services.AccountService) *UserHandler {
+	return &UserHandler{UserService: userService, AccountService: accountService}
 }
 
 // SignUp регистрирует нового пользователя.
 // @Summary Регистрация пользователя
-// @Description Регистрирует нового пользователя с заданными данными
+// @Description Регистрирует нового пользователя с заданными данными. Если указан email, на него отправляется письмо для подтверждения.
 // @Accept json
 // @Produce json
 // @Param body body models.UserInput true "Данные нового пользователя"
@@ -33,12 +35,12 @@ func (userHandler *UserHandler) SignUp(c *gin.Context) {
 		return
 	}
 
-	if err := utils.ValidateUser(&user); err != nil {
+	if err := userHandler.UserService.ValidateUser(&user); err != nil {
 		c.JSON(err.Code, gin.H{"error": err.Message})
 		return
 	}
 
-	hashedPassword, err := utils.HashPassword(user.Password)
+	hashedPassword, err := userHandler.UserService.HashPassword(user.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при хэшировании пароля"})
 		return
@@ -50,5 +52,14 @@ func (userHandler *UserHandler) SignUp(c *gin.Context) {
 		return
 	}
 
+	// Ошибка отправки письма не отменяет регистрацию: письмо можно запросить повторно
+	if user.Email != "" {
+		if created, err := userHandler.UserService.GetUserByUsername(user.Username); err == nil {
+			if err := userHandler.AccountService.SendEmailVerification(context.Background(), created.ID); err != nil {
+				log.Printf("Ошибка при отправке письма подтверждения: %v", err)
+			}
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Пользователь успешно зарегистрирован"})
 }
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// TemplateHandler обрабатывает запросы на работу с шаблонами заметок.
+type TemplateHandler struct {
+	TemplateService *services.TemplateService
+	UserService     *services.UserService
+	Tokens          *auth.TokenManager
+}
+
+// NewTemplateHandler создает новый экземпляр TemplateHandler.
+func NewTemplateHandler(templateService *services.TemplateService, userService *services.UserService, tokens *auth.TokenManager) *TemplateHandler {
+	return &TemplateHandler{
+		TemplateService: templateService,
+		UserService:     userService,
+		Tokens:          tokens,
+	}
+}
+
+// CreateTemplate создает шаблон заметки.
+// @Summary Создание шаблона
+// @Description Создает шаблон заметки текущего пользователя. Заголовок и текст могут содержать подстановки {{date}}, {{time}}, {{datetime}}, {{username}} и произвольные {{имя}}, значения которых передаются при создании заметки. Общий шаблон (global) может создать только администратор.
+// @Accept json
+// @Produce json
+// @Param template body models.TemplateInput true "Данные шаблона"
+// @Router /templates [post]
+func (h *TemplateHandler) CreateTemplate(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var input models.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	template, err := h.TemplateService.CreateTemplate(context.Background(), user, input)
+	if err != nil {
+		writeTemplateError(c, err, "Ошибка при создании шаблона")
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates возвращает шаблоны, доступные пользователю.
+// @Summary Список шаблонов
+// @Description Возвращает шаблоны текущего пользователя и общие шаблоны.
+// @Produce json
+// @Router /templates [get]
+func (h *TemplateHandler) GetTemplates(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	templates, err := h.TemplateService.GetTemplates(context.Background(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении шаблонов"})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate возвращает шаблон.
+// @Summary Получение шаблона
+// @Description Возвращает шаблон текущего пользователя или общий шаблон.
+// @Produce json
+// @Param id path int true "Идентификатор шаблона"
+// @Router /templates/{id} [get]
+func (h *TemplateHandler) GetTemplate(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	templateID, ok := templateIDParam(c)
+	if !ok {
+		return
+	}
+
+	template, err := h.TemplateService.GetTemplate(context.Background(), templateID, claims.UserID)
+	if err != nil {
+		writeTemplateError(c, err, "Ошибка при получении шаблона")
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// EditTemplate изменяет шаблон.
+// @Summary Изменение шаблона
+// @Description Изменяет шаблон. Личный шаблон изменяет его владелец, общий — администратор. Признак global не изменяется.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор шаблона"
+// @Param template body models.TemplateInput true "Новые данные шаблона"
+// @Router /templates/{id} [put]
+func (h *TemplateHandler) EditTemplate(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	templateID, ok := templateIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input models.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	template, err := h.TemplateService.UpdateTemplate(context.Background(), templateID, user, input)
+	if err != nil {
+		writeTemplateError(c, err, "Ошибка при изменении шаблона")
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate удаляет шаблон.
+// @Summary Удаление шаблона
+// @Description Удаляет шаблон. Личный шаблон удаляет его владелец, общий — администратор. Заметки, созданные по шаблону, сохраняются.
+// @Produce json
+// @Param id path int true "Идентификатор шаблона"
+// @Router /templates/{id} [delete]
+func (h *TemplateHandler) DeleteTemplate(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+	templateID, ok := templateIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.TemplateService.DeleteTemplate(context.Background(), templateID, user); err != nil {
+		writeTemplateError(c, err, "Ошибка при удалении шаблона")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Шаблон удален"})
+}
+
+// currentUser возвращает текущего пользователя вместе с ролью, от которой зависят права на общие шаблоны.
+func (h *TemplateHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return nil, false
+	}
+	user, err := h.UserService.GetUserByID(claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении информации о пользователе"})
+		return nil, false
+	}
+	return user, true
+}
+
+// writeTemplateError отправляет ответ с ошибкой операции над шаблоном.
+func writeTemplateError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidTemplate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTemplateNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Шаблон не найден"})
+	case errors.Is(err, services.ErrTemplateForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав для этого шаблона"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// templateIDParam возвращает идентификатор шаблона из параметра id.
+func templateIDParam(c *gin.Context) (int, bool) {
+	templateID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор шаблона"})
+		return 0, false
+	}
+	return templateID, true
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+)
+
+// TwoFactorHandler обрабатывает запросы на управление двухфакторной аутентификацией.
+type TwoFactorHandler struct {
+	UserService *services.UserService
+	Tokens      *auth.TokenManager
+	Issuer      string
+}
+
+// NewTwoFactorHandler создает новый экземпляр TwoFactorHandler.
+func NewTwoFactorHandler(userService *services.UserService, tokens *auth.TokenManager, issuer string) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		UserService: userService,
+		Tokens:      tokens,
+		Issuer:      issuer,
+	}
+}
+
+// Enroll начинает подключение двухфакторной аутентификации.
+// @Summary Подключение 2FA
+// @Description Генерирует секрет TOTP и otpauth:// URI для приложения-аутентификатора. 2FA включается после подтверждения кодом.
+// @Produce json
+// @Router /2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	enrollment, err := h.UserService.BeginTOTPEnrollment(claims.UserID, h.Issuer)
+	if err != nil {
+		if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Двухфакторная аутентификация уже включена"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при подключении двухфакторной аутентификации"})
+		return
+	}
+
+	c.JSON(http.StatusOK, enrollment)
+}
+
+// Confirm подтверждает подключение двухфакторной аутентификации.
+// @Summary Подтверждение 2FA
+// @Description Проверяет код из приложения-аутентификатора, включает 2FA и возвращает одноразовые коды восстановления
+// @Accept json
+// @Produce json
+// @Param body body models.TOTPCodeInput true "Код из приложения-аутентификатора"
+// @Router /2fa/confirm [post]
+func (h *TwoFactorHandler) Confirm(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	var input models.TOTPCodeInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	codes, err := h.UserService.ConfirmTOTPEnrollment(claims.UserID, input.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTOTPAlreadyEnabled):
+			c.JSON(http.StatusConflict, gin.H{"error": "Двухфакторная аутентификация уже включена"})
+		case errors.Is(err, services.ErrTOTPNotEnrolled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Подключение двухфакторной аутентификации не начато"})
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный код подтверждения"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при включении двухфакторной аутентификации"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recovery_codes": codes})
+}
+
+// Disable выключает двухфакторную аутентификацию.
+// @Summary Выключение 2FA
+// @Description Выключает 2FA после проверки кода TOTP или кода восстановления. После нескольких неверных кодов подряд ввод кода блокируется на время; в этом случае возвращается 429.
+// @Accept json
+// @Produce json
+// @Param body body models.TwoFactorCodeInput true "Код TOTP или код восстановления"
+// @Router /2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	var input models.TwoFactorCodeInput
+	if err := c.BindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат запроса"})
+		return
+	}
+
+	if err := h.UserService.DisableTOTP(claims.UserID, input.Code, input.RecoveryCode); err != nil {
+		switch {
+		case errors.Is(err, services.ErrTOTPNotEnrolled):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Двухфакторная аутентификация не включена"})
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный код подтверждения"})
+		case errors.Is(err, services.ErrTooManyTwoFactorAttempts):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Слишком много попыток ввода кода, повторите позже"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при выключении двухфакторной аутентификации"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Двухфакторная аутентификация выключена"})
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"note_app/internal/auth"
+	"note_app/internal/models"
+	"note_app/internal/services"
+	"strconv"
+)
+
+// WebhookHandler обрабатывает запросы на управление веб-хуками. Управлять веб-хуками можно только с токеном
+// сессии, но не с персональным токеном доступа.
+type WebhookHandler struct {
+	WebhookService *services.WebhookService
+	Tokens         *auth.TokenManager
+}
+
+// NewWebhookHandler создает новый экземпляр WebhookHandler.
+func NewWebhookHandler(webhookService *services.WebhookService, tokens *auth.TokenManager) *WebhookHandler {
+	return &WebhookHandler{
+		WebhookService: webhookService,
+		Tokens:         tokens,
+	}
+}
+
+// CreateWebhook создает веб-хук.
+// @Summary Создание веб-хука
+// @Description Создает веб-хук: события заметок (note.created, note.updated, note.deleted) отправляются на адрес POST-запросом в том же формате, что и в GET /events. Пустой список events означает все события, author_id оставляет события заметок одного автора. Тело запроса подписывается HMAC-SHA256 ключом secret, подпись передается в заголовке X-Signature-256 (sha256=<hex>). Ключ возвращается только один раз. Адреса во внутренней сети не допускаются, перенаправления не выполняются.
+// @Accept json
+// @Produce json
+// @Param body body models.WebhookInput true "Адрес и фильтры веб-хука"
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	var input models.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	webhook, err := h.WebhookService.Create(context.Background(), claims.UserID, input)
+	if err != nil {
+		writeWebhookError(c, err, "Ошибка при создании веб-хука")
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// GetWebhooks возвращает веб-хуки текущего пользователя.
+// @Summary Список веб-хуков
+// @Description Возвращает веб-хуки текущего пользователя без ключей подписи
+// @Produce json
+// @Router /webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.WebhookService.GetWebhooks(context.Background(), claims.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении веб-хуков"})
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook возвращает веб-хук.
+// @Summary Получение веб-хука
+// @Description Возвращает веб-хук текущего пользователя без ключа подписи
+// @Produce json
+// @Param id path int true "Идентификатор веб-хука"
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	webhook, err := h.WebhookService.Get(context.Background(), claims.UserID, webhookID)
+	if err != nil {
+		writeWebhookError(c, err, "Ошибка при получении веб-хука")
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// EditWebhook изменяет веб-хук.
+// @Summary Изменение веб-хука
+// @Description Изменяет адрес, фильтры и состояние (active) веб-хука. Ключ подписи не меняется.
+// @Accept json
+// @Produce json
+// @Param id path int true "Идентификатор веб-хука"
+// @Param body body models.WebhookInput true "Адрес и фильтры веб-хука"
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) EditWebhook(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	var input models.WebhookInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	webhook, err := h.WebhookService.Update(context.Background(), claims.UserID, webhookID, input)
+	if err != nil {
+		writeWebhookError(c, err, "Ошибка при изменении веб-хука")
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook удаляет веб-хук.
+// @Summary Удаление веб-хука
+// @Description Удаляет веб-хук текущего пользователя вместе с журналом доставок
+// @Produce json
+// @Param id path int true "Идентификатор веб-хука"
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.WebhookService.Delete(context.Background(), claims.UserID, webhookID); err != nil {
+		writeWebhookError(c, err, "Ошибка при удалении веб-хука")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Веб-хук удален"})
+}
+
+// GetDeliveries возвращает журнал доставок веб-хука.
+// @Summary Журнал доставок веб-хука
+// @Description Возвращает доставки веб-хука, начиная с последних: событие, состояние (pending, delivered, failed), число попыток, время следующей попытки, код ответа и ошибку последней попытки.
+// @Produce json
+// @Param id path int true "Идентификатор веб-хука"
+// @Param page query int false "Номер страницы"
+// @Param limit query int false "Количество записей на странице"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+
+	deliveries, err := h.WebhookService.GetDeliveries(context.Background(), claims.UserID, webhookID, (page-1)*limit, limit)
+	if err != nil {
+		writeWebhookError(c, err, "Ошибка при получении доставок веб-хука")
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver повторяет доставку веб-хука.
+// @Summary Повторная доставка веб-хука
+// @Description Ставит событие доставки в очередь повторно. Создается новая доставка со ссылкой на прежнюю (redelivery_of), прежняя остается в журнале.
+// @Produce json
+// @Param id path int true "Идентификатор веб-хука"
+// @Param deliveryId path int true "Идентификатор доставки"
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	claims, ok := authenticate(c, h.Tokens)
+	if !ok {
+		return
+	}
+	webhookID, ok := webhookIDParam(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("deliveryId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор доставки"})
+		return
+	}
+
+	delivery, err := h.WebhookService.Redeliver(context.Background(), claims.UserID, webhookID, deliveryID)
+	if err != nil {
+		writeWebhookError(c, err, "Ошибка при повторе доставки веб-хука")
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// writeWebhookError отправляет ответ с ошибкой операции над веб-хуком.
+func writeWebhookError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrInvalidWebhook):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrWebhookNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Веб-хук не найден"})
+	case errors.Is(err, services.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Доставка не найдена"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// webhookIDParam возвращает идентификатор веб-хука из параметра id.
+func webhookIDParam(c *gin.Context) (int, bool) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный идентификатор веб-хука"})
+		return 0, false
+	}
+	return webhookID, true
+}
@@ -0,0 +1,173 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"note_app/internal/models"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// enexTimeLayout формат времени в файлах ENEX.
+const enexTimeLayout = "20060102T150405Z"
+
+// enexNote заметка файла ENEX. Вложения (resource) и теги не импортируются.
+type enexNote struct {
+	Title   string `xml:"title"`
+	Content string `xml:"content"`
+	Created string `xml:"created"`
+}
+
+// parseENEX читает заметки из файла выгрузки Evernote. Содержимое заметок (ENML) преобразуется в Markdown.
+func parseENEX(data []byte) ([]Entry, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	var entries []Entry
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат файла ENEX: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+
+		var enex enexNote
+		if err := decoder.DecodeElement(&enex, &start); err != nil {
+			return nil, fmt.Errorf("неверный формат файла ENEX: %v", err)
+		}
+		note, err := convertENEXNote(&enex)
+		entries = append(entries, Entry{Name: enex.Title, Note: note, Err: err})
+	}
+	if entries == nil {
+		return nil, errors.New("файл ENEX не содержит заметок")
+	}
+	return entries, nil
+}
+
+// convertENEXNote преобразует заметку Evernote в заметку в формате markdown.
+func convertENEXNote(enex *enexNote) (*models.Note, error) {
+	text, err := enmlToMarkdown(enex.Content)
+	if err != nil {
+		return nil, err
+	}
+	note := &models.Note{
+		Title:  strings.TrimSpace(enex.Title),
+		Text:   text,
+		Format: models.NoteFormatMarkdown,
+	}
+	if enex.Created != "" {
+		created, err := time.Parse(enexTimeLayout, enex.Created)
+		if err != nil {
+			return nil, fmt.Errorf("неверное время создания: %s", enex.Created)
+		}
+		note.CreatedAt = created
+	}
+	return note, nil
+}
+
+// enmlBlockElements элементы ENML, начинающиеся с новой строки.
+var enmlBlockElements = map[string]bool{
+	"div": true, "p": true, "li": true, "tr": true, "blockquote": true, "pre": true,
+	"ul": true, "ol": true, "table": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+var (
+	// enmlSpacePattern последовательность пробельных символов в тексте ENML.
+	enmlSpacePattern = regexp.MustCompile(`\s+`)
+	// enmlBlankLinesPattern больше одной пустой строки подряд.
+	enmlBlankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// enmlToMarkdown преобразует содержимое заметки Evernote (XHTML-документ en-note) в Markdown:
+// абзацы и переводы строк сохраняются, заголовки и элементы списков размечаются, отметки en-todo
+// становятся пунктами списка задач. Остальная разметка отбрасывается.
+func enmlToMarkdown(content string) (string, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	var b strings.Builder
+	newLine := func() {
+		if s := b.String(); s != "" && !strings.HasSuffix(s, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	atLineStart := func() bool {
+		s := b.String()
+		return s == "" || strings.HasSuffix(s, "\n")
+	}
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("неверное содержимое заметки: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case name == "br":
+				b.WriteByte('\n')
+			case name == "hr":
+				newLine()
+				b.WriteString("---\n")
+			case name == "en-todo":
+				if atLineStart() {
+					b.WriteString("- ")
+				}
+				if enmlAttr(t, "checked") == "true" {
+					b.WriteString("[x] ")
+				} else {
+					b.WriteString("[ ] ")
+				}
+			case enmlBlockElements[name]:
+				newLine()
+				if len(name) == 2 && name[0] == 'h' {
+					b.WriteString(strings.Repeat("#", int(name[1]-'0')) + " ")
+				} else if name == "li" {
+					b.WriteString("- ")
+				}
+			}
+		case xml.EndElement:
+			if enmlBlockElements[strings.ToLower(t.Name.Local)] {
+				newLine()
+			}
+		case xml.CharData:
+			text := enmlSpacePattern.ReplaceAllString(string(t), " ")
+			if atLineStart() {
+				text = strings.TrimLeft(text, " ")
+			}
+			b.WriteString(text)
+		}
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	text := enmlBlankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text), nil
+}
+
+// enmlAttr возвращает значение атрибута элемента.
+func enmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
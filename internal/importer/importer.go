@@ -0,0 +1,112 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"note_app/internal/models"
+	"path"
+	"strings"
+)
+
+// Форматы загружаемых файлов.
+const (
+	FormatJSON        = "json"
+	FormatMarkdownZip = "markdown-zip"
+	FormatENEX        = "enex"
+)
+
+var (
+	// ErrUnknownFormat возвращается, если формат файла не указан явно и не определяется по расширению.
+	ErrUnknownFormat = errors.New("неизвестный формат файла")
+	// ErrInvalidFile возвращается, если файл не удалось разобрать.
+	ErrInvalidFile = errors.New("не удалось прочитать файл")
+	// ErrTooManyNotes возвращается, если файл содержит больше заметок, чем допустимо.
+	ErrTooManyNotes = errors.New("слишком много заметок в файле")
+)
+
+// Entry запись загружаемого файла: заметка, которую удалось прочитать, ошибка чтения или причина пропуска.
+type Entry struct {
+	// Name имя файла в архиве или заголовок заметки.
+	Name string
+	Note *models.Note
+	Err  error
+	// Skip причина, по которой запись не является заметкой и пропускается.
+	Skip string
+}
+
+// DetectFormat возвращает формат файла: format, если он указан, иначе формат по расширению имени файла.
+func DetectFormat(format, filename string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(path.Ext(filename)) {
+		case ".json":
+			format = FormatJSON
+		case ".zip":
+			format = FormatMarkdownZip
+		case ".enex":
+			format = FormatENEX
+		}
+	}
+	switch format {
+	case FormatJSON, FormatMarkdownZip, FormatENEX:
+		return format, nil
+	default:
+		return "", ErrUnknownFormat
+	}
+}
+
+// Parse читает записи файла data в формате format. Заметки в записях не проверяются на допустимость,
+// кроме разбора самого файла. Если заметок больше maxNotes, возвращается ErrTooManyNotes. Файлы ZIP-архива
+// вместе не могут занимать после распаковки больше maxUnpacked байт.
+func Parse(format string, data []byte, maxNotes int, maxUnpacked int64) ([]Entry, error) {
+	var entries []Entry
+	var err error
+	switch format {
+	case FormatJSON:
+		entries, err = parseJSON(data)
+	case FormatMarkdownZip:
+		entries, err = parseMarkdownZip(data, maxNotes, maxUnpacked)
+	case FormatENEX:
+		entries, err = parseENEX(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if errors.Is(err, ErrTooManyNotes) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	notes := 0
+	for _, entry := range entries {
+		if entry.Skip == "" {
+			notes++
+		}
+	}
+	if notes > maxNotes {
+		return nil, tooManyNotes(notes, maxNotes)
+	}
+	return entries, nil
+}
+
+// tooManyNotes возвращает ErrTooManyNotes с числом заметок в файле.
+func tooManyNotes(notes, maxNotes int) error {
+	return fmt.Errorf("%w: %d, допустимо не более %d", ErrTooManyNotes, notes, maxNotes)
+}
+
+// fromExported возвращает заметку из записи выгрузки.
+func fromExported(exported *models.ExportedNote) *models.Note {
+	return &models.Note{
+		Title:      exported.Title,
+		Text:       exported.Text,
+		Format:     exported.Format,
+		CreatedAt:  exported.CreatedAt,
+		DueAt:      exported.DueAt,
+		RemindAt:   exported.RemindAt,
+		NoteFlags:  models.NoteFlags{Pinned: exported.Pinned, Favorite: exported.Favorite, Archived: exported.Archived},
+		Items:      exported.Items,
+		Tags:       exported.Tags,
+		Folder:     exported.Folder,
+		Visibility: exported.Visibility,
+	}
+}
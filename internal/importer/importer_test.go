@@ -0,0 +1,142 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildZip собирает ZIP-архив из файлов name — содержимое.
+func buildZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestParseMarkdownZip(t *testing.T) {
+	data := buildZip(t, map[string]string{
+		"a.md":      "---\ntitle: Первая\nformat: plain\n---\n\nТекст",
+		"b.md":      "Без заголовка",
+		"image.png": "png",
+	})
+
+	entries, err := Parse(FormatMarkdownZip, data, 10, 1<<20)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	notes, skipped := 0, 0
+	for _, entry := range entries {
+		switch {
+		case entry.Skip != "":
+			skipped++
+		case entry.Err != nil:
+			t.Errorf("%s: %v", entry.Name, entry.Err)
+		default:
+			notes++
+			if entry.Name == "a.md" && (entry.Note.Title != "Первая" || entry.Note.Text != "Текст") {
+				t.Errorf("a.md: заголовок %q, текст %q", entry.Note.Title, entry.Note.Text)
+			}
+			if entry.Name == "b.md" && entry.Note.Title != "b" {
+				t.Errorf("b.md: заголовок %q, ожидался заголовок из имени файла", entry.Note.Title)
+			}
+		}
+	}
+	if notes != 2 || skipped != 1 {
+		t.Errorf("заметок %d, пропущено %d; ожидалось 2 и 1", notes, skipped)
+	}
+}
+
+func TestParseMarkdownTagsFolderVisibility(t *testing.T) {
+	content := "---\ntitle: Личная\ntags:\n  - работа\n  - дом\nfolder: Проекты\nvisibility: private\n---\n\nТекст"
+	note, err := parseMarkdown("a.md", []byte(content))
+	if err != nil {
+		t.Fatalf("parseMarkdown: %v", err)
+	}
+	if len(note.Tags) != 2 || note.Tags[0] != "работа" || note.Tags[1] != "дом" {
+		t.Errorf("метки %q", note.Tags)
+	}
+	if note.Folder != "Проекты" || note.Visibility != "private" {
+		t.Errorf("папка %q, видимость %q", note.Folder, note.Visibility)
+	}
+}
+
+func TestParseMarkdownZipTooManyNotes(t *testing.T) {
+	files := make(map[string]string)
+	for i := 0; i < 5; i++ {
+		files[fmt.Sprintf("%d.md", i)] = "текст"
+	}
+
+	_, err := Parse(FormatMarkdownZip, buildZip(t, files), 4, 1<<20)
+	if !errors.Is(err, ErrTooManyNotes) {
+		t.Fatalf("ожидалась ErrTooManyNotes, получено %v", err)
+	}
+}
+
+func TestParseMarkdownZipUnpackedLimit(t *testing.T) {
+	// Хорошо сжимаемые файлы: архив намного меньше распакованного содержимого
+	files := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		files[fmt.Sprintf("%d.md", i)] = strings.Repeat("a", 64<<10)
+	}
+	data := buildZip(t, files)
+	if len(data) >= 256<<10 {
+		t.Fatalf("архив должен хорошо сжиматься, размер %d", len(data))
+	}
+
+	_, err := Parse(FormatMarkdownZip, data, 100, 256<<10)
+	if !errors.Is(err, ErrInvalidFile) {
+		t.Fatalf("ожидалась ErrInvalidFile из-за распакованного размера, получено %v", err)
+	}
+
+	if _, err := Parse(FormatMarkdownZip, data, 100, 20*64<<10); err != nil {
+		t.Fatalf("архив в пределах ограничения: %v", err)
+	}
+}
+
+func TestParseJSONTooManyNotes(t *testing.T) {
+	data := []byte(`[{"title": "1", "text": "a"}, {"title": "2", "text": "b"}, {"title": "3", "text": "c"}]`)
+
+	if _, err := Parse(FormatJSON, data, 2, 1<<20); !errors.Is(err, ErrTooManyNotes) {
+		t.Fatalf("ожидалась ErrTooManyNotes, получено %v", err)
+	}
+	entries, err := Parse(FormatJSON, data, 3, 1<<20)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Parse: %d записей, ошибка %v", len(entries), err)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		format, filename, want string
+	}{
+		{"", "notes.JSON", FormatJSON},
+		{"", "export.zip", FormatMarkdownZip},
+		{"", "evernote.enex", FormatENEX},
+		{FormatJSON, "export.zip", FormatJSON},
+	}
+	for _, tt := range tests {
+		got, err := DetectFormat(tt.format, tt.filename)
+		if err != nil || got != tt.want {
+			t.Errorf("DetectFormat(%q, %q) = %q, %v; ожидался %q", tt.format, tt.filename, got, err, tt.want)
+		}
+	}
+	if _, err := DetectFormat("", "notes.txt"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("ожидалась ErrUnknownFormat, получено %v", err)
+	}
+}
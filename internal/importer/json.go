@@ -0,0 +1,27 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// parseJSON читает JSON-массив заметок в формате выгрузки GET /me/export?format=json. Элемент, который
+// не удалось разобрать, возвращается с ошибкой, остальные элементы читаются.
+func parseJSON(data []byte) ([]Entry, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("файл должен содержать JSON-массив заметок: %v", err)
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		var exported models.ExportedNote
+		if err := json.Unmarshal(item, &exported); err != nil {
+			entries = append(entries, Entry{Err: fmt.Errorf("неверный формат заметки: %v", err)})
+			continue
+		}
+		entries = append(entries, Entry{Name: exported.Title, Note: fromExported(&exported)})
+	}
+	return entries, nil
+}
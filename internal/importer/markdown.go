@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"note_app/internal/models"
+	"path"
+	"strings"
+)
+
+// frontMatterDelimiter строка, открывающая и закрывающая YAML-заголовок Markdown-файла.
+const frontMatterDelimiter = "---"
+
+// parseMarkdownZip читает ZIP-архив Markdown-файлов, например выгрузку GET /me/export?format=markdown-zip.
+// Файлы с другими расширениями пропускаются. Число Markdown-файлов проверяется по maxNotes до распаковки,
+// а их общий распакованный размер не может превышать maxUnpacked байт, что защищает от архивов с высокой
+// степенью сжатия. Размеры из заголовков архива не принимаются на веру: распакованные байты считаются при чтении.
+func parseMarkdownZip(data []byte, maxNotes int, maxUnpacked int64) ([]Entry, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("файл не является ZIP-архивом: %v", err)
+	}
+
+	markdown := 0
+	for _, file := range archive.File {
+		if !file.FileInfo().IsDir() && isMarkdownFile(file.Name) {
+			markdown++
+		}
+	}
+	if markdown > maxNotes {
+		return nil, tooManyNotes(markdown, maxNotes)
+	}
+
+	var entries []Entry
+	remaining := maxUnpacked
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !isMarkdownFile(file.Name) {
+			entries = append(entries, Entry{Name: file.Name, Skip: "не Markdown-файл"})
+			continue
+		}
+		if file.UncompressedSize64 > uint64(remaining) {
+			return nil, unpackedTooLarge(maxUnpacked)
+		}
+
+		content, err := readZipFile(file, remaining)
+		if errors.Is(err, errZipFileTooLarge) {
+			return nil, unpackedTooLarge(maxUnpacked)
+		}
+		if err != nil {
+			entries = append(entries, Entry{Name: file.Name, Err: err})
+			continue
+		}
+		remaining -= int64(len(content))
+		note, err := parseMarkdown(file.Name, content)
+		entries = append(entries, Entry{Name: file.Name, Note: note, Err: err})
+	}
+	return entries, nil
+}
+
+// errZipFileTooLarge возвращается readZipFile, если файл больше допустимого размера.
+var errZipFileTooLarge = errors.New("файл слишком большой")
+
+// isMarkdownFile сообщает, является ли файл архива Markdown-файлом.
+func isMarkdownFile(name string) bool {
+	return strings.ToLower(path.Ext(name)) == ".md"
+}
+
+// unpackedTooLarge возвращает ошибку превышения общего распакованного размера архива.
+func unpackedTooLarge(maxUnpacked int64) error {
+	return fmt.Errorf("распакованные файлы архива больше %d байт", maxUnpacked)
+}
+
+// readZipFile читает файл архива, но не больше maxSize байт.
+func readZipFile(file *zip.File, maxSize int64) ([]byte, error) {
+	r, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распаковать файл: %v", err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось распаковать файл: %v", err)
+	}
+	if int64(len(content)) > maxSize {
+		return nil, errZipFileTooLarge
+	}
+	return content, nil
+}
+
+// parseMarkdown читает заметку из Markdown-файла с необязательным YAML-заголовком. Без заголовка
+// заметка получает формат markdown и заголовок из имени файла.
+func parseMarkdown(name string, content []byte) (*models.Note, error) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+
+	var meta models.ExportedNote
+	if rest, ok := strings.CutPrefix(text, frontMatterDelimiter+"\n"); ok {
+		// Ищем закрывающую строку, в том числе сразу после открывающей и в конце файла без перевода строки
+		block := "\n" + rest
+		if !strings.HasSuffix(block, "\n") {
+			block += "\n"
+		}
+		end := strings.Index(block, "\n"+frontMatterDelimiter+"\n")
+		if end < 0 {
+			return nil, errors.New("не закрыт YAML-заголовок")
+		}
+		if err := yaml.Unmarshal([]byte(block[:end]), &meta); err != nil {
+			return nil, fmt.Errorf("неверный YAML-заголовок: %v", err)
+		}
+		text = block[end+len(frontMatterDelimiter)+2:]
+		// Выгрузка отделяет текст от заголовка пустой строкой
+		text = strings.TrimPrefix(text, "\n")
+	}
+	meta.Text = strings.TrimSuffix(text, "\n")
+
+	if meta.Title == "" {
+		meta.Title = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	}
+	if meta.Format == "" {
+		meta.Format = models.NoteFormatMarkdown
+	}
+	return fromExported(&meta), nil
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer записывает письма в файл или в стандартный лог вместо отправки.
+// Используется для локальной разработки и тестирования без почтового сервера.
+type LogMailer struct {
+	from string
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogMailer создает новый экземпляр LogMailer. Если path пуст, письма пишутся в стандартный лог.
+func NewLogMailer(from, path string) *LogMailer {
+	return &LogMailer{from: from, path: path}
+}
+
+// Send записывает письмо.
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	entry := fmt.Sprintf("--- %s\nFrom: %s\nTo: %s\nSubject: %s\n\n%s\n",
+		time.Now().Format(time.RFC3339), m.from, msg.To, msg.Subject, msg.Body)
+
+	if m.path == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("ошибка при открытии файла писем: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(entry); err != nil {
+		return fmt.Errorf("ошибка при записи письма: %v", err)
+	}
+	return nil
+}
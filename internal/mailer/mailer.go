@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"note_app/internal/config"
+)
+
+// Message представляет письмо, отправляемое пользователю.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer интерфейс для отправки писем.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New создает Mailer в соответствии с конфигурацией.
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPMailer(cfg.From, cfg.SMTP), nil
+	case "log", "":
+		return NewLogMailer(cfg.From, cfg.LogFile), nil
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер почты: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,81 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"note_app/internal/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPMailer отправляет письма через SMTP-сервер.
+type SMTPMailer struct {
+	from string
+	cfg  config.SMTPConfig
+}
+
+// NewSMTPMailer создает новый экземпляр SMTPMailer.
+func NewSMTPMailer(from string, cfg config.SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{from: from, cfg: cfg}
+}
+
+// Send отправляет письмо. Если сервер поддерживает STARTTLS, соединение шифруется.
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	// В конверте SMTP нужен только адрес, без отображаемого имени
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("неверный адрес отправителя: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, sender.Address, []string{msg.To}, buildMessage(m.from, msg))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("ошибка при отправке письма: %v", err)
+		}
+		return nil
+	}
+}
+
+// buildMessage формирует письмо в формате RFC 5322.
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + mimeHeader(msg.Subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// mimeHeader кодирует заголовок с не-ASCII символами (RFC 2047).
+func mimeHeader(s string) string {
+	for _, r := range s {
+		if r > 127 {
+			return mime.BEncoding.Encode("UTF-8", s)
+		}
+	}
+	return s
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Области действия персональных токенов доступа.
+const (
+	ScopeNotesRead  = "notes:read"
+	ScopeNotesWrite = "notes:write"
+)
+
+// AccessTokenPrefix префикс персональных токенов доступа, отличающий их от JWT.
+const AccessTokenPrefix = "nap_"
+
+// AccessToken персональный токен доступа для скриптов и интеграций. В базе данных хранится только хэш токена.
+type AccessToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"-"`
+	Name       string     `json:"name"`
+	TokenHash  string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope проверяет, выдан ли токен на указанную область действия.
+func (t *AccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessTokenInput данные для создания персонального токена доступа.
+type AccessTokenInput struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresInDays срок действия в днях. 0 — бессрочный токен.
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+// CreatedAccessToken созданный токен вместе с его значением, которое возвращается только один раз.
+type CreatedAccessToken struct {
+	AccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Attachment файл, прикрепленный к заметке. Содержимое хранится в хранилище файлов под ключом StorageKey.
+type Attachment struct {
+	ID          int       `json:"id"`
+	NoteID      int       `json:"note_id"`
+	UserID      int       `json:"-"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+package models
+
+// BulkOp операция над несколькими заметками.
+type BulkOp string
+
+// Операции над несколькими заметками. Удалять, отмечать метками, перемещать в папку и менять видимость можно
+// только свои заметки; архив — личная отметка, она ставится на любую видимую пользователю заметку.
+const (
+	BulkOpDelete    BulkOp = "delete"
+	BulkOpArchive   BulkOp = "archive"
+	BulkOpUnarchive BulkOp = "unarchive"
+	// BulkOpTag добавляет заметкам метки Tags.
+	BulkOpTag BulkOp = "tag"
+	// BulkOpMove перемещает заметки в папку Folder; пустая строка убирает заметки из папки.
+	BulkOpMove BulkOp = "move"
+	// BulkOpVisibility устанавливает заметкам видимость Visibility.
+	BulkOpVisibility BulkOp = "visibility"
+)
+
+// ChangesNote сообщает, изменяет ли операция саму заметку, а не личные отметки пользователя. Такие операции
+// доступны только автору заметки.
+func (op BulkOp) ChangesNote() bool {
+	switch op {
+	case BulkOpDelete, BulkOpTag, BulkOpMove, BulkOpVisibility:
+		return true
+	default:
+		return false
+	}
+}
+
+// Результаты операции над заметкой.
+const (
+	BulkStatusOK        = "ok"
+	BulkStatusNotFound  = "not_found"
+	BulkStatusForbidden = "forbidden"
+)
+
+// BulkOperation операция над заметками с идентификаторами IDs.
+type BulkOperation struct {
+	Op  BulkOp `json:"op"`
+	IDs []int  `json:"ids"`
+	// Tags метки для операции tag.
+	Tags []string `json:"tags,omitempty"`
+	// Folder папка для операции move.
+	Folder *string `json:"folder,omitempty"`
+	// Visibility видимость для операции visibility: public или private.
+	Visibility string `json:"visibility,omitempty"`
+}
+
+// BulkInput операции над несколькими заметками, выполняемые по порядку в одной транзакции.
+type BulkInput struct {
+	Operations []BulkOperation `json:"operations"`
+}
+
+// BulkResult результат операции над одной заметкой.
+type BulkResult struct {
+	Op     BulkOp `json:"op"`
+	NoteID int    `json:"note_id"`
+	Status string `json:"status"`
+}
+
+// BulkReport результат операций над несколькими заметками. Операции применяются, только если все они
+// допустимы для каждой заметки; иначе Applied равен false и ни одна заметка не изменяется.
+type BulkReport struct {
+	Applied bool         `json:"applied"`
+	Results []BulkResult `json:"results"`
+}
@@ -0,0 +1,26 @@
+package models
+
+// ChecklistItem пункт заметки-списка задач.
+type ChecklistItem struct {
+	ID       int    `json:"id"`
+	Position int    `json:"position"`
+	Text     string `json:"text"`
+	Checked  bool   `json:"checked"`
+}
+
+// ChecklistProgress количество пунктов списка задач: всего и выполненных.
+type ChecklistProgress struct {
+	Total int `json:"total"`
+	Done  int `json:"done"`
+}
+
+// ChecklistItemInput данные нового пункта списка задач.
+type ChecklistItemInput struct {
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+// ChecklistOrderInput новый порядок пунктов списка задач: идентификаторы всех пунктов в нужном порядке.
+type ChecklistOrderInput struct {
+	ItemIDs []int `json:"item_ids"`
+}
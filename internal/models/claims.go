@@ -1,11 +1,26 @@
 package models
 
 import (
-	"github.com/golang-jwt/jwt"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 // Claims представляет пользовательские утверждения JWT.
 type Claims struct {
 	UserID int `json:"user_id"`
-	jwt.StandardClaims
+	// Role роль пользователя на момент выдачи токена.
+	Role string `json:"role,omitempty"`
+	// Purpose задает назначение токена. Пустое значение означает обычный токен сессии.
+	Purpose string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// OIDCStateClaims состояние входа через OpenID Connect, которое хранится в куки между редиректами.
+type OIDCStateClaims struct {
+	Purpose  string `json:"purpose"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	// LinkUserID пользователь, к которому нужно привязать внешнюю учетную запись. 0 — обычный вход.
+	LinkUserID int `json:"link_user_id,omitempty"`
+	jwt.RegisteredClaims
 }
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Comment комментарий к заметке.
+type Comment struct {
+	ID        int        `json:"id"`
+	NoteID    int        `json:"note_id"`
+	UserID    int        `json:"user_id"`
+	Author    string     `json:"author"`
+	Text      string     `json:"text"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// CommentInput данные для создания или изменения комментария.
+type CommentInput struct {
+	Text string `json:"text"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Типы событий заметок.
+const (
+	EventNoteCreated = "note.created"
+	EventNoteUpdated = "note.updated"
+	EventNoteDeleted = "note.deleted"
+	// EventResync сообщает, что часть событий после Last-Event-ID недоступна и список заметок нужно запросить заново.
+	EventResync = "resync"
+)
+
+// NoteEvent событие изменения заметки.
+type NoteEvent struct {
+	// ID идентификатор события: в потоках GET /events — для продолжения потока (Last-Event-ID),
+	// у веб-хуков и NATS — номер события в таблице исходящих событий.
+	ID   string    `json:"id"`
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	// NoteID и UserID заметка и ее автор.
+	NoteID int `json:"note_id,omitempty"`
+	UserID int `json:"user_id,omitempty"`
+	// Note краткие данные созданной или измененной заметки.
+	Note *NoteEventData `json:"note,omitempty"`
+	// Private событие личной заметки: в потоках и веб-хуках оно передается только автору заметки.
+	Private bool `json:"private,omitempty"`
+}
+
+// NoteEventData краткие данные заметки в событии, как в элементе списка заметок.
+type NoteEventData struct {
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Excerpt   string     `json:"excerpt"`
+	Format    string     `json:"format"`
+	Author    string     `json:"author"`
+	CreatedAt time.Time  `json:"created_at"`
+	DueAt     *time.Time `json:"due_at,omitempty"`
+}
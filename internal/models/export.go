@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ExportedNote заметка в выгрузке заметок пользователя. В Markdown-выгрузке текст заметки записывается
+// после YAML-заголовка, поэтому в заголовок он не входит.
+type ExportedNote struct {
+	ID        int        `json:"id" yaml:"id"`
+	Title     string     `json:"title" yaml:"title"`
+	Format    string     `json:"format" yaml:"format"`
+	CreatedAt time.Time  `json:"created_at" yaml:"created_at"`
+	DueAt     *time.Time `json:"due_at,omitempty" yaml:"due_at,omitempty"`
+	RemindAt  *time.Time `json:"remind_at,omitempty" yaml:"remind_at,omitempty"`
+	Pinned    bool       `json:"pinned" yaml:"pinned"`
+	Favorite  bool       `json:"favorite" yaml:"favorite"`
+	Archived  bool       `json:"archived" yaml:"archived"`
+	// Tags метки, Folder папка и Visibility видимость заметки: public или private.
+	Tags       []string `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Folder     string   `json:"folder,omitempty" yaml:"folder,omitempty"`
+	Visibility string   `json:"visibility" yaml:"visibility"`
+	// Items пункты заметки-списка задач. В Markdown-выгрузке они содержатся в тексте в виде списка задач.
+	Items []ChecklistItem `json:"items,omitempty" yaml:"-"`
+	Text  string          `json:"text" yaml:"-"`
+}
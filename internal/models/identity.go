@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// UserIdentity внешняя учетная запись OpenID Connect, привязанная к пользователю.
+type UserIdentity struct {
+	ID        int
+	UserID    int
+	Issuer    string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+}
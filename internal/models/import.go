@@ -0,0 +1,29 @@
+package models
+
+// Результаты загрузки записи файла.
+const (
+	ImportStatusCreated = "created"
+	ImportStatusSkipped = "skipped"
+	ImportStatusFailed  = "failed"
+)
+
+// ImportResult результат загрузки одной записи файла.
+type ImportResult struct {
+	// Index порядковый номер записи в файле, начиная с 1.
+	Index int `json:"index"`
+	// Name имя файла в архиве или заголовок заметки.
+	Name   string `json:"name,omitempty"`
+	Status string `json:"status"`
+	// NoteID идентификатор созданной заметки.
+	NoteID int `json:"note_id,omitempty"`
+	// Message причина пропуска или ошибки.
+	Message string `json:"message,omitempty"`
+}
+
+// ImportReport отчет о загрузке заметок из файла.
+type ImportReport struct {
+	Created int            `json:"created"`
+	Skipped int            `json:"skipped"`
+	Failed  int            `json:"failed"`
+	Items   []ImportResult `json:"items"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// NoteLinkRef ссылка из текста заметки: [[заголовок]] или [[#id]].
+type NoteLinkRef struct {
+	// Text текст ссылки внутри двойных квадратных скобок без лишних пробелов.
+	Text string
+	// TargetID идентификатор заметки для ссылки вида [[#id]], 0 для ссылки по заголовку.
+	TargetID int
+}
+
+// NoteLink исходящая ссылка заметки.
+type NoteLink struct {
+	Text string `json:"text"`
+	// TargetID заметка, на которую указывает ссылка, nil для битой ссылки.
+	TargetID *int `json:"target_id"`
+	// TargetTitle текущий заголовок заметки, на которую указывает ссылка.
+	TargetTitle string `json:"target_title,omitempty"`
+	Broken      bool   `json:"broken"`
+}
+
+// Backlink заметка, ссылающаяся на другую заметку.
+type Backlink struct {
+	NoteID    int       `json:"note_id"`
+	Title     string    `json:"title"`
+	Author    string    `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	// Text текст ссылки в заметке.
+	Text string `json:"text"`
+}
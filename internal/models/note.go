@@ -2,16 +2,103 @@ package models
 
 import "time"
 
+// Форматы текста заметки (поле Format).
+const (
+	NoteFormatPlain    = "plain"
+	NoteFormatMarkdown = "markdown"
+	// NoteFormatChecklist список задач: пункты хранятся отдельно, а текст заметки формируется из них
+	// в виде списка задач Markdown.
+	NoteFormatChecklist = "checklist"
+)
+
+// Видимость заметки (поле Visibility).
+const (
+	// NoteVisibilityPublic заметку видят все пользователи.
+	NoteVisibilityPublic = "public"
+	// NoteVisibilityPrivate заметку видит только ее автор.
+	NoteVisibilityPrivate = "private"
+)
+
+// NoteSort порядок сортировки списка заметок.
+type NoteSort string
+
+// Порядки сортировки списка заметок.
+const (
+	// NoteSortNew сначала новые заметки.
+	NoteSortNew NoteSort = "new"
+	// NoteSortPopular сначала заметки с наибольшим числом реакций.
+	NoteSortPopular NoteSort = "popular"
+	// NoteSortTrending сначала заметки, быстро набирающие реакции: число реакций уменьшается с возрастом заметки.
+	NoteSortTrending NoteSort = "trending"
+)
+
+// NoteListOptions параметры списка заметок, не относящиеся к фильтрам.
+type NoteListOptions struct {
+	// ViewerID пользователь, просматривающий список: его отметки определяют закрепленные и архивные заметки.
+	ViewerID int
+	Sort     NoteSort
+	// Archived возвращать архивные заметки пользователя вместо неархивных.
+	Archived bool
+	// DueBefore если задано, возвращаются только заметки со сроком выполнения раньше этого времени.
+	DueBefore *time.Time
+}
+
+// NoteFlag отметка пользователя на заметке.
+type NoteFlag string
+
+// Отметки пользователя на заметке.
+const (
+	NoteFlagPinned   NoteFlag = "pinned"
+	NoteFlagFavorite NoteFlag = "favorite"
+	NoteFlagArchived NoteFlag = "archived"
+)
+
+// NoteFlags отметки пользователя на заметке: закрепленная, избранная, в архиве.
+type NoteFlags struct {
+	Pinned   bool `json:"pinned"`
+	Favorite bool `json:"favorite"`
+	Archived bool `json:"archived"`
+}
+
 type Note struct {
 	ID                   int       `json:"id"`
 	UserID               int       `json:"user_id"`
 	Title                string    `json:"title"`
 	Text                 string    `json:"text"`
+	Format               string    `json:"format"`
 	CreatedAt            time.Time `json:"created_at"`
 	Author               string    `json:"author"`
+	CommentCount         int       `json:"comment_count"`
 	BelongsToCurrentUser bool      `json:"belongs_to_current_user,omitempty"`
+	NoteFlags
+	// Reactions количество реакций каждого типа, MyReactions реакции текущего пользователя.
+	Reactions   map[string]int `json:"reactions,omitempty"`
+	MyReactions []string       `json:"my_reactions,omitempty"`
+	// DueAt срок выполнения, RemindAt время напоминания автору заметки.
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	RemindAt *time.Time `json:"remind_at,omitempty"`
+	// Items пункты и Progress прогресс заметки-списка задач.
+	Items    []ChecklistItem    `json:"items,omitempty"`
+	Progress *ChecklistProgress `json:"progress,omitempty"`
+	// Tags метки заметки, Folder папка (пустая строка — без папки), Visibility видимость: public или private.
+	Tags       []string `json:"tags,omitempty"`
+	Folder     string   `json:"folder,omitempty"`
+	Visibility string   `json:"visibility,omitempty"`
 }
+
+// VisibleTo сообщает, видна ли заметка пользователю userID: личную заметку видит только ее автор.
+func (n *Note) VisibleTo(userID int) bool {
+	return n.Visibility != NoteVisibilityPrivate || n.UserID == userID
+}
+
 type NoteInput struct {
 	Title string `json:"title"`
 	Text  string `json:"text"`
+	// Format формат текста: plain (по умолчанию), markdown или checklist.
+	Format string `json:"format"`
+	// Items пункты списка задач для формата checklist, текст заметки в этом случае не указывается.
+	Items []ChecklistItemInput `json:"items"`
+	// DueAt срок выполнения и RemindAt время напоминания в формате RFC 3339. Не указанные поля сбрасываются.
+	DueAt    *time.Time `json:"due_at"`
+	RemindAt *time.Time `json:"remind_at"`
 }
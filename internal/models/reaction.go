@@ -0,0 +1,24 @@
+package models
+
+// ReactionTypes допустимые типы реакций на заметки и соответствующие им эмодзи.
+var ReactionTypes = map[string]string{
+	"like":  "👍",
+	"love":  "❤️",
+	"laugh": "😂",
+	"wow":   "😮",
+	"sad":   "😢",
+	"party": "🎉",
+	"fire":  "🔥",
+}
+
+// ReactionInput данные для добавления реакции.
+type ReactionInput struct {
+	// Reaction тип реакции: like, love, laugh, wow, sad, party или fire.
+	Reaction string `json:"reaction"`
+}
+
+// ReactionSummary реакции на заметку: количество каждого типа и реакции текущего пользователя.
+type ReactionSummary struct {
+	Counts map[string]int `json:"reactions"`
+	Mine   []string       `json:"my_reactions"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Reminder напоминание о заметке, которое нужно отправить ее автору.
+type Reminder struct {
+	NoteID   int        `json:"note_id"`
+	UserID   int        `json:"user_id"`
+	Username string     `json:"username"`
+	Email    string     `json:"-"`
+	Title    string     `json:"title"`
+	DueAt    *time.Time `json:"due_at,omitempty"`
+	RemindAt time.Time  `json:"remind_at"`
+	// Attempts номер текущей попытки отправки.
+	Attempts int `json:"-"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// Session сессия пользователя, созданная при входе. Связана с токеном сессии через его идентификатор (jti).
+type Session struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"-"`
+	TokenID    string     `json:"-"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"-"`
+	// Current признак сессии, с которой выполнен запрос.
+	Current bool `json:"current,omitempty"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// Stats сводная статистика приложения для администратора.
+type Stats struct {
+	Users         int `json:"users"`
+	DisabledUsers int `json:"disabled_users"`
+	Admins        int `json:"admins"`
+	Notes         int `json:"notes"`
+	NotesLastDay  int `json:"notes_last_day"`
+}
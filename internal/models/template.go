@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Template шаблон заметки. Заголовок и текст могут содержать подстановки вида {{date}} и {{username}},
+// которые заменяются при создании заметки по шаблону.
+type Template struct {
+	ID int `json:"id"`
+	// UserID владелец шаблона, 0 для общего шаблона.
+	UserID int `json:"user_id,omitempty"`
+	// Global общий шаблон, доступный всем пользователям. Общие шаблоны изменяют администраторы.
+	Global    bool       `json:"global"`
+	Name      string     `json:"name"`
+	Title     string     `json:"title"`
+	Text      string     `json:"text"`
+	Format    string     `json:"format"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// TemplateInput данные шаблона заметки.
+type TemplateInput struct {
+	Name  string `json:"name"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	// Format формат текста заметок по шаблону: plain (по умолчанию) или markdown.
+	Format string `json:"format"`
+	// Global создать общий шаблон. Доступно только администраторам.
+	Global bool `json:"global"`
+}
+
+// TemplateNoteInput данные заметки, создаваемой по шаблону.
+type TemplateNoteInput struct {
+	// Variables значения подстановок шаблона. Перекрывают встроенные подстановки date, time, datetime и username.
+	Variables map[string]string `json:"variables"`
+	DueAt     *time.Time        `json:"due_at"`
+	RemindAt  *time.Time        `json:"remind_at"`
+}
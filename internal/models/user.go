@@ -1,11 +1,100 @@
 package models
 
+// Роли пользователей.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Role роль пользователя: RoleUser или RoleAdmin.
+	Role string `json:"-"`
+	// Disabled признак заблокированной учетной записи.
+	Disabled bool `json:"-"`
+	// Email необязательный адрес электронной почты, используется для восстановления пароля.
+	Email string `json:"email,omitempty"`
+	// EmailVerified признак того, что владение адресом подтверждено.
+	EmailVerified bool `json:"-"`
+	// TOTPSecret секрет TOTP в кодировке base32. Заполнен с момента начала подключения 2FA.
+	TOTPSecret string `json:"-"`
+	// TOTPEnabled признак того, что подключение 2FA подтверждено.
+	TOTPEnabled bool `json:"-"`
+	// TOTPLastStep номер последнего использованного шага TOTP для защиты от повторного использования кода.
+	TOTPLastStep int64 `json:"-"`
 }
 type UserInput struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Email    string `json:"email"`
+}
+
+// UserInfo сведения о пользователе, возвращаемые администратору.
+type UserInfo struct {
+	ID            int    `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
+	Role          string `json:"role"`
+	Disabled      bool   `json:"disabled"`
+	TOTPEnabled   bool   `json:"totp_enabled"`
+}
+
+// RoleInput новая роль пользователя.
+type RoleInput struct {
+	Role string `json:"role"`
+}
+
+// SignInInput данные пользователя для входа.
+type SignInInput struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// EmailInput адрес электронной почты.
+type EmailInput struct {
+	Email string `json:"email"`
+}
+
+// TokenInput одноразовый токен из письма.
+type TokenInput struct {
+	Token string `json:"token"`
+}
+
+// ForgotPasswordInput данные для запроса восстановления пароля: имя пользователя или email.
+type ForgotPasswordInput struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// ResetPasswordInput данные для установки нового пароля по токену из письма.
+type ResetPasswordInput struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// TOTPEnrollment данные для подключения приложения-аутентификатора.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// TOTPCodeInput одноразовый код из приложения-аутентификатора.
+type TOTPCodeInput struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorCodeInput второй фактор: код TOTP либо код восстановления.
+type TwoFactorCodeInput struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorSignInInput данные второго шага входа: токен первого шага и код TOTP либо код восстановления.
+type TwoFactorSignInInput struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
 }
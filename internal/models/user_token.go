@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Назначения одноразовых токенов пользователя.
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+// UserToken одноразовый токен, отправляемый пользователю по почте. В базе данных хранится только хэш токена.
+type UserToken struct {
+	ID        int
+	UserID    int
+	Purpose   string
+	TokenHash string
+	// Email адрес, на который был отправлен токен. Для подтверждения email он должен совпадать с текущим адресом.
+	Email     string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Состояния доставки веб-хука.
+const (
+	// WebhookDeliveryPending доставка ожидает отправки или повторной попытки.
+	WebhookDeliveryPending = "pending"
+	// WebhookDeliveryDelivered получатель ответил кодом 2xx.
+	WebhookDeliveryDelivered = "delivered"
+	// WebhookDeliveryFailed все попытки доставки завершились ошибкой.
+	WebhookDeliveryFailed = "failed"
+)
+
+// Webhook адрес, на который отправляются события заметок. Secret используется для подписи тела запроса
+// и возвращается только при создании веб-хука.
+type Webhook struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"-"`
+	URL    string `json:"url"`
+	Secret string `json:"-"`
+	// Events типы событий, которые отправляются на адрес. Пустой список — все события.
+	Events []string `json:"events"`
+	// AuthorID если задан, отправляются только события заметок этого автора.
+	AuthorID  *int       `json:"author_id,omitempty"`
+	Active    bool       `json:"active"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// WebhookInput данные для создания и изменения веб-хука.
+type WebhookInput struct {
+	URL      string   `json:"url"`
+	Events   []string `json:"events"`
+	AuthorID *int     `json:"author_id"`
+	// Active если не указан, веб-хук при создании включен, а при изменении сохраняет прежнее состояние.
+	Active *bool `json:"active"`
+}
+
+// CreatedWebhook созданный веб-хук вместе с ключом подписи, который возвращается только один раз.
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// WebhookDelivery доставка события на адрес веб-хука и результат последней попытки.
+type WebhookDelivery struct {
+	ID        int64  `json:"id"`
+	WebhookID int    `json:"webhook_id"`
+	EventID   string `json:"event_id"`
+	// RedeliveryOf идентификатор доставки, повторенной по запросу пользователя.
+	RedeliveryOf *int64          `json:"redelivery_of,omitempty"`
+	EventType    string          `json:"event_type"`
+	Payload      json.RawMessage `json:"payload"`
+	Status       string          `json:"status"`
+	Attempts     int             `json:"attempts"`
+	// NextAttemptAt время следующей попытки ожидающей доставки.
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	// ResponseStatus код ответа и Error ошибка последней попытки.
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	// URL и Secret веб-хука, нужные для отправки.
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
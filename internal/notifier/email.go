@@ -0,0 +1,39 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"note_app/internal/mailer"
+	"note_app/internal/models"
+	"time"
+)
+
+// EmailNotifier отправляет напоминания письмом на подтвержденный адрес автора заметки.
+type EmailNotifier struct {
+	mail   mailer.Mailer
+	appURL string
+}
+
+// NewEmailNotifier создает новый экземпляр EmailNotifier.
+func NewEmailNotifier(mail mailer.Mailer, appURL string) *EmailNotifier {
+	return &EmailNotifier{mail: mail, appURL: appURL}
+}
+
+// Notify отправляет письмо с напоминанием. Пользователи без подтвержденного адреса пропускаются.
+func (n *EmailNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	if reminder.Email == "" {
+		return nil
+	}
+
+	body := fmt.Sprintf("Здравствуйте, %s!\n\nНапоминаем о заметке «%s».\n", reminder.Username, reminder.Title)
+	if reminder.DueAt != nil {
+		body += fmt.Sprintf("Срок выполнения: %s.\n", reminder.DueAt.Format(time.RFC1123Z))
+	}
+	body += fmt.Sprintf("\n%s/notes/%d\n", n.appURL, reminder.NoteID)
+
+	return n.mail.Send(ctx, mailer.Message{
+		To:      reminder.Email,
+		Subject: "Напоминание: " + reminder.Title,
+		Body:    body,
+	})
+}
@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"note_app/internal/models"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogNotifier записывает напоминания в файл или в стандартный лог.
+type LogNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewLogNotifier создает новый экземпляр LogNotifier. Если path пуст, напоминания пишутся в стандартный лог.
+func NewLogNotifier(path string) *LogNotifier {
+	return &LogNotifier{path: path}
+}
+
+// Notify записывает напоминание.
+func (n *LogNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	entry := fmt.Sprintf("Напоминание для %s (id %d): заметка %d «%s»", reminder.Username, reminder.UserID,
+		reminder.NoteID, reminder.Title)
+	if reminder.DueAt != nil {
+		entry += ", срок " + reminder.DueAt.Format(time.RFC3339)
+	}
+
+	if n.path == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("ошибка при открытии файла напоминаний: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(time.Now().Format(time.RFC3339) + " " + entry + "\n"); err != nil {
+		return fmt.Errorf("ошибка при записи напоминания: %v", err)
+	}
+	return nil
+}
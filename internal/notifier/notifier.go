@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"note_app/internal/config"
+	"note_app/internal/mailer"
+	"note_app/internal/models"
+)
+
+// Notifier интерфейс для отправки напоминаний о заметках.
+type Notifier interface {
+	Notify(ctx context.Context, reminder models.Reminder) error
+}
+
+// New создает Notifier в соответствии с конфигурацией. Если способов отправки несколько,
+// напоминание отправляется каждым из них. Без настроенных способов напоминания пишутся в лог.
+func New(cfg config.RemindersConfig, mail mailer.Mailer, appURL string) (Notifier, error) {
+	var notifiers multiNotifier
+	for _, name := range cfg.Notifiers {
+		switch name {
+		case "log":
+			notifiers = append(notifiers, NewLogNotifier(cfg.LogFile))
+		case "email":
+			notifiers = append(notifiers, NewEmailNotifier(mail, appURL))
+		case "webhook":
+			webhook, err := NewWebhookNotifier(cfg.Webhook)
+			if err != nil {
+				return nil, err
+			}
+			notifiers = append(notifiers, webhook)
+		default:
+			return nil, fmt.Errorf("неизвестный способ отправки напоминаний: %s", name)
+		}
+	}
+
+	switch len(notifiers) {
+	case 0:
+		return NewLogNotifier(cfg.LogFile), nil
+	case 1:
+		return notifiers[0], nil
+	}
+	return notifiers, nil
+}
+
+// multiNotifier отправляет напоминание всеми способами. Напоминание считается отправленным,
+// если хотя бы один способ отработал без ошибки: иначе повторная отправка продублирует его остальным.
+type multiNotifier []Notifier
+
+// Notify отправляет напоминание всеми способами.
+func (m multiNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, reminder); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == len(m) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"time"
+)
+
+// defaultWebhookTimeout время ожидания ответа, если оно не задано в конфигурации.
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookNotifier отправляет напоминания в формате JSON POST-запросом на заданный адрес.
+type WebhookNotifier struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+// NewWebhookNotifier создает новый экземпляр WebhookNotifier.
+func NewWebhookNotifier(cfg config.WebhookNotifierConfig) (*WebhookNotifier, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("не указан адрес для отправки напоминаний")
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookNotifier{url: cfg.URL, secret: cfg.Secret, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Notify отправляет напоминание. Если задан секрет, тело подписывается HMAC-SHA256,
+// подпись передается в заголовке X-Signature-256 в виде sha256=<hex>.
+func (n *WebhookNotifier) Notify(ctx context.Context, reminder models.Reminder) error {
+	body, err := json.Marshal(map[string]interface{}{"event": "note.reminder", "reminder": reminder})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		mac := hmac.New(sha256.New, []byte(n.secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("не удалось отправить напоминание: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("не удалось отправить напоминание: %s", resp.Status)
+	}
+	return nil
+}
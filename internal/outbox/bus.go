@@ -0,0 +1,103 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/lib/pq"
+	"log"
+	"note_app/internal/events"
+	"note_app/internal/models"
+	"time"
+)
+
+const (
+	// busChannel канал PostgreSQL NOTIFY, через который события передаются в шины всех экземпляров приложения.
+	busChannel = "note_events"
+	// maxNotifyPayload максимальный размер сообщения NOTIFY в байтах (ограничение PostgreSQL — 8000 байт).
+	maxNotifyPayload = 7999
+	// busListenerPing период проверки соединения, на котором ожидаются сообщения NOTIFY.
+	busListenerPing = 90 * time.Second
+)
+
+// BusSink передает события в шины событий всех экземпляров приложения, из которых их получают потоки
+// GET /events и GET /events/ws. Исходящие события пересылает один экземпляр, поэтому событие рассылается
+// сообщением PostgreSQL NOTIFY, а каждый экземпляр принимает его через BusListener.
+type BusSink struct {
+	db *sql.DB
+}
+
+// NewBusSink создает новый экземпляр BusSink.
+func NewBusSink(db *sql.DB) *BusSink {
+	return &BusSink{db: db}
+}
+
+// Publish рассылает событие экземплярам приложения. Если событие не помещается в сообщение NOTIFY, оно
+// рассылается без краткого содержания заметки.
+func (s *BusSink) Publish(ctx context.Context, event models.NoteEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		event.Note = nil
+		if payload, err = json.Marshal(event); err != nil {
+			return err
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, busChannel, string(payload)); err != nil {
+		return fmt.Errorf("не удалось разослать событие экземплярам приложения: %v", err)
+	}
+	return nil
+}
+
+// BusListener принимает события, разосланные BusSink, и передает их в шину событий процесса.
+type BusListener struct {
+	listener *pq.Listener
+	bus      *events.Bus
+}
+
+// NewBusListener создает новый экземпляр BusListener, который подключается к базе данных по строке
+// подключения dsn.
+func NewBusListener(dsn string, bus *events.Bus) (*BusListener, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("Ошибка соединения для получения событий заметок: %v", err)
+		}
+	})
+	if err := listener.Listen(busChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("не удалось подписаться на события заметок: %v", err)
+	}
+	return &BusListener{listener: listener, bus: bus}, nil
+}
+
+// Run передает события в шину до отмены ctx. Сообщения, разосланные, пока соединение было потеряно,
+// не доставляются, поэтому после переподключения подписчики получают событие resync.
+func (l *BusListener) Run(ctx context.Context) {
+	defer l.listener.Close()
+
+	ticker := time.NewTicker(busListenerPing)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			go l.listener.Ping()
+		case notification := <-l.listener.Notify:
+			// nil передается после восстановления соединения
+			if notification == nil {
+				l.bus.Resync()
+				continue
+			}
+			var event models.NoteEvent
+			if err := json.Unmarshal([]byte(notification.Extra), &event); err != nil {
+				log.Printf("Не удалось прочитать событие заметки: %v", err)
+				continue
+			}
+			l.bus.Publish(event)
+		}
+	}
+}
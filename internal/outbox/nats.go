@@ -0,0 +1,185 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"note_app/internal/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultNATSSubject префикс темы, если он не задан в конфигурации.
+const defaultNATSSubject = "notes"
+
+// NATSPublisher публикует сообщение в тему NATS. Метод совпадает с Publish из github.com/nats-io/nats.go,
+// поэтому вместо NATSConn можно передать *nats.Conn.
+type NATSPublisher interface {
+	Publish(subject string, data []byte) error
+}
+
+// NATSSink публикует события в NATS в темы <subject>.created, <subject>.updated и <subject>.deleted.
+type NATSSink struct {
+	publisher NATSPublisher
+	subject   string
+}
+
+// NewNATSSink создает новый экземпляр NATSSink.
+func NewNATSSink(publisher NATSPublisher, subject string) *NATSSink {
+	if subject == "" {
+		subject = defaultNATSSubject
+	}
+	return &NATSSink{publisher: publisher, subject: subject}
+}
+
+// Publish публикует событие в формате JSON, как в GET /events.
+func (s *NATSSink) Publish(ctx context.Context, event models.NoteEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.publisher.Publish(s.subject+"."+strings.TrimPrefix(event.Type, "note."), data)
+}
+
+// NATSConn минимальный клиент NATS для публикации сообщений по текстовому протоколу NATS без TLS.
+// Подключается при первой публикации и после каждой публикации дожидается ответа сервера на PING,
+// поэтому Publish возвращает ошибку, если сервер не принял сообщение. Подписки не поддерживаются.
+type NATSConn struct {
+	mu       sync.Mutex
+	addr     string
+	user     string
+	password string
+	timeout  time.Duration
+	conn     net.Conn
+	reader   *bufio.Reader
+}
+
+// NewNATSConn создает клиент для сервера rawURL вида nats://[user:password@]host[:port].
+func NewNATSConn(rawURL string, timeout time.Duration) (*NATSConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("неверный адрес сервера NATS: %q", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	c := &NATSConn{addr: addr, timeout: timeout}
+	if u.User != nil {
+		c.user = u.User.Username()
+		c.password, _ = u.User.Password()
+	}
+	return c, nil
+}
+
+// Publish публикует сообщение в тему subject. Если соединение было разорвано, клиент подключается заново
+// и повторяет публикацию один раз.
+func (c *NATSConn) Publish(subject string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if c.conn == nil {
+			if err = c.connect(); err != nil {
+				return err
+			}
+		}
+		if err = c.publish(subject, data); err == nil {
+			return nil
+		}
+		c.close()
+	}
+	return fmt.Errorf("не удалось опубликовать событие в NATS: %v", err)
+}
+
+// Close закрывает соединение с сервером.
+func (c *NATSConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.close()
+}
+
+// connect подключается к серверу: читает INFO, отправляет CONNECT и дожидается ответа на PING.
+func (c *NATSConn) connect() error {
+	conn, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return fmt.Errorf("не удалось подключиться к NATS: %v", err)
+	}
+	c.conn, c.reader = conn, bufio.NewReader(conn)
+	_ = conn.SetDeadline(time.Now().Add(c.timeout))
+
+	line, err := c.reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		c.close()
+		return errors.New("не удалось подключиться к NATS: сервер не прислал INFO")
+	}
+
+	options := map[string]interface{}{"verbose": false, "pedantic": false, "name": "note_app", "lang": "go"}
+	if c.user != "" {
+		options["user"], options["pass"] = c.user, c.password
+	}
+	connect, err := json.Marshal(options)
+	if err != nil {
+		c.close()
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		c.close()
+		return fmt.Errorf("не удалось подключиться к NATS: %v", err)
+	}
+	if err := c.waitPong(); err != nil {
+		c.close()
+		return fmt.Errorf("не удалось подключиться к NATS: %v", err)
+	}
+	return nil
+}
+
+// publish отправляет PUB и PING и дожидается PONG.
+func (c *NATSConn) publish(subject string, data []byte) error {
+	_ = c.conn.SetDeadline(time.Now().Add(c.timeout))
+	message := make([]byte, 0, len(subject)+len(data)+32)
+	message = fmt.Appendf(message, "PUB %s %d\r\n", subject, len(data))
+	message = append(message, data...)
+	message = append(message, "\r\nPING\r\n"...)
+	if _, err := c.conn.Write(message); err != nil {
+		return err
+	}
+	return c.waitPong()
+}
+
+// waitPong читает ответы сервера до PONG, отвечая на его PING. Ответ -ERR возвращается как ошибка.
+func (c *NATSConn) waitPong() error {
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := c.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// close закрывает соединение, если оно открыто.
+func (c *NATSConn) close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.reader = nil, nil
+	return err
+}
@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"time"
+)
+
+// defaultNATSTimeout время ожидания сервера NATS, если оно не задано в конфигурации.
+const defaultNATSTimeout = 5 * time.Second
+
+// Sink получатель событий заметок, пересылаемых из таблицы исходящих событий. Событие может быть передано
+// получателю повторно, например после перезапуска приложения; повтор распознается по идентификатору события.
+type Sink interface {
+	Publish(ctx context.Context, event models.NoteEvent) error
+}
+
+// New создает получателей событий в соответствии с конфигурацией. Без настроенных получателей события
+// передаются в потоки GET /events и в очередь веб-хуков.
+func New(cfg config.OutboxConfig, db *sql.DB, webhooks repository.WebhookRepository) ([]Sink, error) {
+	names := cfg.Sinks
+	if len(names) == 0 {
+		names = []string{"bus", "webhooks"}
+	}
+
+	var sinks []Sink
+	for _, name := range names {
+		switch name {
+		case "bus":
+			sinks = append(sinks, NewBusSink(db))
+		case "webhooks":
+			sinks = append(sinks, NewWebhookSink(webhooks))
+		case "nats":
+			timeout := time.Duration(cfg.NATS.Timeout) * time.Second
+			if timeout <= 0 {
+				timeout = defaultNATSTimeout
+			}
+			conn, err := NewNATSConn(cfg.NATS.URL, timeout)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, NewNATSSink(conn, cfg.NATS.Subject))
+		default:
+			return nil, fmt.Errorf("неизвестный получатель событий заметок: %s", name)
+		}
+	}
+	return sinks, nil
+}
@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+)
+
+// WebhookSink ставит события в очередь доставки веб-хуков, фильтры которых им соответствуют.
+type WebhookSink struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookSink создает новый экземпляр WebhookSink.
+func NewWebhookSink(repo repository.WebhookRepository) *WebhookSink {
+	return &WebhookSink{repo: repo}
+}
+
+// Publish ставит событие в очередь доставки.
+func (s *WebhookSink) Publish(ctx context.Context, event models.NoteEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = s.repo.EnqueueEvent(ctx, event, payload)
+	return err
+}
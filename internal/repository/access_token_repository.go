@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+)
+
+// AccessTokenRepository интерфейс для работы с персональными токенами доступа.
+type AccessTokenRepository interface {
+	CreateAccessToken(ctx context.Context, token *models.AccessToken) error
+	GetAccessTokensByUserID(ctx context.Context, userID int) ([]models.AccessToken, error)
+	GetActiveAccessTokenByHash(ctx context.Context, tokenHash string) (*models.AccessToken, error)
+	RevokeAccessToken(ctx context.Context, userID, tokenID int) error
+	TouchAccessToken(ctx context.Context, tokenID int) error
+}
+
+// accessTokenRepository реализация интерфейса AccessTokenRepository.
+type accessTokenRepository struct {
+	db *sql.DB
+}
+
+// NewAccessTokenRepository создает новый экземпляр AccessTokenRepository.
+func NewAccessTokenRepository(db *sql.DB) AccessTokenRepository {
+	return &accessTokenRepository{db: db}
+}
+
+// CreateAccessToken сохраняет новый токен.
+func (ar *accessTokenRepository) CreateAccessToken(ctx context.Context, token *models.AccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := ar.db.QueryRowContext(ctx, query, token.UserID, token.Name, token.TokenHash, pq.Array(token.Scopes), token.ExpiresAt).
+		Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить токен доступа: %v", err)
+	}
+	return nil
+}
+
+// GetAccessTokensByUserID возвращает неотозванные токены пользователя.
+func (ar *accessTokenRepository) GetAccessTokensByUserID(ctx context.Context, userID int) ([]models.AccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+	rows, err := ar.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить токены доступа: %v", err)
+	}
+	defer rows.Close()
+
+	tokens := []models.AccessToken{}
+	for rows.Next() {
+		var token models.AccessToken
+		err := rows.Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, pq.Array(&token.Scopes),
+			&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// GetActiveAccessTokenByHash возвращает неотозванный и непросроченный токен по его хэшу.
+func (ar *accessTokenRepository) GetActiveAccessTokenByHash(ctx context.Context, tokenHash string) (*models.AccessToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL
+			AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+	`
+	var token models.AccessToken
+	err := ar.db.QueryRowContext(ctx, query, tokenHash).
+		Scan(&token.ID, &token.UserID, &token.Name, &token.TokenHash, pq.Array(&token.Scopes),
+			&token.ExpiresAt, &token.LastUsedAt, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeAccessToken отзывает токен пользователя.
+func (ar *accessTokenRepository) RevokeAccessToken(ctx context.Context, userID, tokenID int) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	result, err := ar.db.ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось отозвать токен доступа: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TouchAccessToken обновляет время последнего использования токена.
+// Чтобы не писать в базу на каждый запрос, время обновляется не чаще раза в минуту.
+func (ar *accessTokenRepository) TouchAccessToken(ctx context.Context, tokenID int) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < CURRENT_TIMESTAMP - INTERVAL '1 minute')
+	`
+	_, err := ar.db.ExecContext(ctx, query, tokenID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить время использования токена: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// AttachmentRepository интерфейс для работы с вложениями заметок.
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, attachment *models.Attachment) error
+	GetAttachment(ctx context.Context, noteID, attachmentID int) (*models.Attachment, error)
+	GetAttachmentsByNoteID(ctx context.Context, noteID int) ([]models.Attachment, error)
+	DeleteAttachment(ctx context.Context, noteID, attachmentID int) error
+}
+
+// attachmentRepository реализация интерфейса AttachmentRepository.
+type attachmentRepository struct {
+	db *sql.DB
+}
+
+// NewAttachmentRepository создает новый экземпляр AttachmentRepository.
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+// attachmentColumns список столбцов, из которых собирается models.Attachment.
+const attachmentColumns = `id, note_id, user_id, filename, content_type, size, storage_key, created_at`
+
+// scanAttachment считывает вложение из строки результата запроса.
+func scanAttachment(row rowScanner) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := row.Scan(&attachment.ID, &attachment.NoteID, &attachment.UserID, &attachment.Filename,
+		&attachment.ContentType, &attachment.Size, &attachment.StorageKey, &attachment.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// CreateAttachment сохраняет сведения о вложении.
+func (ar *attachmentRepository) CreateAttachment(ctx context.Context, attachment *models.Attachment) error {
+	query := `
+		INSERT INTO attachments (note_id, user_id, filename, content_type, size, storage_key)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := ar.db.QueryRowContext(ctx, query, attachment.NoteID, attachment.UserID, attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.StorageKey).
+		Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить вложение: %v", err)
+	}
+	return nil
+}
+
+// GetAttachment возвращает вложение заметки.
+func (ar *attachmentRepository) GetAttachment(ctx context.Context, noteID, attachmentID int) (*models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1 AND note_id = $2`
+	return scanAttachment(ar.db.QueryRowContext(ctx, query, attachmentID, noteID))
+}
+
+// GetAttachmentsByNoteID возвращает вложения заметки в порядке добавления.
+func (ar *attachmentRepository) GetAttachmentsByNoteID(ctx context.Context, noteID int) ([]models.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE note_id = $1 ORDER BY id`
+	rows, err := ar.db.QueryContext(ctx, query, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить вложения: %v", err)
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *attachment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// DeleteAttachment удаляет сведения о вложении.
+func (ar *attachmentRepository) DeleteAttachment(ctx context.Context, noteID, attachmentID int) error {
+	result, err := ar.db.ExecContext(ctx, `DELETE FROM attachments WHERE id = $1 AND note_id = $2`, attachmentID, noteID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить вложение: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+)
+
+// BulkRepository интерфейс для выполнения операций над несколькими заметками.
+type BulkRepository interface {
+	ApplyBulk(ctx context.Context, userID int, ops []models.BulkOperation, check BulkCheck) (bool, error)
+}
+
+// BulkCheck проверяет операции по затронутым заметкам (идентификатор заметки — заметка с заполненными ID, UserID
+// и Visibility; несуществующих заметок в notes нет) и возвращает, можно ли их выполнить.
+type BulkCheck func(notes map[int]models.Note) bool
+
+// bulkRepository реализация интерфейса BulkRepository.
+type bulkRepository struct {
+	db *sql.DB
+}
+
+// NewBulkRepository создает новый экземпляр BulkRepository.
+func NewBulkRepository(db *sql.DB) BulkRepository {
+	return &bulkRepository{db: db}
+}
+
+// ApplyBulk выполняет операции пользователя userID по порядку в одной транзакции и записывает события изменения
+// и удаления заметок. Затронутые заметки блокируются до проверки check, поэтому их владельцы и видимость не могут
+// измениться до конца транзакции. Если check возвращает false, ничего не изменяется.
+func (br *bulkRepository) ApplyBulk(ctx context.Context, userID int, ops []models.BulkOperation, check BulkCheck) (bool, error) {
+	tx, err := br.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer tx.Rollback()
+
+	var noteIDs []int
+	for _, op := range ops {
+		noteIDs = append(noteIDs, op.IDs...)
+	}
+	// Строки блокируются в порядке идентификаторов, чтобы одновременные запросы не взаимоблокировались
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, user_id, visibility FROM notes WHERE id = ANY($1) ORDER BY id FOR UPDATE`, pq.Array(noteIDs))
+	if err != nil {
+		return false, fmt.Errorf("не удалось получить заметки: %v", err)
+	}
+	notes := make(map[int]models.Note)
+	for rows.Next() {
+		var note models.Note
+		if err := rows.Scan(&note.ID, &note.UserID, &note.Visibility); err != nil {
+			rows.Close()
+			return false, err
+		}
+		notes[note.ID] = note
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	if !check(notes) {
+		return false, nil
+	}
+
+	for _, op := range ops {
+		for _, noteID := range op.IDs {
+			if err := applyBulkOperation(ctx, tx, userID, op, notes[noteID]); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("не удалось выполнить операции над заметками: %v", err)
+	}
+	return true, nil
+}
+
+// applyBulkOperation выполняет операцию op пользователя userID над заметкой note в транзакции tx.
+func applyBulkOperation(ctx context.Context, tx *sql.Tx, userID int, op models.BulkOperation, note models.Note) error {
+	switch op.Op {
+	case models.BulkOpArchive, models.BulkOpUnarchive:
+		return setNoteFlag(ctx, tx, note.ID, userID, models.NoteFlagArchived, op.Op == models.BulkOpArchive)
+	case models.BulkOpDelete:
+		if _, err := tx.ExecContext(ctx, `DELETE FROM notes WHERE id = $1`, note.ID); err != nil {
+			return fmt.Errorf("не удалось удалить заметки: %v", err)
+		}
+		return insertNoteEvent(ctx, tx, models.EventNoteDeleted, &note)
+	case models.BulkOpTag:
+		// Метки добавляются к имеющимся без повторов
+		return updateBulkNote(ctx, tx, note.ID,
+			`tags = ARRAY(SELECT DISTINCT tag FROM unnest(tags || $2::text[]) AS tag ORDER BY tag)`, pq.Array(op.Tags))
+	case models.BulkOpMove:
+		return updateBulkNote(ctx, tx, note.ID, `folder = $2`, *op.Folder)
+	case models.BulkOpVisibility:
+		return updateBulkNote(ctx, tx, note.ID, `visibility = $2`, op.Visibility)
+	default:
+		return fmt.Errorf("неизвестная операция над заметками: %s", op.Op)
+	}
+}
+
+// updateBulkNote изменяет заметку noteID выражением set с параметром $2 = value и записывает событие ее изменения.
+func updateBulkNote(ctx context.Context, tx *sql.Tx, noteID int, set string, value interface{}) error {
+	updated := models.Note{ID: noteID}
+	err := tx.QueryRowContext(ctx, `
+		UPDATE notes SET `+set+`
+		WHERE id = $1
+		RETURNING user_id, title, text, format, created_at, author, due_at, visibility
+	`, noteID, value).Scan(&updated.UserID, &updated.Title, &updated.Text, &updated.Format, &updated.CreatedAt,
+		&updated.Author, &updated.DueAt, &updated.Visibility)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить заметки: %v", err)
+	}
+	return insertNoteEvent(ctx, tx, models.EventNoteUpdated, &updated)
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+)
+
+// ChecklistRepository интерфейс для работы с пунктами заметок-списков задач.
+type ChecklistRepository interface {
+	GetChecklistItems(ctx context.Context, noteID int) ([]models.ChecklistItem, error)
+	UpdateChecklist(ctx context.Context, noteID int, update ChecklistUpdate) ([]models.ChecklistItem, error)
+}
+
+// ChecklistUpdate изменяет пункты списка задач заметки note и возвращает новые пункты и текст заметки.
+// Пункты без идентификатора добавляются, пункты, которых нет в результате, удаляются.
+type ChecklistUpdate func(note *models.Note, items []models.ChecklistItem) ([]models.ChecklistItem, string, error)
+
+// checklistRepository реализация интерфейса ChecklistRepository.
+type checklistRepository struct {
+	db *sql.DB
+}
+
+// NewChecklistRepository создает новый экземпляр ChecklistRepository.
+func NewChecklistRepository(db *sql.DB) ChecklistRepository {
+	return &checklistRepository{db: db}
+}
+
+// queryer общий интерфейс *sql.DB и *sql.Tx для чтения.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// GetChecklistItems возвращает пункты списка задач заметки по порядку.
+func (cr *checklistRepository) GetChecklistItems(ctx context.Context, noteID int) ([]models.ChecklistItem, error) {
+	return getChecklistItems(ctx, cr.db, noteID)
+}
+
+// UpdateChecklist изменяет пункты списка задач и текст заметки в одной транзакции и записывает событие изменения
+// заметки. Строка заметки блокируется, поэтому одновременные изменения одного списка выполняются по очереди и не теряются.
+func (cr *checklistRepository) UpdateChecklist(ctx context.Context, noteID int, update ChecklistUpdate) ([]models.ChecklistItem, error) {
+	tx, err := cr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer tx.Rollback()
+
+	note := models.Note{ID: noteID}
+	err = tx.QueryRowContext(ctx,
+		`SELECT user_id, title, format, created_at, due_at, author, visibility FROM notes WHERE id = $1 FOR UPDATE`, noteID).
+		Scan(&note.UserID, &note.Title, &note.Format, &note.CreatedAt, &note.DueAt, &note.Author, &note.Visibility)
+	if err != nil {
+		return nil, err
+	}
+	items, err := getChecklistItems(ctx, tx, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, text, err := update(&note, items)
+	if err != nil {
+		return nil, err
+	}
+
+	keep := make([]int, 0, len(items))
+	for _, item := range items {
+		if item.ID != 0 {
+			keep = append(keep, item.ID)
+		}
+	}
+	_, err = tx.ExecContext(ctx, `DELETE FROM note_checklist_items WHERE note_id = $1 AND NOT (id = ANY($2))`, noteID, pq.Array(keep))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось удалить пункты списка: %v", err)
+	}
+	for i := range items {
+		if items[i].ID == 0 {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO note_checklist_items (note_id, position, text, checked)
+				VALUES ($1, $2, $3, $4)
+				RETURNING id
+			`, noteID, items[i].Position, items[i].Text, items[i].Checked).Scan(&items[i].ID)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+				UPDATE note_checklist_items SET position = $1, text = $2, checked = $3
+				WHERE id = $4 AND note_id = $5
+			`, items[i].Position, items[i].Text, items[i].Checked, items[i].ID, noteID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("не удалось сохранить пункт списка: %v", err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE notes SET text = $1 WHERE id = $2`, text, noteID); err != nil {
+		return nil, fmt.Errorf("не удалось обновить текст заметки: %v", err)
+	}
+	if err := syncNoteLinks(ctx, tx, noteID, note.UserID, note.Title, text); err != nil {
+		return nil, err
+	}
+	note.Text = text
+	if err := insertNoteEvent(ctx, tx, models.EventNoteUpdated, &note); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить список: %v", err)
+	}
+	return items, nil
+}
+
+// getChecklistItems возвращает пункты списка задач заметки по порядку.
+func getChecklistItems(ctx context.Context, q queryer, noteID int) ([]models.ChecklistItem, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, position, text, checked
+		FROM note_checklist_items
+		WHERE note_id = $1
+		ORDER BY position, id
+	`, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить пункты списка: %v", err)
+	}
+	defer rows.Close()
+
+	items := []models.ChecklistItem{}
+	for rows.Next() {
+		var item models.ChecklistItem
+		if err := rows.Scan(&item.ID, &item.Position, &item.Text, &item.Checked); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// insertChecklistItems добавляет пункты списка задач к заметке.
+func insertChecklistItems(ctx context.Context, tx *sql.Tx, noteID int, items []models.ChecklistItem) error {
+	for i := range items {
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO note_checklist_items (note_id, position, text, checked)
+			VALUES ($1, $2, $3, $4)
+			RETURNING id
+		`, noteID, items[i].Position, items[i].Text, items[i].Checked).Scan(&items[i].ID)
+		if err != nil {
+			return fmt.Errorf("не удалось добавить пункт списка: %v", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// CommentRepository интерфейс для работы с комментариями к заметкам.
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	GetComment(ctx context.Context, noteID, commentID int) (*models.Comment, error)
+	GetCommentsByNoteID(ctx context.Context, noteID, offset, limit int) ([]models.Comment, error)
+	UpdateComment(ctx context.Context, commentID int, text string) error
+	DeleteComment(ctx context.Context, commentID int) error
+}
+
+// commentRepository реализация интерфейса CommentRepository.
+type commentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository создает новый экземпляр CommentRepository.
+func NewCommentRepository(db *sql.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+// commentColumns список столбцов, из которых собирается models.Comment.
+const commentColumns = `note_comments.id, note_comments.note_id, note_comments.user_id, users.username,
+	note_comments.text, note_comments.created_at, note_comments.updated_at`
+
+// scanComment считывает комментарий из строки результата запроса.
+func scanComment(row rowScanner) (*models.Comment, error) {
+	var comment models.Comment
+	var updatedAt sql.NullTime
+	err := row.Scan(&comment.ID, &comment.NoteID, &comment.UserID, &comment.Author,
+		&comment.Text, &comment.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if updatedAt.Valid {
+		comment.UpdatedAt = &updatedAt.Time
+	}
+	return &comment, nil
+}
+
+// CreateComment сохраняет комментарий.
+func (cr *commentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	query := `
+		INSERT INTO note_comments (note_id, user_id, text)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := cr.db.QueryRowContext(ctx, query, comment.NoteID, comment.UserID, comment.Text).
+		Scan(&comment.ID, &comment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить комментарий: %v", err)
+	}
+	return nil
+}
+
+// GetComment возвращает комментарий к заметке.
+func (cr *commentRepository) GetComment(ctx context.Context, noteID, commentID int) (*models.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM note_comments
+		INNER JOIN users ON note_comments.user_id = users.id
+		WHERE note_comments.id = $1 AND note_comments.note_id = $2
+	`
+	return scanComment(cr.db.QueryRowContext(ctx, query, commentID, noteID))
+}
+
+// GetCommentsByNoteID возвращает комментарии к заметке в порядке добавления.
+func (cr *commentRepository) GetCommentsByNoteID(ctx context.Context, noteID, offset, limit int) ([]models.Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM note_comments
+		INNER JOIN users ON note_comments.user_id = users.id
+		WHERE note_comments.note_id = $1
+		ORDER BY note_comments.created_at, note_comments.id
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := cr.db.QueryContext(ctx, query, noteID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить комментарии: %v", err)
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// UpdateComment изменяет текст комментария и отмечает время изменения.
+func (cr *commentRepository) UpdateComment(ctx context.Context, commentID int, text string) error {
+	result, err := cr.db.ExecContext(ctx,
+		`UPDATE note_comments SET text = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2`, text, commentID)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить комментарий: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteComment удаляет комментарий.
+func (cr *commentRepository) DeleteComment(ctx context.Context, commentID int) error {
+	result, err := cr.db.ExecContext(ctx, `DELETE FROM note_comments WHERE id = $1`, commentID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить комментарий: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
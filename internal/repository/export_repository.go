@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+)
+
+// ExportRepository интерфейс для чтения заметок пользователя при выгрузке.
+type ExportRepository interface {
+	ForEachNote(ctx context.Context, userID int, fn func(note *models.ExportedNote) error) error
+}
+
+// exportRepository реализация интерфейса ExportRepository.
+type exportRepository struct {
+	db *sql.DB
+}
+
+// NewExportRepository создает новый экземпляр ExportRepository.
+func NewExportRepository(db *sql.DB) ExportRepository {
+	return &exportRepository{db: db}
+}
+
+// ForEachNote вызывает fn для каждой заметки пользователя в порядке создания. Заметки читаются из результата
+// запроса по одной, пункты списков задач собираются в JSON тем же запросом. Ошибка fn прерывает обход.
+func (er *exportRepository) ForEachNote(ctx context.Context, userID int, fn func(note *models.ExportedNote) error) error {
+	query := `
+		SELECT notes.id, notes.title, notes.text, notes.format, notes.created_at, notes.due_at, notes.remind_at,
+			COALESCE(flags.pinned, FALSE), COALESCE(flags.favorite, FALSE), COALESCE(flags.archived, FALSE),
+			notes.tags, notes.folder, notes.visibility,
+			(SELECT json_agg(json_build_object(
+					'id', items.id, 'position', items.position, 'text', items.text, 'checked', items.checked
+				) ORDER BY items.position, items.id)
+			FROM note_checklist_items items WHERE items.note_id = notes.id)
+		FROM notes
+		LEFT JOIN note_user_flags flags ON flags.note_id = notes.id AND flags.user_id = notes.user_id
+		WHERE notes.user_id = $1
+		ORDER BY notes.created_at, notes.id
+	`
+	rows, err := er.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось получить заметки для выгрузки: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var note models.ExportedNote
+		var items []byte
+		err := rows.Scan(&note.ID, &note.Title, &note.Text, &note.Format, &note.CreatedAt, &note.DueAt, &note.RemindAt,
+			&note.Pinned, &note.Favorite, &note.Archived, pq.Array(&note.Tags), &note.Folder, &note.Visibility, &items)
+		if err != nil {
+			return err
+		}
+		if items != nil {
+			if err := json.Unmarshal(items, &note.Items); err != nil {
+				return fmt.Errorf("не удалось прочитать пункты списка заметки %d: %v", note.ID, err)
+			}
+		}
+		if err := fn(&note); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
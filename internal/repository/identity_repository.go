@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// IdentityRepository интерфейс для работы с внешними учетными записями пользователей.
+type IdentityRepository interface {
+	GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+	CreateIdentity(ctx context.Context, identity *models.UserIdentity) error
+}
+
+// identityRepository реализация интерфейса IdentityRepository.
+type identityRepository struct {
+	db *sql.DB
+}
+
+// NewIdentityRepository создает новый экземпляр IdentityRepository.
+func NewIdentityRepository(db *sql.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+// GetIdentity возвращает внешнюю учетную запись по издателю и идентификатору субъекта.
+// Возвращает sql.ErrNoRows, если учетная запись не привязана.
+func (ir *identityRepository) GetIdentity(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	query := `
+		SELECT id, user_id, issuer, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE issuer = $1 AND subject = $2
+	`
+	var identity models.UserIdentity
+	err := ir.db.QueryRowContext(ctx, query, issuer, subject).
+		Scan(&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject, &identity.Email, &identity.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateIdentity привязывает внешнюю учетную запись к пользователю.
+func (ir *identityRepository) CreateIdentity(ctx context.Context, identity *models.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, issuer, subject, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at
+	`
+	err := ir.db.QueryRowContext(ctx, query, identity.UserID, identity.Issuer, identity.Subject, identity.Email).
+		Scan(&identity.ID, &identity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось привязать внешнюю учетную запись: %v", err)
+	}
+	return nil
+}
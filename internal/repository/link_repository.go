@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+	"note_app/pkg/utils"
+)
+
+// LinkRepository интерфейс для чтения ссылок между заметками. Ссылки сохраняются вместе с заметкой.
+type LinkRepository interface {
+	GetLinks(ctx context.Context, noteID, viewerID int) ([]models.NoteLink, error)
+	GetBacklinks(ctx context.Context, noteID, viewerID, offset, limit int) ([]models.Backlink, error)
+}
+
+// linkRepository реализация интерфейса LinkRepository.
+type linkRepository struct {
+	db *sql.DB
+}
+
+// NewLinkRepository создает новый экземпляр LinkRepository.
+func NewLinkRepository(db *sql.DB) LinkRepository {
+	return &linkRepository{db: db}
+}
+
+// GetLinks возвращает исходящие ссылки заметки вместе с текущими заголовками заметок, на которые они указывают.
+// Ссылки на заметки, которые не видны пользователю viewerID, возвращаются как битые.
+func (lr *linkRepository) GetLinks(ctx context.Context, noteID, viewerID int) ([]models.NoteLink, error) {
+	query := `
+		SELECT note_links.link_text, notes.id, COALESCE(notes.title, '')
+		FROM note_links
+		LEFT JOIN notes ON notes.id = note_links.target_note_id AND ` + noteVisible + `
+		WHERE note_links.source_note_id = $2
+		ORDER BY note_links.link_text
+	`
+	rows, err := lr.db.QueryContext(ctx, query, viewerID, noteID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить ссылки заметки: %v", err)
+	}
+	defer rows.Close()
+
+	links := []models.NoteLink{}
+	for rows.Next() {
+		var link models.NoteLink
+		var targetID sql.NullInt64
+		if err := rows.Scan(&link.Text, &targetID, &link.TargetTitle); err != nil {
+			return nil, err
+		}
+		if targetID.Valid {
+			id := int(targetID.Int64)
+			link.TargetID = &id
+		} else {
+			link.Broken = true
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// GetBacklinks возвращает видимые пользователю viewerID заметки, ссылающиеся на заметку, сначала новые.
+func (lr *linkRepository) GetBacklinks(ctx context.Context, noteID, viewerID, offset, limit int) ([]models.Backlink, error) {
+	query := `
+		SELECT notes.id, notes.title, users.username, notes.created_at, note_links.link_text
+		FROM note_links
+		INNER JOIN notes ON notes.id = note_links.source_note_id
+		INNER JOIN users ON users.id = notes.user_id
+		WHERE note_links.target_note_id = $2 AND ` + noteVisible + `
+		ORDER BY notes.created_at DESC, notes.id DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := lr.db.QueryContext(ctx, query, viewerID, noteID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить обратные ссылки: %v", err)
+	}
+	defer rows.Close()
+
+	backlinks := []models.Backlink{}
+	for rows.Next() {
+		var backlink models.Backlink
+		if err := rows.Scan(&backlink.NoteID, &backlink.Title, &backlink.Author, &backlink.CreatedAt, &backlink.Text); err != nil {
+			return nil, err
+		}
+		backlinks = append(backlinks, backlink)
+	}
+	return backlinks, rows.Err()
+}
+
+// syncNoteLinks сохраняет ссылки из текста text заметки и разрешает битые ссылки на ее заголовок. Ссылка [[#id]]
+// указывает на заметку с этим идентификатором, ссылка [[заголовок]] — на самую новую заметку автора с таким
+// заголовком без учета регистра. Ссылка, которая уже указывала на заметку, сохраняет цель и после
+// переименования этой заметки.
+func syncNoteLinks(ctx context.Context, tx *sql.Tx, noteID, userID int, title, text string) error {
+	previous := make(map[string]int)
+	rows, err := tx.QueryContext(ctx,
+		`SELECT link_text, target_note_id FROM note_links WHERE source_note_id = $1 AND target_note_id IS NOT NULL`, noteID)
+	if err != nil {
+		return fmt.Errorf("не удалось получить ссылки заметки: %v", err)
+	}
+	for rows.Next() {
+		var text string
+		var targetID int
+		if err := rows.Scan(&text, &targetID); err != nil {
+			rows.Close()
+			return err
+		}
+		previous[text] = targetID
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM note_links WHERE source_note_id = $1`, noteID); err != nil {
+		return fmt.Errorf("не удалось обновить ссылки заметки: %v", err)
+	}
+
+	for _, link := range utils.ParseNoteLinks(text) {
+		var target sql.NullInt64
+		switch {
+		case link.TargetID != 0:
+			err = tx.QueryRowContext(ctx, `SELECT id FROM notes WHERE id = $1`, link.TargetID).Scan(&target)
+		case previous[link.Text] != 0:
+			target = sql.NullInt64{Int64: int64(previous[link.Text]), Valid: true}
+		default:
+			err = tx.QueryRowContext(ctx, `
+				SELECT id FROM notes
+				WHERE user_id = $1 AND lower(title) = lower($2)
+				ORDER BY created_at DESC, id DESC
+				LIMIT 1
+			`, userID, link.Text).Scan(&target)
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("не удалось найти заметку по ссылке: %v", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO note_links (source_note_id, link_text, target_note_id) VALUES ($1, $2, $3)`,
+			noteID, link.Text, target)
+		if err != nil {
+			return fmt.Errorf("не удалось сохранить ссылку заметки: %v", err)
+		}
+	}
+
+	// Битые ссылки заметок того же автора на новый заголовок теперь указывают на эту заметку
+	_, err = tx.ExecContext(ctx, `
+		UPDATE note_links SET target_note_id = $1
+		FROM notes source
+		WHERE source.id = note_links.source_note_id AND source.user_id = $2
+			AND note_links.target_note_id IS NULL
+			AND note_links.link_text !~ '^#[0-9]+$'
+			AND lower(note_links.link_text) = lower($3)
+	`, noteID, userID, title)
+	if err != nil {
+		return fmt.Errorf("не удалось разрешить ссылки на заметку: %v", err)
+	}
+	return nil
+}
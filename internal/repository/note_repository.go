@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"github.com/lib/pq"
 	"log"
 	"note_app/internal/models"
 	"note_app/pkg/utils"
@@ -12,16 +13,68 @@ import (
 // NoteRepository интерфейс для работы с заметками в базе данных.
 type NoteRepository interface {
 	AddNote(ctx context.Context, note *models.Note) (int, error)
+	ImportNotes(ctx context.Context, notes []*models.Note) ([]int, error)
 	GetNoteByID(ctx context.Context, noteID int) (*models.Note, error)
 	UpdateNote(ctx context.Context, noteID int, note *models.Note) error
 	DeleteNote(ctx context.Context, noteID int) error
-	GetNotesByUserID(ctx context.Context, userID, offset, limit int) ([]models.Note, error)
-	GetNotesByDateRange(ctx context.Context, startDate, endDate string, offset, limit int) ([]models.Note, error)
-	GetNotesByDay(ctx context.Context, day string, offset, limit int) ([]models.Note, error)
-	GetNotes(ctx context.Context, offset, limit int) ([]models.Note, error)
-	GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, offset, limit int) ([]models.Note, error)
-	GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, offset, limit int) ([]models.Note, error)
-	GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, offset, limit int) ([]models.Note, error)
+	GetNotesByUserID(ctx context.Context, userID int, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByDateRange(ctx context.Context, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByDay(ctx context.Context, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetFavoriteNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNoteFlags(ctx context.Context, noteID, userID int) (models.NoteFlags, error)
+	SetNoteFlag(ctx context.Context, noteID, userID int, flag models.NoteFlag, value bool) error
+}
+
+// noteListColumns столбцы списков заметок в порядке, ожидаемом utils.ScanNotes. Количество комментариев
+// и пунктов списка задач считается подзапросами в том же запросе по индексам, отметки берутся для пользователя,
+// который просматривает список.
+const noteListColumns = `notes.id, notes.user_id, notes.title, notes.text, notes.format, notes.created_at, users.username,
+	(SELECT COUNT(*) FROM note_comments WHERE note_comments.note_id = notes.id),
+	COALESCE(flags.pinned, FALSE), COALESCE(flags.favorite, FALSE), COALESCE(flags.archived, FALSE),
+	notes.due_at, notes.remind_at,
+	(SELECT COUNT(*) FROM note_checklist_items WHERE note_checklist_items.note_id = notes.id),
+	(SELECT COUNT(*) FROM note_checklist_items WHERE note_checklist_items.note_id = notes.id AND checked),
+	notes.tags, notes.folder, notes.visibility`
+
+// noteListFrom источник строк списков заметок. $1 — идентификатор пользователя, просматривающего список.
+// Личные заметки других пользователей из списков исключаются условием noteVisible.
+const noteListFrom = `
+	FROM notes
+	INNER JOIN users ON notes.user_id = users.id
+	LEFT JOIN note_user_flags flags ON flags.note_id = notes.id AND flags.user_id = $1`
+
+// noteVisible условие видимости заметки пользователю $1: личные заметки видит только автор.
+const noteVisible = `(notes.visibility <> '` + models.NoteVisibilityPrivate + `' OR notes.user_id = $1)`
+
+// trendingGravity степень, в которую возводится возраст заметки в часах при сортировке trending:
+// чем она больше, тем быстрее старые заметки опускаются в списке.
+const trendingGravity = 1.8
+
+// noteReactionCount подзапрос, возвращающий количество реакций на заметку.
+const noteReactionCount = `(SELECT COUNT(*) FROM note_reactions WHERE note_reactions.note_id = notes.id)`
+
+// noteOrder возвращает выражение ORDER BY для порядка сортировки списка заметок.
+// Закрепленные пользователем заметки всегда идут первыми.
+func noteOrder(sort models.NoteSort) string {
+	return `COALESCE(flags.pinned, FALSE) DESC, ` + noteSortOrder(sort)
+}
+
+// noteSortOrder возвращает выражение ORDER BY для порядка сортировки без учета закрепленных заметок.
+func noteSortOrder(sort models.NoteSort) string {
+	switch sort {
+	case models.NoteSortPopular:
+		return noteReactionCount + ` DESC, notes.created_at DESC`
+	case models.NoteSortTrending:
+		// Число реакций, деленное на (возраст в часах + 2) в степени trendingGravity
+		return noteReactionCount + ` / POWER(GREATEST(EXTRACT(EPOCH FROM (LOCALTIMESTAMP - notes.created_at)), 0) / 3600 + 2, ` +
+			fmt.Sprint(trendingGravity) + `) DESC, notes.created_at DESC`
+	default:
+		return `notes.created_at DESC`
+	}
 }
 
 // noteRepository реализация интерфейса NoteRepository.
@@ -34,168 +87,322 @@ func NewNoteRepository(db *sql.DB) NoteRepository {
 	return &noteRepository{db: db}
 }
 
-// AddNote добавляет новую заметку в базу данных.
+// AddNote добавляет новую заметку в базу данных вместе с пунктами списка задач и ссылками на другие заметки.
 func (nr *noteRepository) AddNote(ctx context.Context, note *models.Note) (int, error) {
+	tx, err := nr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось добавить заметку: %v", err)
+	}
+	defer tx.Rollback()
+
+	id, err := insertNote(ctx, tx, note)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("не удалось добавить заметку: %v", err)
+	}
+	return id, nil
+}
+
+// ImportNotes добавляет заметки в одной транзакции: при ошибке не добавляется ни одна. Заметка, у автора
+// которой уже есть заметка с тем же заголовком и текстом, пропускается, и для нее возвращается идентификатор 0.
+// Отметки заметки (закреплена, в избранном, в архиве) сохраняются как отметки ее автора.
+func (nr *noteRepository) ImportNotes(ctx context.Context, notes []*models.Note) ([]int, error) {
+	tx, err := nr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer tx.Rollback()
+
+	ids := make([]int, len(notes))
+	for i, note := range notes {
+		var exists bool
+		err := tx.QueryRowContext(ctx,
+			`SELECT EXISTS (SELECT 1 FROM notes WHERE user_id = $1 AND title = $2 AND text = $3)`,
+			note.UserID, note.Title, note.Text).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось проверить заметку: %v", err)
+		}
+		if exists {
+			continue
+		}
+
+		if ids[i], err = insertNote(ctx, tx, note); err != nil {
+			return nil, err
+		}
+		if note.NoteFlags != (models.NoteFlags{}) {
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO note_user_flags (note_id, user_id, pinned, favorite, archived)
+				VALUES ($1, $2, $3, $4, $5)
+			`, ids[i], note.UserID, note.Pinned, note.Favorite, note.Archived)
+			if err != nil {
+				return nil, fmt.Errorf("не удалось сохранить отметки заметки: %v", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось сохранить заметки: %v", err)
+	}
+	return ids, nil
+}
+
+// insertNote добавляет заметку с пунктами списка задач и ссылками в транзакции tx и записывает событие ее создания.
+// Заметка без видимости становится общедоступной.
+func insertNote(ctx context.Context, tx *sql.Tx, note *models.Note) (int, error) {
+	tags := note.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	visibility := note.Visibility
+	if visibility == "" {
+		visibility = models.NoteVisibilityPublic
+	}
+
 	var id int
 	query := `
-		INSERT INTO notes (user_id, title, text, created_at, author)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO notes (user_id, title, text, format, created_at, author, due_at, remind_at, tags, folder, visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
-	err := nr.db.QueryRowContext(
+	err := tx.QueryRowContext(
 		ctx, query,
-		note.UserID, note.Title, note.Text, note.CreatedAt, note.Author,
+		note.UserID, note.Title, note.Text, note.Format, note.CreatedAt, note.Author, note.DueAt, note.RemindAt,
+		pq.Array(tags), note.Folder, visibility,
 	).Scan(&id)
 	if err != nil {
 		log.Printf("Ошибка при добавлении заметки: %v", err)
 		return 0, fmt.Errorf("не удалось добавить заметку: %v", err)
 	}
+	if err := insertChecklistItems(ctx, tx, id, note.Items); err != nil {
+		return 0, err
+	}
+	if err := syncNoteLinks(ctx, tx, id, note.UserID, note.Title, note.Text); err != nil {
+		return 0, err
+	}
+	created := *note
+	created.ID = id
+	created.Visibility = visibility
+	if err := insertNoteEvent(ctx, tx, models.EventNoteCreated, &created); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
-// GetNoteByID возвращает заметку по её ID из базы данных.
+// GetNoteByID возвращает заметку по её ID из базы данных вместе с пунктами списка задач.
 func (nr *noteRepository) GetNoteByID(ctx context.Context, noteID int) (*models.Note, error) {
 	var note models.Note
 	query := `
-		SELECT id, user_id, title, text, created_at 
-		FROM notes 
+		SELECT id, user_id, title, text, format, created_at, due_at, remind_at, tags, folder, visibility
+		FROM notes
 		WHERE id = $1
 	`
 	err := nr.db.QueryRowContext(ctx, query, noteID).
-		Scan(&note.ID, &note.UserID, &note.Title, &note.Text, &note.CreatedAt)
+		Scan(&note.ID, &note.UserID, &note.Title, &note.Text, &note.Format, &note.CreatedAt, &note.DueAt, &note.RemindAt,
+			pq.Array(&note.Tags), &note.Folder, &note.Visibility)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("заметка не найдена по ID: %d", noteID)
+			return nil, fmt.Errorf("заметка не найдена по ID %d: %w", noteID, sql.ErrNoRows)
 		}
 		log.Printf("Ошибка при получении заметки по ID: %v", err)
 		return nil, fmt.Errorf("не удалось получить заметку по ID: %v", err)
 	}
+	if note.Format == models.NoteFormatChecklist {
+		if note.Items, err = getChecklistItems(ctx, nr.db, noteID); err != nil {
+			return nil, err
+		}
+		note.Progress = utils.ChecklistProgress(note.Items)
+	}
 	return &note, nil
 }
 
-// UpdateNote обновляет заметку в базе данных.
+// UpdateNote обновляет заметку в базе данных. При изменении времени напоминания напоминание
+// снова ожидает отправки, счетчик попыток его отправки сбрасывается. Пункты списка задач заменяются
+// на note.Items; если note.Items не задан у заметки-списка задач, пункты остаются прежними. Ссылки на другие
+// заметки пересчитываются по новому тексту, событие изменения записывается в той же транзакции.
 func (nr *noteRepository) UpdateNote(ctx context.Context, noteID int, note *models.Note) error {
+	tx, err := nr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить заметку: %v", err)
+	}
+	defer tx.Rollback()
+
 	query := `
         UPDATE notes 
-        SET title = $1, text = $2 
-        WHERE id = $3
+        SET title = $1, text = $2, format = $3, due_at = $4, remind_at = $5,
+            reminded_at = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminded_at END,
+            reminder_attempts = CASE WHEN remind_at IS DISTINCT FROM $5 THEN 0 ELSE reminder_attempts END,
+            reminder_next_attempt_at = CASE WHEN remind_at IS DISTINCT FROM $5 THEN NULL ELSE reminder_next_attempt_at END
+        WHERE id = $6
+        RETURNING user_id, author, created_at, visibility
     `
-	result, err := nr.db.ExecContext(ctx, query, note.Title, note.Text, noteID)
+	updated := *note
+	updated.ID = noteID
+	err = tx.QueryRowContext(ctx, query, note.Title, note.Text, note.Format, note.DueAt, note.RemindAt, noteID).
+		Scan(&updated.UserID, &updated.Author, &updated.CreatedAt, &updated.Visibility)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("нет затронутых строк, ID заметки %d: %w", noteID, sql.ErrNoRows)
+	}
 	if err != nil {
 		log.Printf("Ошибка при обновлении заметки: %v", err)
 		return fmt.Errorf("не удалось обновить заметку: %v", err)
 	}
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("нет затронутых строк, ID заметки: %d", noteID)
+
+	if note.Format != models.NoteFormatChecklist || note.Items != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM note_checklist_items WHERE note_id = $1`, noteID); err != nil {
+			return fmt.Errorf("не удалось обновить пункты списка: %v", err)
+		}
+		if err := insertChecklistItems(ctx, tx, noteID, note.Items); err != nil {
+			return err
+		}
+	}
+	if err := syncNoteLinks(ctx, tx, noteID, note.UserID, note.Title, note.Text); err != nil {
+		return err
+	}
+	if err := insertNoteEvent(ctx, tx, models.EventNoteUpdated, &updated); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось обновить заметку: %v", err)
 	}
 	return nil
 }
 
-// DeleteNote удаляет заметку из базы данных.
+// DeleteNote удаляет заметку из базы данных и записывает событие ее удаления в той же транзакции.
 func (nr *noteRepository) DeleteNote(ctx context.Context, noteID int) error {
-	const deleteQuery = "DELETE FROM notes WHERE id = $1"
+	const deleteQuery = "DELETE FROM notes WHERE id = $1 RETURNING user_id, visibility"
 
-	result, err := nr.db.ExecContext(ctx, deleteQuery, noteID)
+	tx, err := nr.db.BeginTx(ctx, nil)
 	if err != nil {
-		log.Printf("Ошибка при удалении заметки: %v", err)
 		return fmt.Errorf("не удалось удалить заметку: %v", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("нет затронутых строк, ID заметки: %d", noteID)
+	deleted := models.Note{ID: noteID}
+	err = tx.QueryRowContext(ctx, deleteQuery, noteID).Scan(&deleted.UserID, &deleted.Visibility)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("нет затронутых строк, ID заметки %d: %w", noteID, sql.ErrNoRows)
+	}
+	if err != nil {
+		log.Printf("Ошибка при удалении заметки: %v", err)
+		return fmt.Errorf("не удалось удалить заметку: %v", err)
 	}
 
+	if err := insertNoteEvent(ctx, tx, models.EventNoteDeleted, &deleted); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось удалить заметку: %v", err)
+	}
 	return nil
 }
 
+// listNotes выполняет запрос списка заметок. conditions — условия WHERE с параметрами начиная с $3 и значения
+// этих параметров в args. Архивные заметки пользователя возвращаются, только если запрошены opts.Archived,
+// личные заметки других пользователей не возвращаются.
+func (nr *noteRepository) listNotes(ctx context.Context, conditions string, opts models.NoteListOptions, offset, limit int, args ...interface{}) ([]models.Note, error) {
+	where := `WHERE COALESCE(flags.archived, FALSE) = $2 AND ` + noteVisible
+	if conditions != "" {
+		where += ` AND ` + conditions
+	}
+	if opts.DueBefore != nil {
+		args = append(args, *opts.DueBefore)
+		where += ` AND notes.due_at < $` + fmt.Sprint(len(args)+2)
+	}
+	next := len(args) + 3
+	query := `SELECT ` + noteListColumns + noteListFrom + `
+		` + where + `
+		ORDER BY ` + noteOrder(opts.Sort) + `
+		LIMIT $` + fmt.Sprint(next) + ` OFFSET $` + fmt.Sprint(next+1)
+
+	params := append([]interface{}{opts.ViewerID, opts.Archived}, args...)
+	params = append(params, limit, offset)
+	return utils.GetNotes(ctx, nr.db, query, params...)
+}
+
 // GetNotesByUserID возвращает заметки пользователя из базы данных.
-func (nr *noteRepository) GetNotesByUserID(ctx context.Context, userID, offset, limit int) ([]models.Note, error) {
-	query := `
-		SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-		FROM notes 
-		INNER JOIN users ON notes.user_id = users.id 
-		WHERE notes.user_id = $1 
-		ORDER BY notes.created_at DESC 
-		LIMIT $2 OFFSET $3
-	`
-	return utils.GetNotes(ctx, nr.db, query, userID, limit, offset)
+func (nr *noteRepository) GetNotesByUserID(ctx context.Context, userID int, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `notes.user_id = $3`, opts, offset, limit, userID)
 }
 
 // GetNotesByDateRange возвращает заметки за определенный период времени из базы данных.
-func (nr *noteRepository) GetNotesByDateRange(ctx context.Context, startDate, endDate string, offset, limit int) ([]models.Note, error) {
-	query := `
-		SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-		FROM notes 
-		INNER JOIN users ON notes.user_id = users.id 
-		WHERE notes.created_at >= $1 AND notes.created_at <= $2 
-		ORDER BY notes.created_at DESC 
-		LIMIT $3 OFFSET $4
-	`
-	return utils.GetNotes(ctx, nr.db, query, startDate, endDate, limit, offset)
+func (nr *noteRepository) GetNotesByDateRange(ctx context.Context, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `notes.created_at >= $3 AND notes.created_at <= $4`, opts, offset, limit, startDate, endDate)
 }
 
 // GetNotesByDay возвращает заметки за определенный день из базы данных.
-func (nr *noteRepository) GetNotesByDay(ctx context.Context, day string, offset, limit int) ([]models.Note, error) {
-	query := `
-		SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-		FROM notes 
-		INNER JOIN users ON notes.user_id = users.id 
-		WHERE DATE(notes.created_at) = $1 
-		ORDER BY notes.created_at DESC 
-		LIMIT $2 OFFSET $3
-	`
-	return utils.GetNotes(ctx, nr.db, query, day, limit, offset)
+func (nr *noteRepository) GetNotesByDay(ctx context.Context, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `DATE(notes.created_at) = $3`, opts, offset, limit, day)
 }
 
 // GetNotes возвращает все заметки из базы данных.
-func (nr *noteRepository) GetNotes(ctx context.Context, offset, limit int) ([]models.Note, error) {
-	query := `
-		SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-		FROM notes 
-		INNER JOIN users ON notes.user_id = users.id 
-		ORDER BY notes.created_at DESC 
-		LIMIT $1 OFFSET $2
-	`
-	return utils.GetNotes(ctx, nr.db, query, limit, offset)
+func (nr *noteRepository) GetNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, "", opts, offset, limit)
 }
 
 // GetNotesByUserIDAndDateRange возвращает заметки пользователя в определенном диапазоне дат.
-func (nr *noteRepository) GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, offset, limit int) ([]models.Note, error) {
-	query := `
-        SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-        FROM notes 
-        INNER JOIN users ON notes.user_id = users.id 
-        WHERE notes.user_id = $1 AND notes.created_at >= $2 AND notes.created_at <= $3
-        ORDER BY notes.created_at DESC 
-        LIMIT $4 OFFSET $5
-    `
-	return utils.GetNotes(ctx, nr.db, query, userID, startDate, endDate, limit, offset)
+func (nr *noteRepository) GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `notes.user_id = $3 AND notes.created_at >= $4 AND notes.created_at <= $5`, opts, offset, limit, userID, startDate, endDate)
 }
 
 // GetNotesByUserIDAndDate возвращает заметки пользователя за определенную дату.
-func (nr *noteRepository) GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, offset, limit int) ([]models.Note, error) {
-	query := `
-        SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-        FROM notes 
-        INNER JOIN users ON notes.user_id = users.id 
-        WHERE notes.user_id = $1 AND DATE(notes.created_at) = $2
-        ORDER BY notes.created_at DESC 
-        LIMIT $3 OFFSET $4
-    `
-	return utils.GetNotes(ctx, nr.db, query, userID, date, limit, offset)
+func (nr *noteRepository) GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `notes.user_id = $3 AND DATE(notes.created_at) = $4`, opts, offset, limit, userID, date)
 }
 
 // GetNotesByUserIDAndDay возвращает заметки, созданные указанным пользователем за указанный день
-func (nr *noteRepository) GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, offset, limit int) ([]models.Note, error) {
+func (nr *noteRepository) GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `notes.user_id = $3 AND DATE(notes.created_at) = $4`, opts, offset, limit, userID, day)
+}
+
+// GetFavoriteNotes возвращает избранные заметки пользователя opts.ViewerID.
+func (nr *noteRepository) GetFavoriteNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return nr.listNotes(ctx, `flags.favorite`, opts, offset, limit)
+}
+
+// GetNoteFlags возвращает отметки пользователя на заметке.
+func (nr *noteRepository) GetNoteFlags(ctx context.Context, noteID, userID int) (models.NoteFlags, error) {
+	var flags models.NoteFlags
+	query := `SELECT pinned, favorite, archived FROM note_user_flags WHERE note_id = $1 AND user_id = $2`
+	err := nr.db.QueryRowContext(ctx, query, noteID, userID).Scan(&flags.Pinned, &flags.Favorite, &flags.Archived)
+	if err != nil && err != sql.ErrNoRows {
+		return flags, fmt.Errorf("не удалось получить отметки заметки: %v", err)
+	}
+	return flags, nil
+}
+
+// SetNoteFlag устанавливает или снимает отметку пользователя на заметке.
+func (nr *noteRepository) SetNoteFlag(ctx context.Context, noteID, userID int, flag models.NoteFlag, value bool) error {
+	return setNoteFlag(ctx, nr.db, noteID, userID, flag, value)
+}
+
+// execer общий интерфейс *sql.DB и *sql.Tx для изменения данных.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// setNoteFlag устанавливает или снимает отметку пользователя на заметке через db или транзакцию.
+func setNoteFlag(ctx context.Context, db execer, noteID, userID int, flag models.NoteFlag, value bool) error {
+	var column string
+	switch flag {
+	case models.NoteFlagPinned:
+		column = "pinned"
+	case models.NoteFlagFavorite:
+		column = "favorite"
+	case models.NoteFlagArchived:
+		column = "archived"
+	default:
+		return fmt.Errorf("неизвестная отметка заметки: %s", flag)
+	}
+
 	query := `
-		SELECT notes.id, notes.user_id, notes.title, notes.text, notes.created_at, users.username 
-		FROM notes 
-		INNER JOIN users ON notes.user_id = users.id 
-		WHERE notes.user_id = $1 AND DATE(notes.created_at) = $2 
-		ORDER BY notes.created_at DESC 
-		LIMIT $3 OFFSET $4
+		INSERT INTO note_user_flags (note_id, user_id, ` + column + `, updated_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+		ON CONFLICT (note_id, user_id) DO UPDATE SET ` + column + ` = EXCLUDED.` + column + `, updated_at = CURRENT_TIMESTAMP
 	`
-	return utils.GetNotes(ctx, nr.db, query, userID, day, limit, offset)
+	if _, err := db.ExecContext(ctx, query, noteID, userID, value); err != nil {
+		return fmt.Errorf("не удалось изменить отметку заметки: %v", err)
+	}
+	return nil
 }
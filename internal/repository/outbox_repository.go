@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+	"note_app/pkg/utils"
+	"strconv"
+	"time"
+)
+
+// maxOutboxErrorLength максимальная длина сохраняемой ошибки пересылки события в символах.
+const maxOutboxErrorLength = 500
+
+// OutboxRepository интерфейс для пересылки событий заметок из таблицы note_events_outbox.
+// События записываются в нее методами NoteRepository, ChecklistRepository и BulkRepository в одной
+// транзакции с изменением заметки.
+type OutboxRepository interface {
+	ProcessPendingEvents(ctx context.Context, limit int, publish func(event models.NoteEvent, attempt int) (time.Time, error)) (int, error)
+	DeletePublishedEvents(ctx context.Context, before time.Time) (int, error)
+}
+
+// outboxRepository реализация интерфейса OutboxRepository.
+type outboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository создает новый экземпляр OutboxRepository.
+func NewOutboxRepository(db *sql.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// insertNoteEvent записывает событие eventType заметки note в таблицу исходящих событий через транзакцию tx.
+// Событие удаления содержит только идентификаторы заметки и автора. Событие личной заметки отмечается как личное.
+//
+// Событие нужно записывать после того, как строка заметки изменена или заблокирована: тогда события одной
+// заметки получают идентификаторы в порядке фиксации транзакций.
+func insertNoteEvent(ctx context.Context, tx execer, eventType string, note *models.Note) error {
+	var data interface{}
+	if eventType != models.EventNoteDeleted {
+		payload, err := json.Marshal(models.NoteEventData{
+			ID:        note.ID,
+			Title:     note.Title,
+			Excerpt:   utils.NoteExcerpt(note.Text, note.Format, utils.ExcerptLength),
+			Format:    note.Format,
+			Author:    note.Author,
+			CreatedAt: note.CreatedAt,
+			DueAt:     note.DueAt,
+		})
+		if err != nil {
+			return err
+		}
+		data = string(payload)
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO note_events_outbox (note_id, user_id, event_type, note, private)
+		VALUES ($1, $2, $3, $4::jsonb, $5)
+	`, note.ID, note.UserID, eventType, data, note.Visibility == models.NoteVisibilityPrivate)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить событие заметки: %v", err)
+	}
+	return nil
+}
+
+// pendingEvent неотправленное событие и число уже выполненных попыток его отправки.
+type pendingEvent struct {
+	event    models.NoteEvent
+	attempts int
+}
+
+// failedEvent событие, которое не удалось отправить. Нулевое retryAt означает, что попытки исчерпаны.
+type failedEvent struct {
+	id      string
+	retryAt time.Time
+	message string
+}
+
+// ProcessPendingEvents выбирает до limit неотправленных событий, время попытки которых наступило, в порядке
+// записи и вызывает для каждого publish с номером попытки. Если publish завершился без ошибки, событие
+// отмечается отправленным, иначе следующая попытка назначается на возвращенное publish время, а если оно нулевое,
+// событие отмечается неудачным и больше не отправляется. Возвращает число отправленных событий; если
+// не удалось сохранить ошибку отправки части событий, отправленные события все равно отмечаются и вместе с их
+// числом возвращается ошибка.
+//
+// Пересылку выполняет один экземпляр приложения: транзакция берет рекомендательную блокировку, и если ее держит
+// другой экземпляр, ничего не делает. Если событие заметки отправить не удалось, следующие события этой заметки
+// не отправляются, пока оно не будет отправлено или отмечено неудачным, поэтому получатели видят события каждой
+// заметки в порядке их записи, а события других заметок не задерживаются. Событие, отправленное не всем
+// получателям, отправляется повторно всем, то есть доставка выполняется хотя бы один раз.
+func (or *outboxRepository) ProcessPendingEvents(ctx context.Context, limit int, publish func(event models.NoteEvent, attempt int) (time.Time, error)) (int, error) {
+	tx, err := or.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, `SELECT pg_try_advisory_xact_lock(hashtext('note_events_outbox'))`).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("не удалось заблокировать исходящие события: %v", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	// События заметки, предыдущее событие которой уже не удалось отправить, ждут его и не занимают порцию
+	query := `
+		SELECT id, note_id, user_id, event_type, note, private, created_at, attempts
+		FROM note_events_outbox
+		WHERE published_at IS NULL AND failed_at IS NULL AND next_attempt_at <= NOW()
+			AND NOT EXISTS (
+				SELECT 1 FROM note_events_outbox earlier
+				WHERE earlier.note_id = note_events_outbox.note_id AND earlier.id < note_events_outbox.id
+					AND earlier.published_at IS NULL AND earlier.failed_at IS NULL AND earlier.attempts > 0
+			)
+		ORDER BY id
+		LIMIT $1
+	`
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось получить исходящие события: %v", err)
+	}
+	var events []pendingEvent
+	for rows.Next() {
+		var id int64
+		var pending pendingEvent
+		event := &pending.event
+		var note []byte
+		err := rows.Scan(&id, &event.NoteID, &event.UserID, &event.Type, &note, &event.Private, &event.Time, &pending.attempts)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		event.ID = strconv.FormatInt(id, 10)
+		if note != nil {
+			event.Note = &models.NoteEventData{}
+			if err := json.Unmarshal(note, event.Note); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("не удалось прочитать событие %d: %v", id, err)
+			}
+		}
+		events = append(events, pending)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	published, failed := publishInOrder(events, publish)
+	if len(published) > 0 {
+		_, err := tx.ExecContext(ctx, `
+			UPDATE note_events_outbox
+			SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+			WHERE id = ANY($1::bigint[])
+		`, pq.Array(published))
+		if err != nil {
+			return 0, fmt.Errorf("не удалось отметить отправленные события: %v", err)
+		}
+	}
+
+	// Ошибка сохранения результата одного события не должна отменять отметки об отправке остальных,
+	// поэтому каждое событие сохраняется в своей точке сохранения
+	var failures []error
+	for _, failure := range failed {
+		if err := recordOutboxFailure(ctx, tx, failure); err != nil {
+			failures = append(failures, fmt.Errorf("не удалось сохранить ошибку отправки события %s: %v", failure.id, err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("не удалось отметить отправленные события: %v", err)
+	}
+	return len(published), errors.Join(failures...)
+}
+
+// recordOutboxFailure сохраняет неудачную попытку отправки события в точке сохранения транзакции tx и при
+// ошибке откатывается к ней, оставляя транзакцию пригодной для фиксации.
+func recordOutboxFailure(ctx context.Context, tx *sql.Tx, failure failedEvent) error {
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT outbox_failure`); err != nil {
+		return err
+	}
+	var err error
+	if failure.retryAt.IsZero() {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE note_events_outbox SET attempts = attempts + 1, last_error = $1, failed_at = NOW() WHERE id = $2
+		`, failure.message, failure.id)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE note_events_outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3
+		`, failure.message, failure.retryAt, failure.id)
+	}
+	if err != nil {
+		if _, rollbackErr := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT outbox_failure`); rollbackErr != nil {
+			return rollbackErr
+		}
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `RELEASE SAVEPOINT outbox_failure`)
+	return err
+}
+
+// publishInOrder передает события publish по порядку и возвращает идентификаторы отправленных событий и ошибки
+// отправки. После первой ошибки события заметки до конца порции пропускаются.
+func publishInOrder(events []pendingEvent, publish func(event models.NoteEvent, attempt int) (time.Time, error)) ([]string, []failedEvent) {
+	var published []string
+	var failed []failedEvent
+	blocked := make(map[int]bool)
+	for _, pending := range events {
+		event := pending.event
+		if blocked[event.NoteID] {
+			continue
+		}
+		retryAt, err := publish(event, pending.attempts+1)
+		if err != nil {
+			blocked[event.NoteID] = true
+			message := utils.TruncateString(err.Error(), maxOutboxErrorLength)
+			failed = append(failed, failedEvent{id: event.ID, retryAt: retryAt, message: message})
+			continue
+		}
+		published = append(published, event.ID)
+	}
+	return published, failed
+}
+
+// DeletePublishedEvents удаляет события, отправленные или отмеченные неудачными раньше before. Возвращает число
+// удаленных событий.
+func (or *outboxRepository) DeletePublishedEvents(ctx context.Context, before time.Time) (int, error) {
+	result, err := or.db.ExecContext(ctx, `DELETE FROM note_events_outbox WHERE published_at < $1 OR failed_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось удалить отправленные события: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"errors"
+	"note_app/internal/models"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestPublishInOrder(t *testing.T) {
+	var events []pendingEvent
+	for i, noteID := range []int{1, 2, 1, 3, 2, 1} {
+		events = append(events, pendingEvent{
+			event:    models.NoteEvent{ID: strconv.Itoa(i + 1), NoteID: noteID},
+			attempts: i % 2,
+		})
+	}
+
+	retryAt := time.Now().Add(time.Minute)
+	var calls []string
+	attempts := make(map[string]int)
+	published, failed := publishInOrder(events, func(event models.NoteEvent, attempt int) (time.Time, error) {
+		calls = append(calls, event.ID)
+		attempts[event.ID] = attempt
+		// Не удается отправить второе событие заметки 1 и первое событие заметки 2
+		if event.ID == "3" || event.ID == "2" {
+			return retryAt, errors.New("получатель недоступен")
+		}
+		return time.Time{}, nil
+	})
+
+	if want := []string{"1", "2", "3", "4"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("отправлены события %v, ожидались %v: после ошибки события заметки пропускаются", calls, want)
+	}
+	if want := []string{"1", "4"}; !reflect.DeepEqual(published, want) {
+		t.Errorf("отмечены отправленными %v, ожидались %v", published, want)
+	}
+	if len(failed) != 2 || failed[0].id != "2" || failed[1].id != "3" || !failed[0].retryAt.Equal(retryAt) ||
+		failed[0].message != "получатель недоступен" {
+		t.Errorf("ошибки отправки: %+v", failed)
+	}
+	if attempts["1"] != 1 || attempts["2"] != 2 {
+		t.Errorf("номера попыток: %v", attempts)
+	}
+}
+
+func TestPublishInOrderTruncatesLongError(t *testing.T) {
+	// Ошибки получателей на русском языке, объединенные errors.Join, длиннее maxOutboxErrorLength байт
+	var errs []error
+	for i := 0; i < 20; i++ {
+		errs = append(errs, errors.New("не удалось поставить событие в очередь веб-хуков: соединение разорвано"))
+	}
+	events := []pendingEvent{{event: models.NoteEvent{ID: "1", NoteID: 1}}}
+
+	_, failed := publishInOrder(events, func(event models.NoteEvent, attempt int) (time.Time, error) {
+		return time.Time{}, errors.Join(errs...)
+	})
+	if len(failed) != 1 {
+		t.Fatalf("ошибок отправки %d, ожидалась 1", len(failed))
+	}
+	message := failed[0].message
+	if !utf8.ValidString(message) || utf8.RuneCountInString(message) != maxOutboxErrorLength {
+		t.Errorf("ошибка длиной %d символов, допустимый UTF-8: %v", utf8.RuneCountInString(message), utf8.ValidString(message))
+	}
+}
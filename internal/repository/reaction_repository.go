@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+)
+
+// ReactionRepository интерфейс для работы с реакциями на заметки.
+type ReactionRepository interface {
+	AddReaction(ctx context.Context, noteID, userID int, reaction string) error
+	RemoveReaction(ctx context.Context, noteID, userID int, reaction string) error
+	GetReactionSummaries(ctx context.Context, noteIDs []int, userID int) (map[int]models.ReactionSummary, error)
+}
+
+// reactionRepository реализация интерфейса ReactionRepository.
+type reactionRepository struct {
+	db *sql.DB
+}
+
+// NewReactionRepository создает новый экземпляр ReactionRepository.
+func NewReactionRepository(db *sql.DB) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// AddReaction добавляет реакцию пользователя. Повторное добавление той же реакции ничего не меняет.
+func (rr *reactionRepository) AddReaction(ctx context.Context, noteID, userID int, reaction string) error {
+	query := `
+		INSERT INTO note_reactions (note_id, user_id, reaction)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (note_id, user_id, reaction) DO NOTHING
+	`
+	if _, err := rr.db.ExecContext(ctx, query, noteID, userID, reaction); err != nil {
+		return fmt.Errorf("не удалось добавить реакцию: %v", err)
+	}
+	return nil
+}
+
+// RemoveReaction удаляет реакцию пользователя.
+func (rr *reactionRepository) RemoveReaction(ctx context.Context, noteID, userID int, reaction string) error {
+	result, err := rr.db.ExecContext(ctx,
+		`DELETE FROM note_reactions WHERE note_id = $1 AND user_id = $2 AND reaction = $3`, noteID, userID, reaction)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить реакцию: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetReactionSummaries возвращает реакции на несколько заметок одним запросом. Заметки без реакций
+// в результат не попадают.
+func (rr *reactionRepository) GetReactionSummaries(ctx context.Context, noteIDs []int, userID int) (map[int]models.ReactionSummary, error) {
+	summaries := make(map[int]models.ReactionSummary)
+	if len(noteIDs) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+		SELECT note_id, reaction, COUNT(*), BOOL_OR(user_id = $2)
+		FROM note_reactions
+		WHERE note_id = ANY($1)
+		GROUP BY note_id, reaction
+		ORDER BY note_id, reaction
+	`
+	rows, err := rr.db.QueryContext(ctx, query, pq.Array(noteIDs), userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить реакции: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var noteID, count int
+		var reaction string
+		var mine bool
+		if err := rows.Scan(&noteID, &reaction, &count, &mine); err != nil {
+			return nil, err
+		}
+		summary, found := summaries[noteID]
+		if !found {
+			summary = models.ReactionSummary{Counts: map[string]int{}, Mine: []string{}}
+		}
+		summary.Counts[reaction] = count
+		if mine {
+			summary.Mine = append(summary.Mine, reaction)
+		}
+		summaries[noteID] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+	"time"
+)
+
+// ReminderRepository интерфейс для выборки и отметки напоминаний о заметках.
+type ReminderRepository interface {
+	ClaimDueReminders(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]models.Reminder, error)
+	MarkReminderSent(ctx context.Context, reminder models.Reminder) error
+	MarkReminderFailed(ctx context.Context, reminder models.Reminder, retryAt time.Time) error
+}
+
+// reminderRepository реализация интерфейса ReminderRepository.
+type reminderRepository struct {
+	db *sql.DB
+}
+
+// NewReminderRepository создает новый экземпляр ReminderRepository.
+func NewReminderRepository(db *sql.DB) ReminderRepository {
+	return &reminderRepository{db: db}
+}
+
+// ClaimDueReminders выбирает до limit наступивших и еще не отправленных напоминаний, у которых было меньше
+// maxAttempts попыток и время следующей попытки наступило, и занимает их на время lease: увеличивает число
+// попыток и переносит следующую попытку на lease вперед. Возвращает занятые напоминания.
+//
+// Напоминания занимаются в короткой транзакции (FOR UPDATE SKIP LOCKED), а отправляются уже после ее
+// завершения, поэтому несколько экземпляров приложения получают разные напоминания и не держат блокировки
+// во время отправки. Если процесс завершится, не отметив результат, напоминание будет выбрано снова после
+// истечения lease.
+func (rr *reminderRepository) ClaimDueReminders(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]models.Reminder, error) {
+	tx, err := rr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Напоминания с ошибкой отложены до reminder_next_attempt_at, поэтому не занимают порцию раньше
+	// ожидающих первой попытки
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM notes
+			WHERE remind_at <= NOW() AND reminded_at IS NULL AND reminder_attempts < $2
+				AND (reminder_next_attempt_at IS NULL OR reminder_next_attempt_at <= NOW())
+			ORDER BY COALESCE(reminder_next_attempt_at, remind_at)
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE notes
+		SET reminder_attempts = notes.reminder_attempts + 1, reminder_next_attempt_at = NOW() + $3::float8 * INTERVAL '1 second'
+		FROM due, users
+		WHERE notes.id = due.id AND users.id = notes.user_id
+		RETURNING notes.id, notes.user_id, users.username,
+			CASE WHEN users.email_verified THEN COALESCE(users.email, '') ELSE '' END,
+			notes.title, notes.due_at, notes.remind_at, notes.reminder_attempts
+	`
+	rows, err := tx.QueryContext(ctx, query, limit, maxAttempts, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить напоминания: %v", err)
+	}
+	var reminders []models.Reminder
+	for rows.Next() {
+		var reminder models.Reminder
+		err := rows.Scan(&reminder.NoteID, &reminder.UserID, &reminder.Username, &reminder.Email,
+			&reminder.Title, &reminder.DueAt, &reminder.RemindAt, &reminder.Attempts)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		reminders = append(reminders, reminder)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось занять напоминания: %v", err)
+	}
+	return reminders, nil
+}
+
+// MarkReminderSent отмечает напоминание отправленным. Если время напоминания за время отправки изменилось,
+// заметка не изменяется: новое напоминание еще ожидает отправки.
+func (rr *reminderRepository) MarkReminderSent(ctx context.Context, reminder models.Reminder) error {
+	query := `
+		UPDATE notes
+		SET reminded_at = NOW(), reminder_next_attempt_at = NULL
+		WHERE id = $1 AND remind_at = $2 AND reminded_at IS NULL
+	`
+	if _, err := rr.db.ExecContext(ctx, query, reminder.NoteID, reminder.RemindAt); err != nil {
+		return fmt.Errorf("не удалось отметить напоминание: %v", err)
+	}
+	return nil
+}
+
+// MarkReminderFailed назначает следующую попытку отправки напоминания на retryAt.
+func (rr *reminderRepository) MarkReminderFailed(ctx context.Context, reminder models.Reminder, retryAt time.Time) error {
+	query := `
+		UPDATE notes
+		SET reminder_next_attempt_at = $3
+		WHERE id = $1 AND remind_at = $2 AND reminded_at IS NULL
+	`
+	if _, err := rr.db.ExecContext(ctx, query, reminder.NoteID, reminder.RemindAt, retryAt); err != nil {
+		return fmt.Errorf("не удалось сохранить ошибку напоминания: %v", err)
+	}
+	return nil
+}
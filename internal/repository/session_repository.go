@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// SessionRepository интерфейс для работы с сессиями пользователей.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *models.Session) error
+	GetSessionByTokenID(ctx context.Context, tokenID string) (*models.Session, error)
+	GetActiveSessionsByUserID(ctx context.Context, userID int) ([]models.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID int) error
+	TouchSession(ctx context.Context, sessionID int) error
+}
+
+// sessionRepository реализация интерфейса SessionRepository.
+type sessionRepository struct {
+	db *sql.DB
+}
+
+// NewSessionRepository создает новый экземпляр SessionRepository.
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// sessionColumns список столбцов, из которых собирается models.Session.
+const sessionColumns = `id, user_id, token_id, device, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+// scanSession считывает сессию из строки результата запроса.
+func scanSession(row rowScanner) (*models.Session, error) {
+	var session models.Session
+	err := row.Scan(&session.ID, &session.UserID, &session.TokenID, &session.Device, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt, &session.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// CreateSession сохраняет новую сессию.
+func (sr *sessionRepository) CreateSession(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, token_id, device, user_agent, ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, last_seen_at
+	`
+	err := sr.db.QueryRowContext(ctx, query, session.UserID, session.TokenID, session.Device, session.UserAgent,
+		session.IP, session.ExpiresAt).
+		Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить сессию: %v", err)
+	}
+	return nil
+}
+
+// GetSessionByTokenID возвращает сессию по идентификатору токена.
+func (sr *sessionRepository) GetSessionByTokenID(ctx context.Context, tokenID string) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + ` FROM sessions WHERE token_id = $1`
+	return scanSession(sr.db.QueryRowContext(ctx, query, tokenID))
+}
+
+// GetActiveSessionsByUserID возвращает неотозванные и непросроченные сессии пользователя.
+func (sr *sessionRepository) GetActiveSessionsByUserID(ctx context.Context, userID int) ([]models.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY last_seen_at DESC
+	`
+	rows, err := sr.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить сессии: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession отзывает сессию пользователя.
+func (sr *sessionRepository) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+	result, err := sr.db.ExecContext(ctx, query, sessionID, userID)
+	if err != nil {
+		return fmt.Errorf("не удалось отозвать сессию: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TouchSession обновляет время последней активности сессии.
+// Чтобы не писать в базу на каждый запрос, время обновляется не чаще раза в минуту.
+func (sr *sessionRepository) TouchSession(ctx context.Context, sessionID int) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND last_seen_at < CURRENT_TIMESTAMP - INTERVAL '1 minute'
+	`
+	_, err := sr.db.ExecContext(ctx, query, sessionID)
+	if err != nil {
+		return fmt.Errorf("не удалось обновить время активности сессии: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// StatsRepository интерфейс для получения сводной статистики.
+type StatsRepository interface {
+	GetStats(ctx context.Context) (*models.Stats, error)
+}
+
+// statsRepository реализация интерфейса StatsRepository.
+type statsRepository struct {
+	db *sql.DB
+}
+
+// NewStatsRepository создает новый экземпляр StatsRepository.
+func NewStatsRepository(db *sql.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// GetStats возвращает количество пользователей и заметок.
+func (sr *statsRepository) GetStats(ctx context.Context) (*models.Stats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE disabled),
+			(SELECT COUNT(*) FROM users WHERE role = $1),
+			(SELECT COUNT(*) FROM notes),
+			(SELECT COUNT(*) FROM notes WHERE created_at >= NOW() - INTERVAL '1 day')
+	`
+	var stats models.Stats
+	err := sr.db.QueryRowContext(ctx, query, models.RoleAdmin).
+		Scan(&stats.Users, &stats.DisabledUsers, &stats.Admins, &stats.Notes, &stats.NotesLastDay)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить статистику: %v", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// TemplateRepository интерфейс для работы с шаблонами заметок.
+type TemplateRepository interface {
+	CreateTemplate(ctx context.Context, template *models.Template) error
+	GetTemplate(ctx context.Context, templateID int) (*models.Template, error)
+	GetTemplatesForUser(ctx context.Context, userID int) ([]models.Template, error)
+	UpdateTemplate(ctx context.Context, template *models.Template) error
+	DeleteTemplate(ctx context.Context, templateID int) error
+}
+
+// templateRepository реализация интерфейса TemplateRepository.
+type templateRepository struct {
+	db *sql.DB
+}
+
+// NewTemplateRepository создает новый экземпляр TemplateRepository.
+func NewTemplateRepository(db *sql.DB) TemplateRepository {
+	return &templateRepository{db: db}
+}
+
+// templateColumns список столбцов, из которых собирается models.Template.
+const templateColumns = `id, COALESCE(user_id, 0), user_id IS NULL, name, title, text, format, created_at, updated_at`
+
+// scanTemplate считывает шаблон из строки результата запроса.
+func scanTemplate(row rowScanner) (*models.Template, error) {
+	var template models.Template
+	var updatedAt sql.NullTime
+	err := row.Scan(&template.ID, &template.UserID, &template.Global, &template.Name,
+		&template.Title, &template.Text, &template.Format, &template.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if updatedAt.Valid {
+		template.UpdatedAt = &updatedAt.Time
+	}
+	return &template, nil
+}
+
+// CreateTemplate сохраняет шаблон. Для общего шаблона владелец не сохраняется.
+func (tr *templateRepository) CreateTemplate(ctx context.Context, template *models.Template) error {
+	var userID sql.NullInt64
+	if !template.Global {
+		userID = sql.NullInt64{Int64: int64(template.UserID), Valid: true}
+	}
+	query := `
+		INSERT INTO note_templates (user_id, name, title, text, format)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := tr.db.QueryRowContext(ctx, query, userID, template.Name, template.Title, template.Text, template.Format).
+		Scan(&template.ID, &template.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить шаблон: %v", err)
+	}
+	return nil
+}
+
+// GetTemplate возвращает шаблон по идентификатору.
+func (tr *templateRepository) GetTemplate(ctx context.Context, templateID int) (*models.Template, error) {
+	query := `SELECT ` + templateColumns + ` FROM note_templates WHERE id = $1`
+	return scanTemplate(tr.db.QueryRowContext(ctx, query, templateID))
+}
+
+// GetTemplatesForUser возвращает шаблоны пользователя и общие шаблоны: сначала собственные, затем по имени.
+func (tr *templateRepository) GetTemplatesForUser(ctx context.Context, userID int) ([]models.Template, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM note_templates
+		WHERE user_id = $1 OR user_id IS NULL
+		ORDER BY user_id IS NULL, name, id
+	`
+	rows, err := tr.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить шаблоны: %v", err)
+	}
+	defer rows.Close()
+
+	templates := []models.Template{}
+	for rows.Next() {
+		template, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// UpdateTemplate изменяет имя, заголовок, текст и формат шаблона и отмечает время изменения.
+func (tr *templateRepository) UpdateTemplate(ctx context.Context, template *models.Template) error {
+	query := `
+		UPDATE note_templates
+		SET name = $1, title = $2, text = $3, format = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+	result, err := tr.db.ExecContext(ctx, query, template.Name, template.Title, template.Text, template.Format, template.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить шаблон: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteTemplate удаляет шаблон.
+func (tr *templateRepository) DeleteTemplate(ctx context.Context, templateID int) error {
+	result, err := tr.db.ExecContext(ctx, `DELETE FROM note_templates WHERE id = $1`, templateID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить шаблон: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"note_app/internal/models"
+)
+
+// TokenRepository интерфейс для работы с одноразовыми токенами пользователей.
+type TokenRepository interface {
+	CreateToken(ctx context.Context, token *models.UserToken) error
+	ConsumeToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+	DeleteUserTokens(ctx context.Context, userID int, purpose string) error
+}
+
+// tokenRepository реализация интерфейса TokenRepository.
+type tokenRepository struct {
+	db *sql.DB
+}
+
+// NewTokenRepository создает новый экземпляр TokenRepository.
+func NewTokenRepository(db *sql.DB) TokenRepository {
+	return &tokenRepository{db: db}
+}
+
+// CreateToken сохраняет новый токен.
+func (tr *tokenRepository) CreateToken(ctx context.Context, token *models.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING id
+	`
+	err := tr.db.QueryRowContext(ctx, query, token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt).
+		Scan(&token.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить токен: %v", err)
+	}
+	return nil
+}
+
+// ConsumeToken атомарно помечает действующий токен как использованный и возвращает его.
+// Возвращает sql.ErrNoRows, если токен не найден, просрочен или уже использован.
+func (tr *tokenRepository) ConsumeToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	query := `
+		UPDATE user_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING id, user_id, purpose, token_hash, COALESCE(email, ''), expires_at, used_at
+	`
+	var token models.UserToken
+	err := tr.db.QueryRowContext(ctx, query, purpose, tokenHash).
+		Scan(&token.ID, &token.UserID, &token.Purpose, &token.TokenHash, &token.Email, &token.ExpiresAt, &token.UsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("не удалось использовать токен: %v", err)
+	}
+	return &token, nil
+}
+
+// DeleteUserTokens удаляет все токены пользователя с указанным назначением.
+func (tr *tokenRepository) DeleteUserTokens(ctx context.Context, userID int, purpose string) error {
+	_, err := tr.db.ExecContext(ctx, `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2`, userID, purpose)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить токены: %v", err)
+	}
+	return nil
+}
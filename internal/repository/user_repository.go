@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"note_app/internal/models"
+	"strings"
+	"time"
 )
 
 // UserRepository представляет интерфейс для работы с пользователями.
@@ -11,6 +13,44 @@ type UserRepository interface {
 	CreateUser(user *models.User) error
 	GetUserByID(userID int) (*models.User, error)
 	GetUserByUsername(username string) (*models.User, error)
+	SetTOTPSecret(userID int, secret string) error
+	EnableTOTP(userID int, step int64, recoveryCodeHashes []string) error
+	DisableTOTP(userID int) error
+	UseTOTPStep(userID int, step int64) (bool, error)
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	BeginTOTPAttempt(userID, maxAttempts int, lockout time.Duration) (bool, error)
+	ResetTOTPAttempts(userID int) error
+	BeginChallengeAttempt(tokenID string, userID int, expiresAt time.Time) (int, bool, error)
+	UseChallenge(tokenID string) (bool, error)
+	GetUserByEmail(email string) (*models.User, error)
+	SetEmail(userID int, email string) error
+	MarkEmailVerified(userID int, email string) (bool, error)
+	UpdatePassword(userID int, passwordHash string) error
+	ResetPassword(tokenHash, passwordHash string) error
+	ListUsers(search string, offset, limit int) ([]models.User, error)
+	SetDisabled(userID int, disabled bool) error
+	SetRole(userID int, role string) error
+	SetRoleByUsername(username, role string) error
+}
+
+// userColumns список столбцов, считываемых функцией scanUser.
+const userColumns = `id, username, password, role, disabled, COALESCE(email, ''), email_verified,
+		COALESCE(totp_secret, ''), totp_enabled, totp_last_step`
+
+// rowScanner общий интерфейс *sql.Row и *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser считывает пользователя из строки результата запроса со столбцами userColumns.
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.Disabled, &user.Email, &user.EmailVerified,
+		&user.TOTPSecret, &user.TOTPEnabled, &user.TOTPLastStep)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
 // UserRepositoryImpl представляет реализацию интерфейса UserRepository.
@@ -26,10 +66,10 @@ func NewUserRepository(db *sql.DB) *UserRepositoryImpl {
 // CreateUser создает нового пользователя.
 func (ur *UserRepositoryImpl) CreateUser(user *models.User) error {
 	query := `
-		INSERT INTO users (username, password)
-		VALUES ($1, $2)
+		INSERT INTO users (username, password, email)
+		VALUES ($1, $2, NULLIF($3, ''))
 	`
-	_, err := ur.db.Exec(query, user.Username, user.Password)
+	_, err := ur.db.Exec(query, user.Username, user.Password, user.Email)
 	if err != nil {
 		return fmt.Errorf("ошибка при создании пользователя: %v", err)
 	}
@@ -38,30 +78,340 @@ func (ur *UserRepositoryImpl) CreateUser(user *models.User) error {
 
 // GetUserByID возвращает пользователя по его ID.
 func (ur *UserRepositoryImpl) GetUserByID(userID int) (*models.User, error) {
-	query := `
-		SELECT id, username, password
-		FROM users
-		WHERE id = $1
-	`
-	var user models.User
-	err := ur.db.QueryRow(query, userID).Scan(&user.ID, &user.Username, &user.Password)
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user, err := scanUser(ur.db.QueryRow(query, userID))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка при получении пользователя по ID: %v", err)
 	}
-	return &user, nil
+	return user, nil
 }
 
 // GetUserByUsername возвращает пользователя по его имени пользователя.
 func (ur *UserRepositoryImpl) GetUserByUsername(username string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	user, err := scanUser(ur.db.QueryRow(query, username))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении пользователя по имени пользователя: %v", err)
+	}
+	return user, nil
+}
+
+// SetTOTPSecret сохраняет новый секрет TOTP. До подтверждения 2FA остается выключенной.
+func (ur *UserRepositoryImpl) SetTOTPSecret(userID int, secret string) error {
 	query := `
-		SELECT id, username, password
-		FROM users
-		WHERE username = $1
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = FALSE, totp_last_step = 0
+		WHERE id = $2 AND totp_enabled = FALSE
 	`
-	var user models.User
-	err := ur.db.QueryRow(query, username).Scan(&user.ID, &user.Username, &user.Password)
+	result, err := ur.db.Exec(query, secret, userID)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при получении пользователя по имени пользователя: %v", err)
+		return fmt.Errorf("ошибка при сохранении секрета TOTP: %v", err)
 	}
-	return &user, nil
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("двухфакторная аутентификация уже включена, ID пользователя: %d", userID)
+	}
+	return nil
+}
+
+// EnableTOTP включает 2FA и заменяет коды восстановления пользователя в одной транзакции.
+func (ur *UserRepositoryImpl) EnableTOTP(userID int, step int64, recoveryCodeHashes []string) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE users SET totp_enabled = TRUE, totp_last_step = $1 WHERE id = $2`, step, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при включении 2FA: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении кодов восстановления: %v", err)
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err = tx.Exec(`INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)`, userID, hash)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении кода восстановления: %v", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// DisableTOTP выключает 2FA, удаляя секрет и коды восстановления.
+func (ur *UserRepositoryImpl) DisableTOTP(userID int) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET totp_secret = NULL, totp_enabled = FALSE, totp_last_step = 0, totp_failed_attempts = 0, totp_locked_until = NULL
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при выключении 2FA: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM user_recovery_codes WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении кодов восстановления: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// UseTOTPStep отмечает шаг TOTP как использованный.
+// Возвращает false, если этот или более поздний шаг уже был использован.
+func (ur *UserRepositoryImpl) UseTOTPStep(userID int, step int64) (bool, error) {
+	result, err := ur.db.Exec(`UPDATE users SET totp_last_step = $1 WHERE id = $2 AND totp_last_step < $1`, step, userID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении шага TOTP: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// UseRecoveryCode погашает неиспользованный код восстановления.
+// Возвращает false, если код не найден или уже был использован.
+func (ur *UserRepositoryImpl) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	result, err := ur.db.Exec(query, userID, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при использовании кода восстановления: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// BeginTOTPAttempt учитывает попытку ввода кода 2FA до его проверки, чтобы одновременные попытки не превысили
+// ограничение. Попытка, которая достигает maxAttempts попыток подряд, блокирует следующие на время lockout;
+// успешная попытка снимает блокировку через ResetTOTPAttempts. Возвращает false, если ввод кода заблокирован.
+func (ur *UserRepositoryImpl) BeginTOTPAttempt(userID, maxAttempts int, lockout time.Duration) (bool, error) {
+	// После окончания блокировки попытки считаются заново
+	query := `
+		WITH attempt AS (
+			SELECT id, CASE WHEN totp_locked_until IS NULL THEN totp_failed_attempts + 1 ELSE 1 END AS attempts
+			FROM users
+			WHERE id = $1 AND (totp_locked_until IS NULL OR totp_locked_until <= NOW())
+			FOR UPDATE
+		)
+		UPDATE users
+		SET totp_failed_attempts = attempt.attempts,
+			totp_locked_until = CASE WHEN attempt.attempts >= $2 THEN NOW() + $3 * INTERVAL '1 second' END
+		FROM attempt
+		WHERE users.id = attempt.id
+	`
+	result, err := ur.db.Exec(query, userID, maxAttempts, lockout.Seconds())
+	if err != nil {
+		return false, fmt.Errorf("ошибка при учете попытки ввода кода: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ResetTOTPAttempts сбрасывает счетчик попыток ввода кода 2FA и снимает блокировку после успешной попытки.
+func (ur *UserRepositoryImpl) ResetTOTPAttempts(userID int) error {
+	_, err := ur.db.Exec(`UPDATE users SET totp_failed_attempts = 0, totp_locked_until = NULL WHERE id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при сбросе попыток ввода кода: %v", err)
+	}
+	return nil
+}
+
+// BeginChallengeAttempt учитывает попытку ввода кода с токеном второго шага входа tokenID до его проверки.
+// Возвращает число попыток с этим токеном вместе с текущей и true, если токен уже использован для входа;
+// попытки с использованным токеном не учитываются. Записи о токенах пользователя с истекшим сроком удаляются.
+func (ur *UserRepositoryImpl) BeginChallengeAttempt(tokenID string, userID int, expiresAt time.Time) (int, bool, error) {
+	if _, err := ur.db.Exec(`DELETE FROM two_factor_challenges WHERE user_id = $1 AND expires_at < NOW()`, userID); err != nil {
+		return 0, false, fmt.Errorf("ошибка при удалении истекших токенов входа: %v", err)
+	}
+
+	query := `
+		INSERT INTO two_factor_challenges (token_id, user_id, attempts, expires_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (token_id) DO UPDATE
+		SET attempts = two_factor_challenges.attempts + CASE WHEN two_factor_challenges.used_at IS NULL THEN 1 ELSE 0 END
+		RETURNING attempts, used_at IS NOT NULL
+	`
+	var attempts int
+	var used bool
+	if err := ur.db.QueryRow(query, tokenID, userID, expiresAt).Scan(&attempts, &used); err != nil {
+		return 0, false, fmt.Errorf("ошибка при учете попытки входа: %v", err)
+	}
+	return attempts, used, nil
+}
+
+// UseChallenge отмечает токен второго шага входа использованным. Возвращает false, если он уже использован.
+func (ur *UserRepositoryImpl) UseChallenge(tokenID string) (bool, error) {
+	result, err := ur.db.Exec(`UPDATE two_factor_challenges SET used_at = NOW() WHERE token_id = $1 AND used_at IS NULL`, tokenID)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при использовании токена входа: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// GetUserByEmail возвращает пользователя по адресу электронной почты.
+func (ur *UserRepositoryImpl) GetUserByEmail(email string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
+	user, err := scanUser(ur.db.QueryRow(query, email))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении пользователя по email: %v", err)
+	}
+	return user, nil
+}
+
+// SetEmail сохраняет новый адрес электронной почты. Адрес считается неподтвержденным.
+func (ur *UserRepositoryImpl) SetEmail(userID int, email string) error {
+	_, err := ur.db.Exec(`UPDATE users SET email = $1, email_verified = FALSE WHERE id = $2`, email, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при сохранении email: %v", err)
+	}
+	return nil
+}
+
+// MarkEmailVerified подтверждает адрес, если он не изменился с момента отправки письма.
+func (ur *UserRepositoryImpl) MarkEmailVerified(userID int, email string) (bool, error) {
+	result, err := ur.db.Exec(`UPDATE users SET email_verified = TRUE WHERE id = $1 AND email = $2`, userID, email)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при подтверждении email: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// UpdatePassword сохраняет новый хэш пароля пользователя.
+func (ur *UserRepositoryImpl) UpdatePassword(userID int, passwordHash string) error {
+	_, err := ur.db.Exec(`UPDATE users SET password = $1 WHERE id = $2`, passwordHash, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении пароля: %v", err)
+	}
+	return nil
+}
+
+// ResetPassword использует токен восстановления пароля с хэшем tokenHash, сохраняет новый хэш пароля его
+// пользователя, отзывает все сессии и персональные токены доступа пользователя и удаляет его токены
+// восстановления пароля в одной транзакции: после сброса пароля ни один выданный ранее токен не действует, а если
+// сбросить пароль не удалось, токен из письма остается действительным. Возвращает sql.ErrNoRows, если токен
+// не найден, просрочен или уже использован.
+func (ur *UserRepositoryImpl) ResetPassword(tokenHash, passwordHash string) error {
+	tx, err := ur.db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при начале транзакции: %v", err)
+	}
+	defer tx.Rollback()
+
+	var userID int
+	err = tx.QueryRow(`
+		UPDATE user_tokens
+		SET used_at = CURRENT_TIMESTAMP
+		WHERE purpose = $1 AND token_hash = $2 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`, models.TokenPurposePasswordReset, tokenHash).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка при использовании токена восстановления пароля: %v", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password = $1 WHERE id = $2`, passwordHash, userID); err != nil {
+		return fmt.Errorf("ошибка при обновлении пароля: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отзыве сессий: %v", err)
+	}
+
+	_, err = tx.Exec(`UPDATE personal_access_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при отзыве токенов доступа: %v", err)
+	}
+
+	_, err = tx.Exec(`DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2`, userID, models.TokenPurposePasswordReset)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении токенов восстановления пароля: %v", err)
+	}
+
+	return tx.Commit()
+}
+
+// likeEscaper экранирует символы шаблона LIKE, чтобы строка поиска сравнивалась буквально.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListUsers возвращает пользователей, имя или email которых содержит строку search.
+func (ur *UserRepositoryImpl) ListUsers(search string, offset, limit int) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users
+		WHERE $1 = '' OR username ILIKE '%' || $1 || '%' ESCAPE '\' OR email ILIKE '%' || $1 || '%' ESCAPE '\'
+		ORDER BY id
+		LIMIT $2 OFFSET $3`
+	rows, err := ur.db.Query(query, likeEscaper.Replace(search), limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списка пользователей: %v", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении пользователя: %v", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SetDisabled блокирует или разблокирует учетную запись.
+func (ur *UserRepositoryImpl) SetDisabled(userID int, disabled bool) error {
+	result, err := ur.db.Exec(`UPDATE users SET disabled = $1 WHERE id = $2`, disabled, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении статуса пользователя: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("пользователь не найден, ID: %d: %w", userID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// SetRole изменяет роль пользователя.
+func (ur *UserRepositoryImpl) SetRole(userID int, role string) error {
+	result, err := ur.db.Exec(`UPDATE users SET role = $1 WHERE id = $2`, role, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении роли пользователя: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("пользователь не найден, ID: %d: %w", userID, sql.ErrNoRows)
+	}
+	return nil
+}
+
+// SetRoleByUsername изменяет роль пользователя по его имени.
+func (ur *UserRepositoryImpl) SetRoleByUsername(username, role string) error {
+	result, err := ur.db.Exec(`UPDATE users SET role = $1 WHERE username = $2`, role, username)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении роли пользователя: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("пользователь не найден: %s: %w", username, sql.ErrNoRows)
+	}
+	return nil
 }
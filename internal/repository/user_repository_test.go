@@ -0,0 +1,18 @@
+package repository
+
+import "testing"
+
+func TestLikeEscaper(t *testing.T) {
+	tests := map[string]string{
+		"alice":    "alice",
+		"100%":     `100\%`,
+		"a_b":      `a\_b`,
+		`C:\notes`: `C:\\notes`,
+		`%_\`:      `\%\_\\`,
+	}
+	for search, want := range tests {
+		if got := likeEscaper.Replace(search); got != want {
+			t.Errorf("likeEscaper.Replace(%q) = %q, ожидалось %q", search, got, want)
+		}
+	}
+}
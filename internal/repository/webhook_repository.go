@@ -0,0 +1,320 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/lib/pq"
+	"note_app/internal/models"
+	"time"
+)
+
+// WebhookRepository интерфейс для работы с веб-хуками и очередью их доставок.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	GetWebhook(ctx context.Context, webhookID int) (*models.Webhook, error)
+	GetWebhooksByUserID(ctx context.Context, userID int) ([]models.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) error
+	DeleteWebhook(ctx context.Context, webhookID int) error
+	EnqueueEvent(ctx context.Context, event models.NoteEvent, payload []byte) (int, error)
+	GetDeliveries(ctx context.Context, webhookID, offset, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*models.WebhookDelivery, error)
+	RecordDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error
+}
+
+// webhookRepository реализация интерфейса WebhookRepository.
+type webhookRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookRepository создает новый экземпляр WebhookRepository.
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// webhookColumns список столбцов, из которых собирается models.Webhook.
+const webhookColumns = `id, user_id, url, secret, events, author_id, active, created_at, updated_at`
+
+// scanWebhook считывает веб-хук из строки результата запроса.
+func scanWebhook(row rowScanner) (*models.Webhook, error) {
+	var webhook models.Webhook
+	var authorID sql.NullInt64
+	var updatedAt sql.NullTime
+	err := row.Scan(&webhook.ID, &webhook.UserID, &webhook.URL, &webhook.Secret, pq.Array(&webhook.Events),
+		&authorID, &webhook.Active, &webhook.CreatedAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+	if authorID.Valid {
+		id := int(authorID.Int64)
+		webhook.AuthorID = &id
+	}
+	if updatedAt.Valid {
+		webhook.UpdatedAt = &updatedAt.Time
+	}
+	return &webhook, nil
+}
+
+// CreateWebhook сохраняет веб-хук.
+func (wr *webhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, events, author_id, active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := wr.db.QueryRowContext(ctx, query, webhook.UserID, webhook.URL, webhook.Secret, pq.Array(webhook.Events),
+		webhook.AuthorID, webhook.Active).Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить веб-хук: %v", err)
+	}
+	return nil
+}
+
+// GetWebhook возвращает веб-хук по идентификатору.
+func (wr *webhookRepository) GetWebhook(ctx context.Context, webhookID int) (*models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`
+	return scanWebhook(wr.db.QueryRowContext(ctx, query, webhookID))
+}
+
+// GetWebhooksByUserID возвращает веб-хуки пользователя в порядке создания.
+func (wr *webhookRepository) GetWebhooksByUserID(ctx context.Context, userID int) ([]models.Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY id`
+	rows, err := wr.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить веб-хуки: %v", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// UpdateWebhook изменяет адрес, фильтры и состояние веб-хука и отмечает время изменения.
+func (wr *webhookRepository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	query := `
+		UPDATE webhooks
+		SET url = $1, events = $2, author_id = $3, active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`
+	result, err := wr.db.ExecContext(ctx, query, webhook.URL, pq.Array(webhook.Events), webhook.AuthorID, webhook.Active, webhook.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось изменить веб-хук: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteWebhook удаляет веб-хук вместе с его доставками.
+func (wr *webhookRepository) DeleteWebhook(ctx context.Context, webhookID int) error {
+	result, err := wr.db.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, webhookID)
+	if err != nil {
+		return fmt.Errorf("не удалось удалить веб-хук: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// EnqueueEvent ставит событие в очередь доставки всех включенных веб-хуков, фильтры которых ему соответствуют.
+// Событие личной заметки доставляется только веб-хукам ее автора. Повторно переданное событие в очередь
+// не ставится. Возвращает число созданных доставок.
+func (wr *webhookRepository) EnqueueEvent(ctx context.Context, event models.NoteEvent, payload []byte) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload)
+		SELECT id, $1, $2::text, $3::jsonb
+		FROM webhooks
+		WHERE active
+			AND (cardinality(events) = 0 OR $2::text = ANY(events))
+			AND (author_id IS NULL OR author_id = $4)
+			AND (NOT $5::boolean OR user_id = $4)
+		ON CONFLICT (webhook_id, event_id) WHERE redelivery_of IS NULL DO NOTHING
+	`
+	result, err := wr.db.ExecContext(ctx, query, event.ID, event.Type, string(payload), event.UserID, event.Private)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось поставить событие в очередь веб-хуков: %v", err)
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return int(rowsAffected), nil
+}
+
+// deliveryColumns список столбцов, из которых собирается models.WebhookDelivery.
+const deliveryColumns = `webhook_deliveries.id, webhook_deliveries.webhook_id, webhook_deliveries.event_id,
+	webhook_deliveries.redelivery_of, webhook_deliveries.event_type, webhook_deliveries.payload, webhook_deliveries.status, webhook_deliveries.attempts,
+	webhook_deliveries.next_attempt_at, webhook_deliveries.response_status, COALESCE(webhook_deliveries.error, ''),
+	webhook_deliveries.delivered_at, webhook_deliveries.created_at`
+
+// scanDelivery считывает доставку из строки результата запроса. Для ожидающей доставки заполняется время
+// следующей попытки. extra — дополнительные столбцы после deliveryColumns.
+func scanDelivery(row rowScanner, extra ...interface{}) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var payload []byte
+	var nextAttemptAt, deliveredAt sql.NullTime
+	var responseStatus, redeliveryOf sql.NullInt64
+	dest := []interface{}{&delivery.ID, &delivery.WebhookID, &delivery.EventID, &redeliveryOf, &delivery.EventType, &payload,
+		&delivery.Status, &delivery.Attempts, &nextAttemptAt, &responseStatus, &delivery.Error, &deliveredAt, &delivery.CreatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	if redeliveryOf.Valid {
+		delivery.RedeliveryOf = &redeliveryOf.Int64
+	}
+	if nextAttemptAt.Valid && delivery.Status == models.WebhookDeliveryPending {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return &delivery, nil
+}
+
+// GetDeliveries возвращает доставки веб-хука, начиная с последних.
+func (wr *webhookRepository) GetDeliveries(ctx context.Context, webhookID, offset, limit int) ([]models.WebhookDelivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY id DESC
+		OFFSET $2 LIMIT $3
+	`
+	rows, err := wr.db.QueryContext(ctx, query, webhookID, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить доставки веб-хука: %v", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Redeliver ставит в очередь новую доставку с тем же событием, что и доставка deliveryID веб-хука webhookID.
+// Прежняя доставка остается в журнале без изменений.
+func (wr *webhookRepository) Redeliver(ctx context.Context, webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event_id, redelivery_of, event_type, payload)
+		SELECT webhook_id, event_id, id, event_type, payload
+		FROM webhook_deliveries
+		WHERE id = $1 AND webhook_id = $2
+		RETURNING ` + deliveryColumns
+	return scanDelivery(wr.db.QueryRowContext(ctx, query, deliveryID, webhookID))
+}
+
+// ClaimDueDeliveries выбирает до limit ожидающих доставок включенных веб-хуков, у которых было меньше maxAttempts
+// попыток и время попытки наступило, и занимает их на время lease: увеличивает число попыток и переносит следующую
+// попытку на lease вперед. Возвращает занятые доставки вместе с адресом и ключом подписи веб-хука.
+//
+// Доставка, результат последней попытки которой не удалось сохранить, по истечении lease отмечается неудачной,
+// поэтому число попыток ограничено, даже если результаты не сохраняются.
+//
+// Доставки занимаются в короткой транзакции (FOR UPDATE SKIP LOCKED), а отправляются уже после ее завершения,
+// поэтому несколько экземпляров приложения получают разные доставки и не держат блокировки во время запросов
+// к получателям. Если процесс завершится, не сохранив результат, доставка будет отправлена повторно после
+// истечения lease: получатель должен распознавать повторы по заголовку X-Webhook-Delivery.
+func (wr *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	tx, err := wr.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось начать транзакцию: %v", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = 'failed'
+		WHERE status = 'pending' AND attempts >= $1 AND next_attempt_at <= NOW()
+	`, maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось отметить неудачные доставки веб-хуков: %v", err)
+	}
+
+	query := `
+		WITH due AS (
+			SELECT webhook_deliveries.id
+			FROM webhook_deliveries
+			INNER JOIN webhooks ON webhook_deliveries.webhook_id = webhooks.id
+			WHERE webhook_deliveries.status = 'pending' AND webhook_deliveries.next_attempt_at <= NOW()
+				AND webhook_deliveries.attempts < $3 AND webhooks.active
+			ORDER BY webhook_deliveries.next_attempt_at, webhook_deliveries.id
+			LIMIT $1
+			FOR UPDATE OF webhook_deliveries SKIP LOCKED
+		)
+		UPDATE webhook_deliveries
+		SET attempts = webhook_deliveries.attempts + 1, next_attempt_at = NOW() + $2::float8 * INTERVAL '1 second'
+		FROM due, webhooks
+		WHERE webhook_deliveries.id = due.id AND webhooks.id = webhook_deliveries.webhook_id
+		RETURNING ` + deliveryColumns + `, webhooks.url, webhooks.secret
+	`
+	rows, err := tx.QueryContext(ctx, query, limit, lease.Seconds(), maxAttempts)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось получить доставки веб-хуков: %v", err)
+	}
+	var deliveries []*models.WebhookDelivery
+	for rows.Next() {
+		var url, secret string
+		delivery, err := scanDelivery(rows, &url, &secret)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		delivery.URL, delivery.Secret = url, secret
+		deliveries = append(deliveries, delivery)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("не удалось занять доставки веб-хуков: %v", err)
+	}
+	return deliveries, nil
+}
+
+// RecordDeliveryResult сохраняет результат попытки доставки: состояние, время следующей попытки, код ответа
+// и ошибку. Если доставка уже не ожидает отправки, она не изменяется.
+func (wr *webhookRepository) RecordDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, next_attempt_at = COALESCE($2, next_attempt_at), response_status = $3,
+			error = NULLIF($4, ''), delivered_at = $5
+		WHERE id = $6 AND status = 'pending'
+	`
+	_, err := wr.db.ExecContext(ctx, query, delivery.Status, delivery.NextAttemptAt, delivery.ResponseStatus,
+		delivery.Error, delivery.DeliveredAt, delivery.ID)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить результат доставки веб-хука: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// accessTokenSize размер случайной части персонального токена в байтах.
+	accessTokenSize = 32
+	// maxAccessTokenNameLength максимальная длина названия токена.
+	maxAccessTokenNameLength = 100
+)
+
+var (
+	// ErrInvalidAccessTokenInput возвращается при неверном названии, областях действия или сроке токена.
+	ErrInvalidAccessTokenInput = errors.New("неверные параметры токена доступа")
+	// ErrAccessTokenNotFound возвращается, если токен не найден, просрочен или отозван.
+	ErrAccessTokenNotFound = errors.New("токен доступа не найден")
+)
+
+// knownScopes допустимые области действия персональных токенов.
+var knownScopes = map[string]bool{
+	models.ScopeNotesRead:  true,
+	models.ScopeNotesWrite: true,
+}
+
+// AccessTokenService предоставляет методы для работы с персональными токенами доступа.
+type AccessTokenService struct {
+	repo repository.AccessTokenRepository
+}
+
+// NewAccessTokenService создает новый экземпляр AccessTokenService.
+func NewAccessTokenService(repo repository.AccessTokenRepository) *AccessTokenService {
+	return &AccessTokenService{repo: repo}
+}
+
+// CreateToken создает новый токен. Значение токена возвращается только один раз.
+func (ts *AccessTokenService) CreateToken(ctx context.Context, userID int, input models.AccessTokenInput) (*models.CreatedAccessToken, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > maxAccessTokenNameLength || len(input.Scopes) == 0 || input.ExpiresInDays < 0 {
+		return nil, ErrInvalidAccessTokenInput
+	}
+	for _, scope := range input.Scopes {
+		if !knownScopes[scope] {
+			return nil, ErrInvalidAccessTokenInput
+		}
+	}
+
+	random, err := utils.GenerateRandomToken(accessTokenSize)
+	if err != nil {
+		return nil, err
+	}
+	raw := models.AccessTokenPrefix + random
+
+	token := models.AccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashToken(raw),
+		Scopes:    input.Scopes,
+	}
+	if input.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, input.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := ts.repo.CreateAccessToken(ctx, &token); err != nil {
+		return nil, err
+	}
+	return &models.CreatedAccessToken{AccessToken: token, Token: raw}, nil
+}
+
+// GetTokens возвращает действующие токены пользователя.
+func (ts *AccessTokenService) GetTokens(ctx context.Context, userID int) ([]models.AccessToken, error) {
+	return ts.repo.GetAccessTokensByUserID(ctx, userID)
+}
+
+// RevokeToken отзывает токен пользователя.
+func (ts *AccessTokenService) RevokeToken(ctx context.Context, userID, tokenID int) error {
+	err := ts.repo.RevokeAccessToken(ctx, userID, tokenID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAccessTokenNotFound
+	}
+	return err
+}
+
+// Authenticate проверяет значение токена и отмечает время его использования.
+func (ts *AccessTokenService) Authenticate(ctx context.Context, raw string) (*models.AccessToken, error) {
+	token, err := ts.repo.GetActiveAccessTokenByHash(ctx, utils.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAccessTokenNotFound
+		}
+		return nil, err
+	}
+
+	if err := ts.repo.TouchAccessToken(ctx, token.ID); err != nil {
+		log.Printf("Ошибка при обновлении времени использования токена: %v", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"note_app/internal/mailer"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"strings"
+	"time"
+)
+
+const (
+	// emailVerificationTTL время жизни токена подтверждения email.
+	emailVerificationTTL = 24 * time.Hour
+	// passwordResetTTL время жизни токена восстановления пароля.
+	passwordResetTTL = time.Hour
+	// accountTokenSize размер одноразовых токенов в байтах.
+	accountTokenSize = 32
+)
+
+var (
+	// ErrEmailTaken возвращается, если адрес уже привязан к другому пользователю.
+	ErrEmailTaken = errors.New("адрес электронной почты уже используется")
+	// ErrEmailNotSet возвращается, если у пользователя нет адреса электронной почты.
+	ErrEmailNotSet = errors.New("адрес электронной почты не указан")
+	// ErrInvalidAccountToken возвращается при неверном, просроченном или использованном токене из письма.
+	ErrInvalidAccountToken = errors.New("недействительный или просроченный токен")
+)
+
+// AccountService предоставляет методы для подтверждения email и восстановления пароля.
+type AccountService struct {
+	userRepository  UserRepository
+	tokenRepository repository.TokenRepository
+	mailer          mailer.Mailer
+	passwords       *utils.PasswordManager
+	appURL          string
+}
+
+// NewAccountService создает новый экземпляр AccountService.
+func NewAccountService(userRepository UserRepository, tokenRepository repository.TokenRepository, m mailer.Mailer, passwords *utils.PasswordManager, appURL string) *AccountService {
+	return &AccountService{
+		userRepository:  userRepository,
+		tokenRepository: tokenRepository,
+		mailer:          m,
+		passwords:       passwords,
+		appURL:          strings.TrimRight(appURL, "/"),
+	}
+}
+
+// ChangeEmail привязывает к пользователю новый адрес и отправляет на него письмо для подтверждения.
+func (as *AccountService) ChangeEmail(ctx context.Context, userID int, email string) error {
+	if existing, err := as.userRepository.GetUserByEmail(email); err == nil && existing.ID != userID {
+		return ErrEmailTaken
+	}
+
+	if err := as.userRepository.SetEmail(userID, email); err != nil {
+		return err
+	}
+	return as.SendEmailVerification(ctx, userID)
+}
+
+// SendEmailVerification отправляет письмо со ссылкой для подтверждения текущего адреса пользователя.
+func (as *AccountService) SendEmailVerification(ctx context.Context, userID int) error {
+	user, err := as.userRepository.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return ErrEmailNotSet
+	}
+
+	token, err := as.issueToken(ctx, user, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	return as.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение адреса электронной почты",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nЧтобы подтвердить адрес, перейдите по ссылке:\n%s/me/email/verify?token=%s\n\n"+
+			"Или отправьте токен в POST /me/email/verify: %s\n\nСсылка действительна %s.\n",
+			user.Username, as.appURL, token, token, emailVerificationTTL),
+	})
+}
+
+// VerifyEmail подтверждает адрес по токену из письма.
+func (as *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	stored, err := as.tokenRepository.ConsumeToken(ctx, models.TokenPurposeEmailVerification, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidAccountToken
+		}
+		return err
+	}
+
+	// Адрес мог измениться после отправки письма: такой токен недействителен
+	verified, err := as.userRepository.MarkEmailVerified(stored.UserID, stored.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return ErrInvalidAccountToken
+	}
+	return nil
+}
+
+// RequestPasswordReset отправляет письмо со ссылкой для сброса пароля на подтвержденный адрес пользователя.
+// Чтобы не раскрывать существование учетных записей, отсутствие пользователя или адреса не считается ошибкой.
+func (as *AccountService) RequestPasswordReset(ctx context.Context, username, email string) error {
+	var user *models.User
+	var err error
+	switch {
+	case username != "":
+		user, err = as.userRepository.GetUserByUsername(username)
+	case email != "":
+		user, err = as.userRepository.GetUserByEmail(email)
+	default:
+		return nil
+	}
+	if err != nil {
+		log.Printf("Восстановление пароля: пользователь не найден: %v", err)
+		return nil
+	}
+	if user.Email == "" || !user.EmailVerified {
+		log.Printf("Восстановление пароля: у пользователя %d нет подтвержденного email", user.ID)
+		return nil
+	}
+
+	// Действующим остается только последний выданный токен
+	if err := as.tokenRepository.DeleteUserTokens(ctx, user.ID, models.TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	token, err := as.issueToken(ctx, user, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return as.mailer.Send(ctx, mailer.Message{
+		To:      user.Email,
+		Subject: "Восстановление пароля",
+		Body: fmt.Sprintf("Здравствуйте, %s!\n\nДля сброса пароля перейдите по ссылке:\n%s/password/reset?token=%s\n\n"+
+			"Или отправьте токен в POST /password/reset: %s\n\nСсылка действительна %s. "+
+			"Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.\n",
+			user.Username, as.appURL, token, token, passwordResetTTL),
+	})
+}
+
+// ValidatePassword проверяет новый пароль по настроенным требованиям.
+func (as *AccountService) ValidatePassword(password string) *utils.HTTPError {
+	return as.passwords.Validate(password)
+}
+
+// ResetPassword устанавливает новый пароль по одноразовому токену из письма. Все сессии и персональные токены
+// доступа пользователя отзываются: тот, кто завладел ими до сброса, теряет доступ. Токен используется в одной
+// транзакции со сменой пароля.
+func (as *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	passwordHash, err := as.passwords.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	err = as.userRepository.ResetPassword(utils.HashToken(token), passwordHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInvalidAccountToken
+	}
+	return err
+}
+
+// issueToken генерирует одноразовый токен, сохраняет его хэш и возвращает сам токен.
+func (as *AccountService) issueToken(ctx context.Context, user *models.User, purpose string, ttl time.Duration) (string, error) {
+	token, err := utils.GenerateRandomToken(accountTokenSize)
+	if err != nil {
+		return "", err
+	}
+
+	err = as.tokenRepository.CreateToken(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		Email:     user.Email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+)
+
+var (
+	// ErrUserNotFound возвращается, если пользователь не найден.
+	ErrUserNotFound = errors.New("пользователь не найден")
+	// ErrInvalidRole возвращается при попытке назначить неизвестную роль.
+	ErrInvalidRole = errors.New("неизвестная роль")
+)
+
+// AdminService предоставляет методы для модерации, доступные администраторам.
+type AdminService struct {
+	userRepository  UserRepository
+	noteService     NoteService
+	statsRepository repository.StatsRepository
+}
+
+// NewAdminService создает новый экземпляр AdminService.
+func NewAdminService(userRepository UserRepository, noteService NoteService, statsRepository repository.StatsRepository) *AdminService {
+	return &AdminService{
+		userRepository:  userRepository,
+		noteService:     noteService,
+		statsRepository: statsRepository,
+	}
+}
+
+// ListUsers возвращает пользователей, имя или email которых содержит строку search.
+func (as *AdminService) ListUsers(search string, offset, limit int) ([]models.UserInfo, error) {
+	users, err := as.userRepository.ListUsers(search, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]models.UserInfo, 0, len(users))
+	for _, user := range users {
+		result = append(result, models.UserInfo{
+			ID:            user.ID,
+			Username:      user.Username,
+			Email:         user.Email,
+			EmailVerified: user.EmailVerified,
+			Role:          user.Role,
+			Disabled:      user.Disabled,
+			TOTPEnabled:   user.TOTPEnabled,
+		})
+	}
+	return result, nil
+}
+
+// SetUserDisabled блокирует или разблокирует учетную запись.
+func (as *AdminService) SetUserDisabled(userID int, disabled bool) error {
+	return userNotFound(as.userRepository.SetDisabled(userID, disabled))
+}
+
+// SetUserRole изменяет роль пользователя.
+func (as *AdminService) SetUserRole(userID int, role string) error {
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return ErrInvalidRole
+	}
+	return userNotFound(as.userRepository.SetRole(userID, role))
+}
+
+// PromoteAdmin назначает пользователя с указанным именем администратором.
+func (as *AdminService) PromoteAdmin(username string) error {
+	return userNotFound(as.userRepository.SetRoleByUsername(username, models.RoleAdmin))
+}
+
+// DeleteNote удаляет любую заметку независимо от автора.
+func (as *AdminService) DeleteNote(ctx context.Context, noteID int) error {
+	if _, err := as.noteService.GetNoteByID(ctx, noteID); err != nil {
+		return noteNotFound(err)
+	}
+	return noteNotFound(as.noteService.DeleteNote(ctx, noteID))
+}
+
+// GetStats возвращает сводную статистику приложения.
+func (as *AdminService) GetStats(ctx context.Context) (*models.Stats, error) {
+	return as.statsRepository.GetStats(ctx)
+}
+
+// noteNotFound заменяет sql.ErrNoRows на ErrNoteNotFound.
+func noteNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNoteNotFound
+	}
+	return err
+}
+
+// userNotFound заменяет sql.ErrNoRows на ErrUserNotFound.
+func userNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUserNotFound
+	}
+	return err
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/internal/storage"
+	"note_app/pkg/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultMaxAttachmentSize максимальный размер вложения, если он не задан в конфигурации.
+	defaultMaxAttachmentSize = 10 << 20
+	// maxFilenameLength максимальная длина имени файла вложения в символах.
+	maxFilenameLength = 255
+	// sniffLength количество байт, по которым определяется тип содержимого.
+	sniffLength = 512
+	// storageKeySize размер случайного ключа объекта в хранилище в байтах.
+	storageKeySize = 16
+)
+
+var (
+	// ErrAttachmentNotFound возвращается, если вложение не найдено.
+	ErrAttachmentNotFound = errors.New("вложение не найдено")
+	// ErrAttachmentTooLarge возвращается, если файл превышает допустимый размер.
+	ErrAttachmentTooLarge = errors.New("файл слишком большой")
+	// ErrAttachmentTypeNotAllowed возвращается, если тип содержимого файла не входит в список допустимых.
+	ErrAttachmentTypeNotAllowed = errors.New("недопустимый тип файла")
+	// ErrAttachmentEmpty возвращается для пустого файла.
+	ErrAttachmentEmpty = errors.New("пустой файл")
+)
+
+// AttachmentService предоставляет методы для работы с вложениями заметок.
+type AttachmentService struct {
+	repo         repository.AttachmentRepository
+	store        storage.BlobStore
+	maxSize      int64
+	allowedTypes []string
+}
+
+// NewAttachmentService создает новый экземпляр AttachmentService.
+func NewAttachmentService(repo repository.AttachmentRepository, store storage.BlobStore, cfg config.AttachmentsConfig) *AttachmentService {
+	as := &AttachmentService{
+		repo:         repo,
+		store:        store,
+		maxSize:      cfg.MaxSize,
+		allowedTypes: cfg.AllowedTypes,
+	}
+	if as.maxSize <= 0 {
+		as.maxSize = defaultMaxAttachmentSize
+	}
+	return as
+}
+
+// MaxSize возвращает максимальный размер вложения в байтах.
+func (as *AttachmentService) MaxSize() int64 {
+	return as.maxSize
+}
+
+// Upload сохраняет файл из r как вложение заметки. Файл сначала записывается во временный файл,
+// чтобы проверить размер и определить тип по содержимому до записи в хранилище.
+func (as *AttachmentService) Upload(ctx context.Context, noteID, userID int, filename string, r io.Reader) (*models.Attachment, error) {
+	tmp, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return nil, fmt.Errorf("не удалось создать временный файл: %v", err)
+	}
+	defer func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	size, err := io.Copy(tmp, io.LimitReader(r, as.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл: %v", err)
+	}
+	if size > as.maxSize {
+		return nil, ErrAttachmentTooLarge
+	}
+	if size == 0 {
+		return nil, ErrAttachmentEmpty
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := tmp.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("не удалось прочитать файл: %v", err)
+	}
+	contentType := http.DetectContentType(head[:n])
+	if !as.typeAllowed(contentType) {
+		return nil, ErrAttachmentTypeNotAllowed
+	}
+
+	key, err := utils.GenerateRandomToken(storageKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось сгенерировать ключ вложения: %v", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	if err := as.store.Put(ctx, key, tmp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	attachment := models.Attachment{
+		NoteID:      noteID,
+		UserID:      userID,
+		Filename:    sanitizeFilename(filename),
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}
+	if err := as.repo.CreateAttachment(ctx, &attachment); err != nil {
+		as.deleteBlob(ctx, key)
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+// typeAllowed проверяет тип содержимого по списку допустимых. Элемент списка совпадает с типом целиком
+// (например, "text/plain; charset=utf-8") или с типом без параметров ("application/pdf").
+func (as *AttachmentService) typeAllowed(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	for _, allowed := range as.allowedTypes {
+		if strings.EqualFold(allowed, contentType) || strings.EqualFold(allowed, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAttachments возвращает вложения заметки.
+func (as *AttachmentService) GetAttachments(ctx context.Context, noteID int) ([]models.Attachment, error) {
+	return as.repo.GetAttachmentsByNoteID(ctx, noteID)
+}
+
+// Open возвращает сведения о вложении и его содержимое для чтения с произвольной позиции.
+func (as *AttachmentService) Open(ctx context.Context, noteID, attachmentID int) (*models.Attachment, io.ReadSeekCloser, error) {
+	attachment, err := as.getAttachment(ctx, noteID, attachmentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := as.store.Open(ctx, attachment.StorageKey, attachment.Size)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, nil, ErrAttachmentNotFound
+		}
+		return nil, nil, err
+	}
+	return attachment, content, nil
+}
+
+// DeleteAttachment удаляет вложение заметки и его содержимое из хранилища.
+func (as *AttachmentService) DeleteAttachment(ctx context.Context, noteID, attachmentID int) error {
+	attachment, err := as.getAttachment(ctx, noteID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if err := as.repo.DeleteAttachment(ctx, noteID, attachmentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAttachmentNotFound
+		}
+		return err
+	}
+	as.deleteBlob(ctx, attachment.StorageKey)
+	return nil
+}
+
+// DeleteBlobs удаляет из хранилища содержимое вложений, сведения о которых уже удалены
+// (например, каскадно вместе с заметкой).
+func (as *AttachmentService) DeleteBlobs(ctx context.Context, attachments []models.Attachment) {
+	for _, attachment := range attachments {
+		as.deleteBlob(ctx, attachment.StorageKey)
+	}
+}
+
+// getAttachment возвращает вложение заметки, преобразуя отсутствие записи в ErrAttachmentNotFound.
+func (as *AttachmentService) getAttachment(ctx context.Context, noteID, attachmentID int) (*models.Attachment, error) {
+	attachment, err := as.repo.GetAttachment(ctx, noteID, attachmentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить вложение: %v", err)
+	}
+	return attachment, nil
+}
+
+// deleteBlob удаляет объект из хранилища. Ошибка только записывается в журнал: запись о вложении
+// уже удалена, а оставшийся объект не виден пользователям.
+func (as *AttachmentService) deleteBlob(ctx context.Context, key string) {
+	if err := as.store.Delete(ctx, key); err != nil {
+		log.Printf("Не удалось удалить объект %s из хранилища: %v", key, err)
+	}
+}
+
+// sanitizeFilename оставляет от имени файла только последний элемент пути без управляющих символов
+// и ограничивает его длину.
+func sanitizeFilename(filename string) string {
+	filename = strings.ToValidUTF8(filename, "")
+	filename = filepath.Base(strings.ReplaceAll(filename, `\`, "/"))
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filename)
+	filename = strings.TrimSpace(filename)
+	if filename == "" || filename == "." || filename == "/" {
+		return "file"
+	}
+	if utf8.RuneCountInString(filename) > maxFilenameLength {
+		filename = string([]rune(filename)[:maxFilenameLength])
+	}
+	return filename
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxBulkItems максимальное число пар операция — заметка в одном запросе.
+	maxBulkItems = 500
+	// maxNoteTags максимальное число меток в одной операции tag и у загружаемой заметки.
+	maxNoteTags = 20
+	// maxTagLength максимальная длина метки в символах.
+	maxTagLength = 50
+	// maxFolderLength максимальная длина имени папки в символах.
+	maxFolderLength = 100
+)
+
+// ErrInvalidBulkOperation возвращается для пустого списка операций, неизвестной операции, неверных параметров
+// операции или слишком большого числа заметок.
+var ErrInvalidBulkOperation = errors.New("недопустимые операции над заметками")
+
+// BulkService предоставляет операции над несколькими заметками.
+type BulkService struct {
+	repo        repository.BulkRepository
+	attachments *AttachmentService
+	outbox      *OutboxService
+}
+
+// NewBulkService создает новый экземпляр BulkService. attachments используется для удаления
+// файлов вложений удаленных заметок, outbox уведомляется о событиях удаления заметок.
+func NewBulkService(repo repository.BulkRepository, attachments *AttachmentService, outbox *OutboxService) *BulkService {
+	return &BulkService{repo: repo, attachments: attachments, outbox: outbox}
+}
+
+// Apply выполняет операции пользователя над заметками по порядку в одной транзакции. Для каждой заметки
+// каждой операции возвращается результат. Если хотя бы одна заметка не найдена (в том числе удалена
+// предыдущей операцией) или изменяется не автором, ни одна операция не выполняется.
+func (bs *BulkService) Apply(ctx context.Context, userID int, ops []models.BulkOperation) (*models.BulkReport, error) {
+	ops, err := normalizeBulkOperations(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	// Файлы вложений удаляются после удаления заметок, когда записи о них уже удалены каскадно
+	var attachments []models.Attachment
+	for _, op := range ops {
+		if op.Op != models.BulkOpDelete {
+			continue
+		}
+		for _, noteID := range op.IDs {
+			noteAttachments, err := bs.attachments.GetAttachments(ctx, noteID)
+			if err != nil {
+				return nil, err
+			}
+			attachments = append(attachments, noteAttachments...)
+		}
+	}
+
+	report := &models.BulkReport{}
+	applied, err := bs.repo.ApplyBulk(ctx, userID, ops, func(notes map[int]models.Note) bool {
+		report.Results = checkBulkOperations(ops, notes, userID)
+		for _, result := range report.Results {
+			if result.Status != models.BulkStatusOK {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report.Applied = applied
+	if applied {
+		bs.attachments.DeleteBlobs(ctx, attachments)
+		bs.outbox.Notify()
+	}
+	return report, nil
+}
+
+// checkBulkOperations возвращает результат каждой операции над каждой заметкой. Личная заметка другого
+// пользователя считается ненайденной, чтобы ответ не раскрывал ее существование.
+func checkBulkOperations(ops []models.BulkOperation, notes map[int]models.Note, userID int) []models.BulkResult {
+	var results []models.BulkResult
+	deleted := make(map[int]bool)
+	for _, op := range ops {
+		for _, noteID := range op.IDs {
+			result := models.BulkResult{Op: op.Op, NoteID: noteID, Status: models.BulkStatusOK}
+			note, exists := notes[noteID]
+			switch {
+			case !exists || deleted[noteID] || !note.VisibleTo(userID):
+				result.Status = models.BulkStatusNotFound
+			case op.Op.ChangesNote() && note.UserID != userID:
+				result.Status = models.BulkStatusForbidden
+			case op.Op == models.BulkOpDelete:
+				deleted[noteID] = true
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// normalizeBulkOperations проверяет операции и их параметры и убирает повторы заметок и меток внутри операции.
+func normalizeBulkOperations(ops []models.BulkOperation) ([]models.BulkOperation, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: не указаны операции", ErrInvalidBulkOperation)
+	}
+
+	normalized := make([]models.BulkOperation, 0, len(ops))
+	items := 0
+	for _, op := range ops {
+		operation := models.BulkOperation{Op: op.Op}
+		switch op.Op {
+		case models.BulkOpDelete, models.BulkOpArchive, models.BulkOpUnarchive:
+		case models.BulkOpTag:
+			if len(op.Tags) == 0 {
+				return nil, fmt.Errorf("%w: не указаны метки для операции tag", ErrInvalidBulkOperation)
+			}
+			tags, err := normalizeTags(op.Tags)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBulkOperation, err)
+			}
+			operation.Tags = tags
+		case models.BulkOpMove:
+			if op.Folder == nil {
+				return nil, fmt.Errorf("%w: не указана папка для операции move", ErrInvalidBulkOperation)
+			}
+			folder, err := normalizeFolder(*op.Folder)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBulkOperation, err)
+			}
+			operation.Folder = &folder
+		case models.BulkOpVisibility:
+			if !validVisibility(op.Visibility) {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidBulkOperation, errInvalidVisibility)
+			}
+			operation.Visibility = op.Visibility
+		default:
+			return nil, fmt.Errorf("%w: неизвестная операция %q, допустимые операции: delete, archive, unarchive, tag, move, visibility",
+				ErrInvalidBulkOperation, op.Op)
+		}
+		if len(op.IDs) == 0 {
+			return nil, fmt.Errorf("%w: не указаны заметки для операции %s", ErrInvalidBulkOperation, op.Op)
+		}
+
+		seen := make(map[int]bool, len(op.IDs))
+		operation.IDs = make([]int, 0, len(op.IDs))
+		for _, noteID := range op.IDs {
+			if !seen[noteID] {
+				seen[noteID] = true
+				operation.IDs = append(operation.IDs, noteID)
+			}
+		}
+		items += len(operation.IDs)
+		normalized = append(normalized, operation)
+	}
+	if items > maxBulkItems {
+		return nil, fmt.Errorf("%w: не более %d заметок в одном запросе", ErrInvalidBulkOperation, maxBulkItems)
+	}
+	return normalized, nil
+}
+
+// errInvalidVisibility возвращается для видимости заметки, отличной от public и private.
+var errInvalidVisibility = errors.New("видимость должна быть public или private")
+
+// normalizeTags проверяет метки заметки, убирает пробелы по краям и повторы.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxNoteTags {
+		return nil, fmt.Errorf("не более %d меток", maxNoteTags)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("метка должна быть непустой и не длиннее %d символов", maxTagLength)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// normalizeFolder убирает пробелы по краям имени папки и проверяет его длину.
+func normalizeFolder(folder string) (string, error) {
+	folder = strings.TrimSpace(folder)
+	if utf8.RuneCountInString(folder) > maxFolderLength {
+		return "", fmt.Errorf("имя папки длиннее %d символов", maxFolderLength)
+	}
+	return folder, nil
+}
+
+// validVisibility сообщает, является ли visibility допустимой видимостью заметки.
+func validVisibility(visibility string) bool {
+	return visibility == models.NoteVisibilityPublic || visibility == models.NoteVisibilityPrivate
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"note_app/internal/models"
+	"testing"
+)
+
+func TestCheckBulkOperations(t *testing.T) {
+	notes := map[int]models.Note{
+		1: {ID: 1, UserID: 1, Visibility: models.NoteVisibilityPublic},
+		2: {ID: 2, UserID: 2, Visibility: models.NoteVisibilityPublic},
+		3: {ID: 3, UserID: 2, Visibility: models.NoteVisibilityPrivate},
+	}
+	folder := "Проекты"
+	ops := []models.BulkOperation{
+		{Op: models.BulkOpArchive, IDs: []int{1, 2, 3}},
+		{Op: models.BulkOpMove, IDs: []int{1, 2}, Folder: &folder},
+		{Op: models.BulkOpDelete, IDs: []int{1, 4}},
+		{Op: models.BulkOpTag, IDs: []int{1}, Tags: []string{"работа"}},
+	}
+
+	want := []models.BulkResult{
+		{Op: models.BulkOpArchive, NoteID: 1, Status: models.BulkStatusOK},
+		// Архив личный, поэтому чужие видимые заметки можно архивировать
+		{Op: models.BulkOpArchive, NoteID: 2, Status: models.BulkStatusOK},
+		// Личная заметка другого пользователя не раскрывается
+		{Op: models.BulkOpArchive, NoteID: 3, Status: models.BulkStatusNotFound},
+		{Op: models.BulkOpMove, NoteID: 1, Status: models.BulkStatusOK},
+		{Op: models.BulkOpMove, NoteID: 2, Status: models.BulkStatusForbidden},
+		{Op: models.BulkOpDelete, NoteID: 1, Status: models.BulkStatusOK},
+		{Op: models.BulkOpDelete, NoteID: 4, Status: models.BulkStatusNotFound},
+		// Заметка удалена предыдущей операцией
+		{Op: models.BulkOpTag, NoteID: 1, Status: models.BulkStatusNotFound},
+	}
+
+	got := checkBulkOperations(ops, notes, 1)
+	if len(got) != len(want) {
+		t.Fatalf("получено %d результатов, ожидалось %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("результат %d: %+v, ожидался %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheckBulkOperationsOwnPrivateNote(t *testing.T) {
+	notes := map[int]models.Note{
+		1: {ID: 1, UserID: 1, Visibility: models.NoteVisibilityPrivate},
+	}
+	ops := []models.BulkOperation{{Op: models.BulkOpVisibility, IDs: []int{1}, Visibility: models.NoteVisibilityPublic}}
+
+	got := checkBulkOperations(ops, notes, 1)
+	if len(got) != 1 || got[0].Status != models.BulkStatusOK {
+		t.Fatalf("автор должен изменять свою личную заметку, получено %+v", got)
+	}
+}
+
+func TestNormalizeBulkOperations(t *testing.T) {
+	folder := "  Проекты "
+	ops, err := normalizeBulkOperations([]models.BulkOperation{
+		{Op: models.BulkOpTag, IDs: []int{1, 2, 1}, Tags: []string{" работа", "работа", "дом"}},
+		{Op: models.BulkOpMove, IDs: []int{3}, Folder: &folder},
+	})
+	if err != nil {
+		t.Fatalf("normalizeBulkOperations: %v", err)
+	}
+	if ids := ops[0].IDs; len(ids) != 2 || ids[0] != 1 || ids[1] != 2 {
+		t.Errorf("повторы заметок не убраны: %v", ids)
+	}
+	if tags := ops[0].Tags; len(tags) != 2 || tags[0] != "работа" || tags[1] != "дом" {
+		t.Errorf("метки не нормализованы: %q", tags)
+	}
+	if ops[1].Folder == nil || *ops[1].Folder != "Проекты" {
+		t.Errorf("папка не нормализована: %v", ops[1].Folder)
+	}
+}
+
+func TestNormalizeBulkOperationsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		ops  []models.BulkOperation
+	}{
+		{"без операций", nil},
+		{"неизвестная операция", []models.BulkOperation{{Op: "pin", IDs: []int{1}}}},
+		{"без заметок", []models.BulkOperation{{Op: models.BulkOpArchive}}},
+		{"tag без меток", []models.BulkOperation{{Op: models.BulkOpTag, IDs: []int{1}}}},
+		{"пустая метка", []models.BulkOperation{{Op: models.BulkOpTag, IDs: []int{1}, Tags: []string{" "}}}},
+		{"move без папки", []models.BulkOperation{{Op: models.BulkOpMove, IDs: []int{1}}}},
+		{"неверная видимость", []models.BulkOperation{{Op: models.BulkOpVisibility, IDs: []int{1}, Visibility: "friends"}}},
+	}
+	for _, tt := range tests {
+		if _, err := normalizeBulkOperations(tt.ops); !errors.Is(err, ErrInvalidBulkOperation) {
+			t.Errorf("%s: ожидалась ErrInvalidBulkOperation, получено %v", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+)
+
+var (
+	// ErrNoteNotFound возвращается, если заметка не найдена.
+	ErrNoteNotFound = errors.New("заметка не найдена")
+	// ErrNotChecklist возвращается при операции над пунктами заметки, которая не является списком задач.
+	ErrNotChecklist = errors.New("заметка не является списком задач")
+	// ErrChecklistItemNotFound возвращается, если пункт списка задач не найден.
+	ErrChecklistItemNotFound = errors.New("пункт списка не найден")
+	// ErrInvalidItemOrder возвращается, если новый порядок содержит не все пункты списка или содержит лишние.
+	ErrInvalidItemOrder = errors.New("порядок должен содержать идентификаторы всех пунктов списка по одному разу")
+	// ErrInvalidChecklistItem возвращается для пустого или слишком длинного пункта списка.
+	ErrInvalidChecklistItem = errors.New("недопустимый пункт списка")
+)
+
+// ChecklistService предоставляет методы для работы с пунктами заметок-списков задач.
+type ChecklistService struct {
+	repo   repository.ChecklistRepository
+	outbox *OutboxService
+}
+
+// NewChecklistService создает новый экземпляр ChecklistService. outbox уведомляется о событиях изменения
+// заметок, записанных вместе с изменениями списков.
+func NewChecklistService(repo repository.ChecklistRepository, outbox *OutboxService) *ChecklistService {
+	return &ChecklistService{repo: repo, outbox: outbox}
+}
+
+// AddItem добавляет пункт в конец списка задач.
+func (cs *ChecklistService) AddItem(ctx context.Context, noteID int, input models.ChecklistItemInput) ([]models.ChecklistItem, error) {
+	return cs.update(ctx, noteID, func(items []models.ChecklistItem) ([]models.ChecklistItem, error) {
+		return append(items, models.ChecklistItem{Text: input.Text, Checked: input.Checked}), nil
+	})
+}
+
+// SetChecked отмечает пункт списка выполненным или снимает отметку.
+func (cs *ChecklistService) SetChecked(ctx context.Context, noteID, itemID int, checked bool) ([]models.ChecklistItem, error) {
+	return cs.update(ctx, noteID, func(items []models.ChecklistItem) ([]models.ChecklistItem, error) {
+		for i := range items {
+			if items[i].ID == itemID {
+				items[i].Checked = checked
+				return items, nil
+			}
+		}
+		return nil, ErrChecklistItemNotFound
+	})
+}
+
+// DeleteItem удаляет пункт из списка задач.
+func (cs *ChecklistService) DeleteItem(ctx context.Context, noteID, itemID int) ([]models.ChecklistItem, error) {
+	return cs.update(ctx, noteID, func(items []models.ChecklistItem) ([]models.ChecklistItem, error) {
+		for i := range items {
+			if items[i].ID == itemID {
+				return append(items[:i], items[i+1:]...), nil
+			}
+		}
+		return nil, ErrChecklistItemNotFound
+	})
+}
+
+// Reorder меняет порядок пунктов списка задач. itemIDs должен содержать идентификаторы всех пунктов по одному разу.
+func (cs *ChecklistService) Reorder(ctx context.Context, noteID int, itemIDs []int) ([]models.ChecklistItem, error) {
+	return cs.update(ctx, noteID, func(items []models.ChecklistItem) ([]models.ChecklistItem, error) {
+		if len(itemIDs) != len(items) {
+			return nil, ErrInvalidItemOrder
+		}
+		byID := make(map[int]models.ChecklistItem, len(items))
+		for _, item := range items {
+			byID[item.ID] = item
+		}
+		ordered := make([]models.ChecklistItem, 0, len(items))
+		for _, id := range itemIDs {
+			item, ok := byID[id]
+			if !ok {
+				return nil, ErrInvalidItemOrder
+			}
+			delete(byID, id)
+			ordered = append(ordered, item)
+		}
+		return ordered, nil
+	})
+}
+
+// update изменяет пункты списка задач функцией change, проверяет результат и сохраняет его вместе с текстом заметки.
+func (cs *ChecklistService) update(ctx context.Context, noteID int, change func(items []models.ChecklistItem) ([]models.ChecklistItem, error)) ([]models.ChecklistItem, error) {
+	items, err := cs.repo.UpdateChecklist(ctx, noteID, func(note *models.Note, items []models.ChecklistItem) ([]models.ChecklistItem, string, error) {
+		if note.Format != models.NoteFormatChecklist {
+			return nil, "", ErrNotChecklist
+		}
+		items, err := change(items)
+		if err != nil {
+			return nil, "", err
+		}
+		items, text, httpErr := utils.PrepareChecklist(note.Title, items)
+		if httpErr != nil {
+			return nil, "", fmt.Errorf("%w: %s", ErrInvalidChecklistItem, httpErr.Message)
+		}
+		return items, text, nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	cs.outbox.Notify()
+	return items, nil
+}
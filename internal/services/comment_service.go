@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxCommentLength максимальная длина комментария в символах.
+const maxCommentLength = 2000
+
+var (
+	// ErrCommentNotFound возвращается, если комментарий не найден.
+	ErrCommentNotFound = errors.New("комментарий не найден")
+	// ErrCommentForbidden возвращается, если у пользователя нет прав на изменение или удаление комментария.
+	ErrCommentForbidden = errors.New("нет прав на комментарий")
+	// ErrInvalidComment возвращается для пустого или слишком длинного комментария.
+	ErrInvalidComment = fmt.Errorf("комментарий должен содержать от 1 до %d символов", maxCommentLength)
+)
+
+// CommentService предоставляет методы для работы с комментариями к заметкам.
+type CommentService struct {
+	repo repository.CommentRepository
+}
+
+// NewCommentService создает новый экземпляр CommentService.
+func NewCommentService(repo repository.CommentRepository) *CommentService {
+	return &CommentService{repo: repo}
+}
+
+// AddComment добавляет комментарий пользователя к заметке.
+func (cs *CommentService) AddComment(ctx context.Context, noteID, userID int, text string) (*models.Comment, error) {
+	text, err := normalizeComment(text)
+	if err != nil {
+		return nil, err
+	}
+	comment := models.Comment{NoteID: noteID, UserID: userID, Text: text}
+	if err := cs.repo.CreateComment(ctx, &comment); err != nil {
+		return nil, err
+	}
+	return cs.getComment(ctx, noteID, comment.ID)
+}
+
+// GetComments возвращает страницу комментариев к заметке.
+func (cs *CommentService) GetComments(ctx context.Context, noteID, offset, limit int) ([]models.Comment, error) {
+	return cs.repo.GetCommentsByNoteID(ctx, noteID, offset, limit)
+}
+
+// UpdateComment изменяет текст комментария. Изменять комментарий может только его автор.
+func (cs *CommentService) UpdateComment(ctx context.Context, noteID, commentID, userID int, text string) (*models.Comment, error) {
+	text, err := normalizeComment(text)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := cs.getComment(ctx, noteID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != userID {
+		return nil, ErrCommentForbidden
+	}
+	if err := cs.repo.UpdateComment(ctx, commentID, text); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return cs.getComment(ctx, noteID, commentID)
+}
+
+// DeleteComment удаляет комментарий. Удалить комментарий может его автор или автор заметки.
+func (cs *CommentService) DeleteComment(ctx context.Context, note *models.Note, commentID, userID int) error {
+	comment, err := cs.getComment(ctx, note.ID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != userID && note.UserID != userID {
+		return ErrCommentForbidden
+	}
+	if err := cs.repo.DeleteComment(ctx, commentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// getComment возвращает комментарий к заметке, преобразуя отсутствие записи в ErrCommentNotFound.
+func (cs *CommentService) getComment(ctx context.Context, noteID, commentID int) (*models.Comment, error) {
+	comment, err := cs.repo.GetComment(ctx, noteID, commentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить комментарий: %v", err)
+	}
+	return comment, nil
+}
+
+// normalizeComment убирает пробелы по краям комментария и проверяет его длину.
+func normalizeComment(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	length := utf8.RuneCountInString(text)
+	if length == 0 || length > maxCommentLength {
+		return "", ErrInvalidComment
+	}
+	return text, nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"note_app/internal/export"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+)
+
+// ExportService предоставляет выгрузку заметок пользователя.
+type ExportService struct {
+	repo repository.ExportRepository
+}
+
+// NewExportService создает новый экземпляр ExportService.
+func NewExportService(repo repository.ExportRepository) *ExportService {
+	return &ExportService{repo: repo}
+}
+
+// Export записывает все заметки пользователя в w и завершает выгрузку.
+func (es *ExportService) Export(ctx context.Context, userID int, w export.Writer) error {
+	err := es.repo.ForEachNote(ctx, userID, func(note *models.ExportedNote) error {
+		return w.WriteNote(note)
+	})
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"note_app/internal/config"
+	"note_app/internal/importer"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"time"
+)
+
+const (
+	// defaultMaxImportSize максимальный размер загружаемого файла, если он не задан в конфигурации.
+	defaultMaxImportSize = 20 << 20
+	// defaultMaxImportNotes максимальное число заметок в файле, если оно не задано в конфигурации.
+	defaultMaxImportNotes = 1000
+)
+
+// ImportService предоставляет загрузку заметок из файлов.
+type ImportService struct {
+	repo     repository.NoteRepository
+	outbox   *OutboxService
+	maxSize  int64
+	maxNotes int
+}
+
+// NewImportService создает новый экземпляр ImportService. outbox уведомляется о событиях создания заметок.
+func NewImportService(repo repository.NoteRepository, outbox *OutboxService, cfg config.ImportConfig) *ImportService {
+	is := &ImportService{
+		repo:     repo,
+		outbox:   outbox,
+		maxSize:  cfg.MaxSize,
+		maxNotes: cfg.MaxNotes,
+	}
+	if is.maxSize <= 0 {
+		is.maxSize = defaultMaxImportSize
+	}
+	if is.maxNotes <= 0 {
+		is.maxNotes = defaultMaxImportNotes
+	}
+	return is
+}
+
+// MaxSize возвращает максимальный размер загружаемого файла в байтах.
+func (is *ImportService) MaxSize() int64 {
+	return is.maxSize
+}
+
+// Import загружает заметки пользователя из файла data в формате format. Каждая заметка проверяется по тем же
+// правилам, что и при создании через POST /notes; недопустимые заметки и повторы не сохраняются, а отмечаются
+// в отчете. Остальные заметки сохраняются в одной транзакции. Ошибка возвращается, только если файл не удалось
+// прочитать или сохранить заметки.
+func (is *ImportService) Import(ctx context.Context, user *models.User, format string, data []byte) (*models.ImportReport, error) {
+	entries, err := importer.Parse(format, data, is.maxNotes, is.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ImportReport{Items: make([]models.ImportResult, len(entries))}
+	var notes []*models.Note
+	var positions []int
+	seen := make(map[[2]string]bool)
+	now := time.Now()
+	for i, entry := range entries {
+		result := &report.Items[i]
+		result.Index = i + 1
+		result.Name = entry.Name
+
+		switch {
+		case entry.Skip != "":
+			result.Status, result.Message = models.ImportStatusSkipped, entry.Skip
+		case entry.Err != nil:
+			result.Status, result.Message = models.ImportStatusFailed, entry.Err.Error()
+		default:
+			note := entry.Note
+			if err := prepareImportedNote(note); err != nil {
+				result.Status, result.Message = models.ImportStatusFailed, err.Error()
+				continue
+			}
+			key := [2]string{note.Title, note.Text}
+			if seen[key] {
+				result.Status, result.Message = models.ImportStatusSkipped, "повторяет заметку из этого же файла"
+				continue
+			}
+			seen[key] = true
+
+			note.UserID = user.ID
+			note.Author = user.Username
+			// Время создания из будущего заменяется текущим: иначе заметка навсегда оставалась бы первой
+			// в списке новых и не выходила бы из окна редактирования
+			if note.CreatedAt.IsZero() || note.CreatedAt.After(now) {
+				note.CreatedAt = now
+			}
+			notes = append(notes, note)
+			positions = append(positions, i)
+		}
+	}
+
+	ids, err := is.repo.ImportNotes(ctx, notes)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		result := &report.Items[positions[i]]
+		if id == 0 {
+			result.Status, result.Message = models.ImportStatusSkipped, "заметка с таким заголовком и текстом уже есть"
+			continue
+		}
+		result.Status, result.NoteID = models.ImportStatusCreated, id
+	}
+	is.outbox.Notify()
+
+	for _, result := range report.Items {
+		switch result.Status {
+		case models.ImportStatusCreated:
+			report.Created++
+		case models.ImportStatusSkipped:
+			report.Skipped++
+		case models.ImportStatusFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// prepareImportedNote проверяет формат и длину заметки, ее метки, папку и видимость. Пункты списка задач без явного
+// перечисления берутся из текста, а текст списка задач формируется из пунктов, как при создании заметки. Заметка
+// без видимости становится общедоступной.
+func prepareImportedNote(note *models.Note) error {
+	tags, err := normalizeTags(note.Tags)
+	if err != nil {
+		return err
+	}
+	note.Tags = tags
+	if note.Folder, err = normalizeFolder(note.Folder); err != nil {
+		return err
+	}
+	if note.Visibility == "" {
+		note.Visibility = models.NoteVisibilityPublic
+	}
+	if !validVisibility(note.Visibility) {
+		return errInvalidVisibility
+	}
+
+	format, valid := utils.NormalizeNoteFormat(note.Format)
+	if !valid {
+		return errors.New("неизвестный формат заметки")
+	}
+	note.Format = format
+
+	if format != models.NoteFormatChecklist {
+		note.Items = nil
+		if !utils.CheckNoteLength(note.Title, note.Text) {
+			return errors.New("превышена максимальная длина заголовка или текста")
+		}
+		return nil
+	}
+
+	if len(note.Items) == 0 {
+		note.Items = utils.ParseChecklistText(note.Text)
+	}
+	items, text, httpErr := utils.PrepareChecklist(note.Title, note.Items)
+	if httpErr != nil {
+		return errors.New(httpErr.Message)
+	}
+	note.Items, note.Text = items, text
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"note_app/internal/config"
+	"note_app/internal/importer"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"testing"
+	"time"
+)
+
+// fakeImportNoteRepository запоминает сохраненные заметки.
+type fakeImportNoteRepository struct {
+	repository.NoteRepository
+	notes []*models.Note
+}
+
+func (r *fakeImportNoteRepository) ImportNotes(ctx context.Context, notes []*models.Note) ([]int, error) {
+	r.notes = append(r.notes, notes...)
+	ids := make([]int, len(notes))
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	return ids, nil
+}
+
+func TestImportClampsFutureCreatedAt(t *testing.T) {
+	repo := &fakeImportNoteRepository{}
+	outbox := NewOutboxService(nil, nil, config.OutboxConfig{})
+	is := NewImportService(repo, outbox, config.ImportConfig{})
+	data := []byte(`[
+		{"title": "Из будущего", "text": "a", "created_at": "2099-01-01T00:00:00Z"},
+		{"title": "Из прошлого", "text": "b", "created_at": "2020-01-01T00:00:00Z"}
+	]`)
+
+	before := time.Now()
+	report, err := is.Import(context.Background(), &models.User{ID: 1, Username: "alice"}, importer.FormatJSON, data)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if report.Created != 2 || len(repo.notes) != 2 {
+		t.Fatalf("создано %d заметок, сохранено %d; ожидалось 2", report.Created, len(repo.notes))
+	}
+	if created := repo.notes[0].CreatedAt; created.Before(before) || created.After(time.Now()) {
+		t.Errorf("время создания из будущего не заменено временем загрузки: %v", created)
+	}
+	if created := repo.notes[1].CreatedAt; !created.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("время создания из прошлого изменено: %v", created)
+	}
+}
+
+func TestImportTagsFolderVisibility(t *testing.T) {
+	repo := &fakeImportNoteRepository{}
+	outbox := NewOutboxService(nil, nil, config.OutboxConfig{})
+	is := NewImportService(repo, outbox, config.ImportConfig{})
+	data := []byte(`[
+		{"title": "Личная", "text": "a", "tags": [" работа", "работа", "дом"], "folder": " Проекты ", "visibility": "private"},
+		{"title": "Без видимости", "text": "b"},
+		{"title": "Неверная видимость", "text": "c", "visibility": "friends"},
+		{"title": "Пустая метка", "text": "d", "tags": [" "]}
+	]`)
+
+	report, err := is.Import(context.Background(), &models.User{ID: 1, Username: "alice"}, importer.FormatJSON, data)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if report.Created != 2 || report.Failed != 2 {
+		t.Fatalf("создано %d, не загружено %d; ожидалось 2 и 2: %+v", report.Created, report.Failed, report.Items)
+	}
+	note := repo.notes[0]
+	if len(note.Tags) != 2 || note.Tags[0] != "работа" || note.Tags[1] != "дом" {
+		t.Errorf("метки не нормализованы: %q", note.Tags)
+	}
+	if note.Folder != "Проекты" || note.Visibility != models.NoteVisibilityPrivate {
+		t.Errorf("папка %q, видимость %q", note.Folder, note.Visibility)
+	}
+	if visibility := repo.notes[1].Visibility; visibility != models.NoteVisibilityPublic {
+		t.Errorf("заметка без видимости получила видимость %q, ожидалась public", visibility)
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+)
+
+// LinkService предоставляет методы для получения ссылок между заметками.
+type LinkService struct {
+	repo repository.LinkRepository
+}
+
+// NewLinkService создает новый экземпляр LinkService.
+func NewLinkService(repo repository.LinkRepository) *LinkService {
+	return &LinkService{repo: repo}
+}
+
+// GetLinks возвращает исходящие ссылки заметки, включая битые. Ссылка на личную заметку другого пользователя
+// возвращается как битая.
+func (ls *LinkService) GetLinks(ctx context.Context, noteID, viewerID int) ([]models.NoteLink, error) {
+	return ls.repo.GetLinks(ctx, noteID, viewerID)
+}
+
+// GetBacklinks возвращает страницу видимых пользователю viewerID заметок, ссылающихся на заметку.
+func (ls *LinkService) GetBacklinks(ctx context.Context, noteID, viewerID, offset, limit int) ([]models.Backlink, error) {
+	return ls.repo.GetBacklinks(ctx, noteID, viewerID, offset, limit)
+}
@@ -12,28 +12,40 @@ type NoteService interface {
 	GetNoteByID(ctx context.Context, noteID int) (*models.Note, error)
 	UpdateNote(ctx context.Context, noteID int, note *models.Note) error
 	DeleteNote(ctx context.Context, noteID int) error
-	GetNotesByUserID(ctx context.Context, userID, offset, limit int) ([]models.Note, error)
-	GetNotesByDateRange(ctx context.Context, startDate, endDate string, offset, limit int) ([]models.Note, error)
-	GetNotesByDay(ctx context.Context, day string, offset, limit int) ([]models.Note, error)
-	GetNotes(ctx context.Context, offset, limit int) ([]models.Note, error)
-	GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, offset, limit int) ([]models.Note, error)
-	GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, offset, limit int) ([]models.Note, error)
-	GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, offset, limit int) ([]models.Note, error)
+	GetNotesByUserID(ctx context.Context, userID int, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByDateRange(ctx context.Context, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByDay(ctx context.Context, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetFavoriteNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error)
+	GetNoteFlags(ctx context.Context, noteID, userID int) (models.NoteFlags, error)
+	SetNoteFlag(ctx context.Context, noteID, userID int, flag models.NoteFlag, value bool) error
 }
 
 // noteService реализация интерфейса NoteService.
 type noteService struct {
-	repo repository.NoteRepository
+	repo        repository.NoteRepository
+	attachments *AttachmentService
+	outbox      *OutboxService
 }
 
-// NewNoteService создает новый экземпляр NoteService.
-func NewNoteService(repo repository.NoteRepository) NoteService {
-	return &noteService{repo: repo}
+// NewNoteService создает новый экземпляр NoteService. attachments используется для удаления
+// файлов вложений вместе с заметкой. События создания, изменения и удаления заметок записываются
+// репозиторием, outbox уведомляется о них после каждого изменения.
+func NewNoteService(repo repository.NoteRepository, attachments *AttachmentService, outbox *OutboxService) NoteService {
+	return &noteService{repo: repo, attachments: attachments, outbox: outbox}
 }
 
 // AddNote добавляет новую заметку.
 func (ns *noteService) AddNote(ctx context.Context, note *models.Note) (int, error) {
-	return ns.repo.AddNote(ctx, note)
+	id, err := ns.repo.AddNote(ctx, note)
+	if err != nil {
+		return 0, err
+	}
+	ns.outbox.Notify()
+	return id, nil
 }
 
 // GetNoteByID возвращает заметку по её ID.
@@ -43,45 +55,74 @@ func (ns *noteService) GetNoteByID(ctx context.Context, noteID int) (*models.Not
 
 // UpdateNote обновляет заметку.
 func (ns *noteService) UpdateNote(ctx context.Context, noteID int, note *models.Note) error {
-	return ns.repo.UpdateNote(ctx, noteID, note)
+	if err := ns.repo.UpdateNote(ctx, noteID, note); err != nil {
+		return err
+	}
+	ns.outbox.Notify()
+	return nil
 }
 
-// DeleteNote удаляет заметку.
+// DeleteNote удаляет заметку вместе с вложениями. Записи о вложениях удаляются базой данных каскадно,
+// после чего их файлы удаляются из хранилища.
 func (ns *noteService) DeleteNote(ctx context.Context, noteID int) error {
-	return ns.repo.DeleteNote(ctx, noteID)
+	attachments, err := ns.attachments.GetAttachments(ctx, noteID)
+	if err != nil {
+		return err
+	}
+	if err := ns.repo.DeleteNote(ctx, noteID); err != nil {
+		return err
+	}
+	ns.attachments.DeleteBlobs(ctx, attachments)
+	ns.outbox.Notify()
+	return nil
 }
 
 // GetNotesByUserID возвращает заметки пользователя.
-func (ns *noteService) GetNotesByUserID(ctx context.Context, userID, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotesByUserID(ctx, userID, offset, limit)
+func (ns *noteService) GetNotesByUserID(ctx context.Context, userID int, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotesByUserID(ctx, userID, opts, offset, limit)
 }
 
 // GetNotesByDateRange возвращает заметки за определенный период времени.
-func (ns *noteService) GetNotesByDateRange(ctx context.Context, startDate, endDate string, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotesByDateRange(ctx, startDate, endDate, offset, limit)
+func (ns *noteService) GetNotesByDateRange(ctx context.Context, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotesByDateRange(ctx, startDate, endDate, opts, offset, limit)
 }
 
 // GetNotesByDay возвращает заметки за определенный день.
-func (ns *noteService) GetNotesByDay(ctx context.Context, day string, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotesByDay(ctx, day, offset, limit)
+func (ns *noteService) GetNotesByDay(ctx context.Context, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotesByDay(ctx, day, opts, offset, limit)
 }
 
 // GetNotes возвращает все заметки.
-func (ns *noteService) GetNotes(ctx context.Context, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotes(ctx, offset, limit)
+func (ns *noteService) GetNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotes(ctx, opts, offset, limit)
 }
 
 // GetNotesByUserIDAndDateRange возвращает заметки пользователя в определенном диапазоне дат.
-func (ns *noteService) GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotesByUserIDAndDateRange(ctx, userID, startDate, endDate, offset, limit)
+func (ns *noteService) GetNotesByUserIDAndDateRange(ctx context.Context, userID int, startDate, endDate string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotesByUserIDAndDateRange(ctx, userID, startDate, endDate, opts, offset, limit)
 }
 
 // GetNotesByUserIDAndDate возвращает заметки пользователя за определенную дату.
-func (ns *noteService) GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotesByUserIDAndDate(ctx, userID, date, offset, limit)
+func (ns *noteService) GetNotesByUserIDAndDate(ctx context.Context, userID int, date string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotesByUserIDAndDate(ctx, userID, date, opts, offset, limit)
 }
 
 // GetNotesByUserIDAndDay возвращает заметки, созданные указанным пользователем за указанный день.
-func (ns *noteService) GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, offset, limit int) ([]models.Note, error) {
-	return ns.repo.GetNotesByUserIDAndDay(ctx, userID, day, offset, limit)
+func (ns *noteService) GetNotesByUserIDAndDay(ctx context.Context, userID int, day string, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetNotesByUserIDAndDay(ctx, userID, day, opts, offset, limit)
+}
+
+// GetFavoriteNotes возвращает избранные заметки пользователя.
+func (ns *noteService) GetFavoriteNotes(ctx context.Context, opts models.NoteListOptions, offset, limit int) ([]models.Note, error) {
+	return ns.repo.GetFavoriteNotes(ctx, opts, offset, limit)
+}
+
+// GetNoteFlags возвращает отметки пользователя на заметке.
+func (ns *noteService) GetNoteFlags(ctx context.Context, noteID, userID int) (models.NoteFlags, error) {
+	return ns.repo.GetNoteFlags(ctx, noteID, userID)
+}
+
+// SetNoteFlag закрепляет заметку, добавляет ее в избранное или в архив пользователя либо снимает отметку.
+func (ns *noteService) SetNoteFlag(ctx context.Context, noteID, userID int, flag models.NoteFlag, value bool) error {
+	return ns.repo.SetNoteFlag(ctx, noteID, userID, flag, value)
 }
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/oidc"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrOIDCDisabled возвращается, если вход через OpenID Connect выключен в конфигурации.
+	ErrOIDCDisabled = errors.New("вход через OpenID Connect выключен")
+	// ErrIdentityNotLinked возвращается, если внешняя учетная запись не привязана, а автосоздание выключено.
+	ErrIdentityNotLinked = errors.New("внешняя учетная запись не привязана к пользователю")
+	// ErrIdentityLinkedToOtherUser возвращается при попытке привязать учетную запись, уже привязанную к другому пользователю.
+	ErrIdentityLinkedToOtherUser = errors.New("внешняя учетная запись привязана к другому пользователю")
+)
+
+// usernameInvalidChars символы, недопустимые в имени пользователя.
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+// OIDCService предоставляет методы для входа через OpenID Connect провайдера.
+type OIDCService struct {
+	provider           *oidc.Provider
+	cfg                config.OIDCConfig
+	userRepository     UserRepository
+	identityRepository repository.IdentityRepository
+	passwords          *utils.PasswordManager
+}
+
+// NewOIDCService создает новый экземпляр OIDCService.
+func NewOIDCService(cfg config.OIDCConfig, userRepository UserRepository, identityRepository repository.IdentityRepository, passwords *utils.PasswordManager) *OIDCService {
+	return &OIDCService{
+		provider:           oidc.NewProvider(cfg),
+		cfg:                cfg,
+		userRepository:     userRepository,
+		identityRepository: identityRepository,
+		passwords:          passwords,
+	}
+}
+
+// AuthURL возвращает адрес страницы входа провайдера.
+func (s *OIDCService) AuthURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if !s.cfg.Enabled {
+		return "", ErrOIDCDisabled
+	}
+	return s.provider.AuthCodeURL(ctx, state, nonce, verifier)
+}
+
+// Login завершает вход: обменивает код на id_token и находит, привязывает или создает пользователя.
+// Если linkUserID не равен 0, внешняя учетная запись привязывается к этому пользователю.
+func (s *OIDCService) Login(ctx context.Context, code, verifier, nonce string, linkUserID int) (*models.User, error) {
+	if !s.cfg.Enabled {
+		return nil, ErrOIDCDisabled
+	}
+
+	claims, err := s.provider.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identityRepository.GetIdentity(ctx, claims.Issuer, claims.Subject)
+	switch {
+	case err == nil:
+		if linkUserID != 0 && identity.UserID != linkUserID {
+			return nil, ErrIdentityLinkedToOtherUser
+		}
+		return s.userRepository.GetUserByID(identity.UserID)
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	if linkUserID != 0 {
+		return s.link(ctx, linkUserID, claims)
+	}
+
+	// Привязка по email допустима, только если адрес подтвержден и у провайдера, и у нас
+	if s.cfg.LinkByEmail && claims.EmailVerified && claims.Email != "" {
+		if user, err := s.userRepository.GetUserByEmail(claims.Email); err == nil && user.EmailVerified {
+			return s.link(ctx, user.ID, claims)
+		}
+	}
+
+	if !s.cfg.AutoProvision {
+		return nil, ErrIdentityNotLinked
+	}
+	return s.provision(ctx, claims)
+}
+
+// link привязывает внешнюю учетную запись к существующему пользователю.
+func (s *OIDCService) link(ctx context.Context, userID int, claims *oidc.IDTokenClaims) (*models.User, error) {
+	err := s.identityRepository.CreateIdentity(ctx, &models.UserIdentity{
+		UserID:  userID,
+		Issuer:  claims.Issuer,
+		Subject: claims.Subject,
+		Email:   claims.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepository.GetUserByID(userID)
+}
+
+// provision создает нового пользователя для внешней учетной записи.
+// Пароль создается случайным: войти по паролю можно будет только после его сброса.
+func (s *OIDCService) provision(ctx context.Context, claims *oidc.IDTokenClaims) (*models.User, error) {
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := s.passwords.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	// Адрес сохраняем, только если он подтвержден провайдером и еще не занят
+	email := ""
+	if claims.EmailVerified && claims.Email != "" && utils.ValidateEmail(claims.Email) == nil {
+		if _, err := s.userRepository.GetUserByEmail(claims.Email); err != nil {
+			email = claims.Email
+		}
+	}
+
+	base := usernameBase(claims)
+	var user *models.User
+	for attempt := 0; attempt < 5; attempt++ {
+		username := base
+		if attempt > 0 {
+			suffix, err := utils.GenerateRandomToken(2)
+			if err != nil {
+				return nil, err
+			}
+			username = base[:min(len(base), 15)] + "_" + suffix
+		}
+
+		if _, err := s.userRepository.GetUserByUsername(username); err == nil {
+			continue
+		}
+		err := s.userRepository.CreateUser(&models.User{Username: username, Password: passwordHash, Email: email})
+		if err != nil {
+			log.Printf("Не удалось создать пользователя %s: %v", username, err)
+			continue
+		}
+		if user, err = s.userRepository.GetUserByUsername(username); err != nil {
+			return nil, err
+		}
+		break
+	}
+	if user == nil {
+		return nil, fmt.Errorf("не удалось подобрать имя пользователя для %s", claims.Subject)
+	}
+
+	if email != "" {
+		if _, err := s.userRepository.MarkEmailVerified(user.ID, email); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.link(ctx, user.ID, claims)
+}
+
+// usernameBase формирует имя пользователя из утверждений провайдера с учетом ограничений utils.ValidateUser.
+func usernameBase(claims *oidc.IDTokenClaims) string {
+	candidate := claims.PreferredUsername
+	if candidate == "" && claims.Email != "" {
+		candidate = strings.SplitN(claims.Email, "@", 2)[0]
+	}
+	candidate = strings.Trim(usernameInvalidChars.ReplaceAllString(candidate, "_"), "_")
+
+	if len(candidate) > 20 {
+		candidate = candidate[:20]
+	}
+	if len(candidate) < 4 {
+		candidate = "user_" + candidate
+	}
+	return candidate
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/outbox"
+	"note_app/internal/repository"
+	"time"
+)
+
+const (
+	// defaultOutboxInterval период проверки неотправленных событий, если он не задан в конфигурации.
+	defaultOutboxInterval = time.Second
+	// defaultOutboxBatchSize число событий за одну проверку, если оно не задано в конфигурации.
+	defaultOutboxBatchSize = 100
+	// defaultOutboxRetention время хранения отправленных событий, если оно не задано в конфигурации.
+	defaultOutboxRetention = 72 * time.Hour
+	// defaultOutboxMaxAttempts число попыток отправки события, если оно не задано в конфигурации.
+	defaultOutboxMaxAttempts = 10
+	// defaultOutboxBackoff задержка перед первой повторной попыткой, если она не задана в конфигурации.
+	defaultOutboxBackoff = 5 * time.Second
+	// defaultOutboxMaxBackoff максимальная задержка между попытками, если она не задана в конфигурации.
+	defaultOutboxMaxBackoff = 10 * time.Minute
+	// outboxCleanupInterval период удаления устаревших отправленных событий.
+	outboxCleanupInterval = time.Hour
+)
+
+// OutboxService пересылает события заметок из таблицы исходящих событий получателям. События записываются
+// в одной транзакции с изменением заметки, поэтому не теряются, даже если процесс завершится сразу после
+// изменения: они будут отправлены при следующей проверке.
+type OutboxService struct {
+	repo        repository.OutboxRepository
+	sinks       []outbox.Sink
+	interval    time.Duration
+	batchSize   int
+	retention   time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	wake        chan struct{}
+	// delivered получатели, уже принявшие событие, которое отправлено не всем получателям: при повторной
+	// отправке они пропускаются.
+	delivered map[string][]bool
+}
+
+// NewOutboxService создает новый экземпляр OutboxService.
+func NewOutboxService(repo repository.OutboxRepository, sinks []outbox.Sink, cfg config.OutboxConfig) *OutboxService {
+	obs := &OutboxService{
+		repo:        repo,
+		sinks:       sinks,
+		interval:    time.Duration(cfg.Interval) * time.Second,
+		batchSize:   cfg.BatchSize,
+		retention:   time.Duration(cfg.Retention) * time.Hour,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     time.Duration(cfg.Backoff) * time.Second,
+		maxBackoff:  time.Duration(cfg.MaxBackoff) * time.Second,
+		wake:        make(chan struct{}, 1),
+		delivered:   make(map[string][]bool),
+	}
+	if obs.interval <= 0 {
+		obs.interval = defaultOutboxInterval
+	}
+	if obs.batchSize <= 0 {
+		obs.batchSize = defaultOutboxBatchSize
+	}
+	if obs.retention <= 0 {
+		obs.retention = defaultOutboxRetention
+	}
+	if obs.maxAttempts <= 0 {
+		obs.maxAttempts = defaultOutboxMaxAttempts
+	}
+	if obs.backoff <= 0 {
+		obs.backoff = defaultOutboxBackoff
+	}
+	if obs.maxBackoff <= 0 {
+		obs.maxBackoff = defaultOutboxMaxBackoff
+	}
+	return obs
+}
+
+// Notify сообщает, что записаны новые события, чтобы они были отправлены, не дожидаясь следующей проверки.
+func (obs *OutboxService) Notify() {
+	select {
+	case obs.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run пересылает события при вызове Notify и с заданным периодом до отмены ctx.
+func (obs *OutboxService) Run(ctx context.Context) {
+	ticker := time.NewTicker(obs.interval)
+	defer ticker.Stop()
+	var cleanedAt time.Time
+
+	for {
+		obs.RelayPendingEvents(ctx)
+		if time.Since(cleanedAt) >= outboxCleanupInterval {
+			if _, err := obs.repo.DeletePublishedEvents(ctx, time.Now().Add(-obs.retention)); err != nil {
+				log.Printf("Ошибка при удалении отправленных событий заметок: %v", err)
+			}
+			cleanedAt = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-obs.wake:
+		}
+	}
+}
+
+// RelayPendingEvents пересылает неотправленные события. Если событий больше batchSize, они пересылаются
+// несколькими порциями.
+func (obs *OutboxService) RelayPendingEvents(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := obs.repo.ProcessPendingEvents(ctx, obs.batchSize, func(event models.NoteEvent, attempt int) (time.Time, error) {
+			return obs.relay(ctx, event, attempt)
+		})
+		if err != nil {
+			log.Printf("Ошибка при отправке событий заметок: %v", err)
+			return
+		}
+		if published < obs.batchSize {
+			return
+		}
+	}
+}
+
+// relay выполняет попытку attempt отправки события. После неудачной попытки возвращает время следующей,
+// которая назначается с экспоненциально растущей задержкой; после maxAttempts попыток возвращает нулевое время,
+// и событие больше не отправляется.
+func (obs *OutboxService) relay(ctx context.Context, event models.NoteEvent, attempt int) (time.Time, error) {
+	err := obs.publish(ctx, event)
+	if err == nil {
+		return time.Time{}, nil
+	}
+	if attempt >= obs.maxAttempts {
+		log.Printf("Не удалось отправить событие %s заметки %d после %d попыток: %v", event.Type, event.NoteID, attempt, err)
+		delete(obs.delivered, event.ID)
+		return time.Time{}, err
+	}
+	log.Printf("Не удалось отправить событие %s заметки %d: %v", event.Type, event.NoteID, err)
+	return time.Now().Add(retryDelay(obs.backoff, obs.maxBackoff, attempt)), err
+}
+
+// publish передает событие всем получателям, которые его еще не приняли.
+func (obs *OutboxService) publish(ctx context.Context, event models.NoteEvent) error {
+	delivered, ok := obs.delivered[event.ID]
+	if !ok {
+		delivered = make([]bool, len(obs.sinks))
+	}
+
+	var errs []error
+	for i, sink := range obs.sinks {
+		if delivered[i] {
+			continue
+		}
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delivered[i] = true
+	}
+
+	if len(errs) > 0 {
+		obs.delivered[event.ID] = delivered
+		return errors.Join(errs...)
+	}
+	delete(obs.delivered, event.ID)
+	return nil
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/outbox"
+	"testing"
+	"time"
+)
+
+// fakeSink запоминает принятые события и возвращает ошибку, пока задано err.
+type fakeSink struct {
+	received []string
+	err      error
+}
+
+func (s *fakeSink) Publish(ctx context.Context, event models.NoteEvent) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.received = append(s.received, event.ID)
+	return nil
+}
+
+func TestOutboxRelayPartialSinkFailure(t *testing.T) {
+	bus, webhooks := &fakeSink{}, &fakeSink{err: errors.New("очередь недоступна")}
+	obs := NewOutboxService(nil, []outbox.Sink{bus, webhooks}, config.OutboxConfig{MaxAttempts: 3, Backoff: 5, MaxBackoff: 60})
+	event := models.NoteEvent{ID: "1", Type: models.EventNoteCreated, NoteID: 1}
+
+	before := time.Now()
+	retryAt, err := obs.relay(context.Background(), event, 1)
+	if err == nil {
+		t.Fatal("ожидалась ошибка отправки")
+	}
+	if delay := retryAt.Sub(before); delay < 5*time.Second || delay > 6*time.Second {
+		t.Errorf("задержка перед повтором %v, ожидалось 5s", delay)
+	}
+
+	webhooks.err = nil
+	if _, err := obs.relay(context.Background(), event, 2); err != nil {
+		t.Fatalf("повторная отправка: %v", err)
+	}
+	if len(bus.received) != 1 {
+		t.Errorf("получатель, уже принявший событие, получил его %d раз", len(bus.received))
+	}
+	if len(webhooks.received) != 1 {
+		t.Errorf("получатель после ошибки получил событие %d раз, ожидался 1", len(webhooks.received))
+	}
+	if len(obs.delivered) != 0 {
+		t.Errorf("после отправки всем получателям состояние события не удалено: %v", obs.delivered)
+	}
+}
+
+func TestOutboxRelayGivesUpAfterMaxAttempts(t *testing.T) {
+	sink := &fakeSink{err: errors.New("получатель недоступен")}
+	obs := NewOutboxService(nil, []outbox.Sink{&fakeSink{}, sink}, config.OutboxConfig{MaxAttempts: 2})
+	event := models.NoteEvent{ID: "1", NoteID: 1}
+
+	if retryAt, err := obs.relay(context.Background(), event, 1); err == nil || retryAt.IsZero() {
+		t.Fatalf("первая попытка: %v, %v; ожидалась ошибка и время повтора", retryAt, err)
+	}
+	retryAt, err := obs.relay(context.Background(), event, 2)
+	if err == nil || !retryAt.IsZero() {
+		t.Errorf("последняя попытка: %v, %v; ожидалась ошибка без повтора", retryAt, err)
+	}
+	if len(obs.delivered) != 0 {
+		t.Errorf("состояние неудачного события не удалено: %v", obs.delivered)
+	}
+}
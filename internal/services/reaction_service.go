@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+)
+
+var (
+	// ErrUnknownReaction возвращается для типа реакции, которого нет в models.ReactionTypes.
+	ErrUnknownReaction = errors.New("неизвестный тип реакции")
+	// ErrReactionNotFound возвращается при удалении реакции, которую пользователь не ставил.
+	ErrReactionNotFound = errors.New("реакция не найдена")
+)
+
+// ReactionService предоставляет методы для работы с реакциями на заметки.
+type ReactionService struct {
+	repo repository.ReactionRepository
+}
+
+// NewReactionService создает новый экземпляр ReactionService.
+func NewReactionService(repo repository.ReactionRepository) *ReactionService {
+	return &ReactionService{repo: repo}
+}
+
+// AddReaction добавляет реакцию пользователя на заметку. Каждый тип реакции пользователь может поставить один раз.
+func (rs *ReactionService) AddReaction(ctx context.Context, noteID, userID int, reaction string) error {
+	if _, found := models.ReactionTypes[reaction]; !found {
+		return ErrUnknownReaction
+	}
+	return rs.repo.AddReaction(ctx, noteID, userID, reaction)
+}
+
+// RemoveReaction удаляет реакцию пользователя на заметку.
+func (rs *ReactionService) RemoveReaction(ctx context.Context, noteID, userID int, reaction string) error {
+	if _, found := models.ReactionTypes[reaction]; !found {
+		return ErrUnknownReaction
+	}
+	if err := rs.repo.RemoveReaction(ctx, noteID, userID, reaction); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrReactionNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// GetSummary возвращает реакции на заметку с отметкой реакций пользователя userID.
+func (rs *ReactionService) GetSummary(ctx context.Context, noteID, userID int) (models.ReactionSummary, error) {
+	summaries, err := rs.repo.GetReactionSummaries(ctx, []int{noteID}, userID)
+	if err != nil {
+		return models.ReactionSummary{}, err
+	}
+	if summary, found := summaries[noteID]; found {
+		return summary, nil
+	}
+	return models.ReactionSummary{Counts: map[string]int{}, Mine: []string{}}, nil
+}
+
+// FillReactions заполняет реакции у списка заметок одним запросом к базе данных.
+func (rs *ReactionService) FillReactions(ctx context.Context, notes []models.Note, userID int) error {
+	noteIDs := make([]int, len(notes))
+	for i, note := range notes {
+		noteIDs[i] = note.ID
+	}
+	summaries, err := rs.repo.GetReactionSummaries(ctx, noteIDs, userID)
+	if err != nil {
+		return err
+	}
+	for i := range notes {
+		summary, found := summaries[notes[i].ID]
+		if !found {
+			summary = models.ReactionSummary{Counts: map[string]int{}, Mine: []string{}}
+		}
+		notes[i].Reactions = summary.Counts
+		notes[i].MyReactions = summary.Mine
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"log"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/notifier"
+	"note_app/internal/repository"
+	"time"
+)
+
+const (
+	// defaultReminderInterval период проверки напоминаний, если он не задан в конфигурации.
+	defaultReminderInterval = 30 * time.Second
+	// defaultReminderBatchSize число напоминаний за одну проверку, если оно не задано в конфигурации.
+	defaultReminderBatchSize = 100
+	// defaultReminderMaxAttempts число попыток отправки напоминания, если оно не задано в конфигурации.
+	defaultReminderMaxAttempts = 5
+	// defaultReminderBackoff задержка перед первой повторной попыткой, если она не задана в конфигурации.
+	defaultReminderBackoff = time.Minute
+	// defaultReminderMaxBackoff максимальная задержка между попытками, если она не задана в конфигурации.
+	defaultReminderMaxBackoff = time.Hour
+	// reminderLease время, на которое занимается порция напоминаний. Оно должно превышать время отправки всей
+	// порции, иначе напоминание может взять другой экземпляр приложения и отправить его повторно.
+	reminderLease = 30 * time.Minute
+)
+
+// ReminderService периодически отправляет наступившие напоминания о заметках.
+type ReminderService struct {
+	repo        repository.ReminderRepository
+	notifier    notifier.Notifier
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+}
+
+// NewReminderService создает новый экземпляр ReminderService.
+func NewReminderService(repo repository.ReminderRepository, n notifier.Notifier, cfg config.RemindersConfig) *ReminderService {
+	rs := &ReminderService{
+		repo:        repo,
+		notifier:    n,
+		interval:    time.Duration(cfg.Interval) * time.Second,
+		batchSize:   cfg.BatchSize,
+		maxAttempts: cfg.MaxAttempts,
+		backoff:     time.Duration(cfg.Backoff) * time.Second,
+		maxBackoff:  time.Duration(cfg.MaxBackoff) * time.Second,
+	}
+	if rs.interval <= 0 {
+		rs.interval = defaultReminderInterval
+	}
+	if rs.batchSize <= 0 {
+		rs.batchSize = defaultReminderBatchSize
+	}
+	if rs.maxAttempts <= 0 {
+		rs.maxAttempts = defaultReminderMaxAttempts
+	}
+	if rs.backoff <= 0 {
+		rs.backoff = defaultReminderBackoff
+	}
+	if rs.maxBackoff <= 0 {
+		rs.maxBackoff = defaultReminderMaxBackoff
+	}
+	return rs
+}
+
+// Run проверяет напоминания с заданным периодом до отмены ctx.
+func (rs *ReminderService) Run(ctx context.Context) {
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+
+	for {
+		rs.SendDueReminders(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDueReminders отправляет наступившие напоминания. Если напоминаний больше batchSize,
+// они отправляются несколькими порциями.
+func (rs *ReminderService) SendDueReminders(ctx context.Context) {
+	for ctx.Err() == nil {
+		reminders, err := rs.repo.ClaimDueReminders(ctx, rs.batchSize, rs.maxAttempts, reminderLease)
+		if err != nil {
+			log.Printf("Ошибка при отправке напоминаний: %v", err)
+			return
+		}
+		for _, reminder := range reminders {
+			rs.send(ctx, reminder)
+		}
+		if len(reminders) < rs.batchSize {
+			return
+		}
+	}
+}
+
+// send отправляет занятое напоминание и сохраняет результат. После неудачной попытки следующая назначается
+// с экспоненциально растущей задержкой; после maxAttempts попыток напоминание больше не отправляется.
+func (rs *ReminderService) send(ctx context.Context, reminder models.Reminder) {
+	if err := rs.notifier.Notify(ctx, reminder); err != nil {
+		if reminder.Attempts >= rs.maxAttempts {
+			log.Printf("Не удалось отправить напоминание о заметке %d после %d попыток: %v", reminder.NoteID, reminder.Attempts, err)
+		} else {
+			log.Printf("Не удалось отправить напоминание о заметке %d: %v", reminder.NoteID, err)
+		}
+		retryAt := time.Now().Add(retryDelay(rs.backoff, rs.maxBackoff, reminder.Attempts))
+		if err := rs.repo.MarkReminderFailed(ctx, reminder, retryAt); err != nil {
+			log.Printf("Ошибка при отправке напоминаний: %v", err)
+		}
+		return
+	}
+	if err := rs.repo.MarkReminderSent(ctx, reminder); err != nil {
+		log.Printf("Ошибка при отправке напоминаний: %v", err)
+	}
+}
+
+// retryDelay возвращает задержку перед попыткой, следующей за попыткой attempts: backoff после первой попытки,
+// затем вдвое больше после каждой следующей, но не более maxBackoff.
+func retryDelay(backoff, maxBackoff time.Duration, attempts int) time.Duration {
+	delay := backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
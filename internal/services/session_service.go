@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"time"
+)
+
+const (
+	// maxUserAgentLength максимальная длина сохраняемого заголовка User-Agent в символах.
+	maxUserAgentLength = 512
+)
+
+var (
+	// ErrSessionNotFound возвращается, если сессия не найдена или уже отозвана.
+	ErrSessionNotFound = errors.New("сессия не найдена")
+	// ErrSessionRevoked возвращается для токена, сессия которого отозвана, истекла или не существует.
+	ErrSessionRevoked = errors.New("сессия отозвана")
+)
+
+// SessionService предоставляет методы для работы с сессиями пользователей.
+type SessionService struct {
+	repo repository.SessionRepository
+}
+
+// NewSessionService создает новый экземпляр SessionService.
+func NewSessionService(repo repository.SessionRepository) *SessionService {
+	return &SessionService{repo: repo}
+}
+
+// StartSession сохраняет сессию для выданного при входе токена.
+func (ss *SessionService) StartSession(ctx context.Context, userID int, tokenID string, expiresAt time.Time, userAgent, ip string) (*models.Session, error) {
+	userAgent = utils.TruncateString(userAgent, maxUserAgentLength)
+	session := models.Session{
+		UserID:    userID,
+		TokenID:   tokenID,
+		Device:    utils.DescribeDevice(userAgent),
+		UserAgent: userAgent,
+		IP:        ip,
+		ExpiresAt: expiresAt,
+	}
+	if err := ss.repo.CreateSession(ctx, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ValidateSession проверяет, что сессия токена существует и не отозвана, и отмечает время активности.
+func (ss *SessionService) ValidateSession(ctx context.Context, tokenID string) error {
+	session, err := ss.repo.GetSessionByTokenID(ctx, tokenID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+
+	if err := ss.repo.TouchSession(ctx, session.ID); err != nil {
+		log.Printf("Ошибка при обновлении времени активности сессии: %v", err)
+	}
+	return nil
+}
+
+// GetSessions возвращает активные сессии пользователя и отмечает сессию с идентификатором токена currentTokenID.
+func (ss *SessionService) GetSessions(ctx context.Context, userID int, currentTokenID string) ([]models.Session, error) {
+	sessions, err := ss.repo.GetActiveSessionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].TokenID == currentTokenID
+	}
+	return sessions, nil
+}
+
+// RevokeSession отзывает сессию пользователя. Токен этой сессии перестает приниматься сразу.
+func (ss *SessionService) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	err := ss.repo.RevokeSession(ctx, userID, sessionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSessionNotFound
+	}
+	return err
+}
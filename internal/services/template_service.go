@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTemplateNameLength максимальная длина имени шаблона в символах.
+const maxTemplateNameLength = 100
+
+var (
+	// ErrTemplateNotFound возвращается, если шаблон не найден или недоступен пользователю.
+	ErrTemplateNotFound = errors.New("шаблон не найден")
+	// ErrTemplateForbidden возвращается, если у пользователя нет прав на изменение шаблона.
+	ErrTemplateForbidden = errors.New("нет прав на шаблон")
+	// ErrInvalidTemplate возвращается для шаблона с недопустимыми данными.
+	ErrInvalidTemplate = errors.New("недопустимый шаблон")
+)
+
+// TemplateService предоставляет методы для работы с шаблонами заметок.
+type TemplateService struct {
+	repo repository.TemplateRepository
+}
+
+// NewTemplateService создает новый экземпляр TemplateService.
+func NewTemplateService(repo repository.TemplateRepository) *TemplateService {
+	return &TemplateService{repo: repo}
+}
+
+// CreateTemplate создает шаблон пользователя или, если его создает администратор с признаком Global, общий шаблон.
+func (ts *TemplateService) CreateTemplate(ctx context.Context, user *models.User, input models.TemplateInput) (*models.Template, error) {
+	if input.Global && user.Role != models.RoleAdmin {
+		return nil, ErrTemplateForbidden
+	}
+	template, err := newTemplate(input)
+	if err != nil {
+		return nil, err
+	}
+	template.UserID = user.ID
+	template.Global = input.Global
+	if err := ts.repo.CreateTemplate(ctx, template); err != nil {
+		return nil, err
+	}
+	if template.Global {
+		template.UserID = 0
+	}
+	return template, nil
+}
+
+// GetTemplates возвращает шаблоны пользователя и общие шаблоны.
+func (ts *TemplateService) GetTemplates(ctx context.Context, userID int) ([]models.Template, error) {
+	return ts.repo.GetTemplatesForUser(ctx, userID)
+}
+
+// GetTemplate возвращает шаблон, если он принадлежит пользователю или является общим.
+func (ts *TemplateService) GetTemplate(ctx context.Context, templateID, userID int) (*models.Template, error) {
+	template, err := ts.repo.GetTemplate(ctx, templateID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, fmt.Errorf("не удалось получить шаблон: %v", err)
+	}
+	// Чужие личные шаблоны не раскрываются даже фактом существования
+	if !template.Global && template.UserID != userID {
+		return nil, ErrTemplateNotFound
+	}
+	return template, nil
+}
+
+// UpdateTemplate изменяет шаблон. Личный шаблон изменяет его владелец, общий — администратор.
+// Шаблон не может стать общим или личным после создания.
+func (ts *TemplateService) UpdateTemplate(ctx context.Context, templateID int, user *models.User, input models.TemplateInput) (*models.Template, error) {
+	template, err := ts.editableTemplate(ctx, templateID, user)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := newTemplate(input)
+	if err != nil {
+		return nil, err
+	}
+	template.Name, template.Title, template.Text, template.Format = updated.Name, updated.Title, updated.Text, updated.Format
+	if err := ts.repo.UpdateTemplate(ctx, template); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTemplateNotFound
+		}
+		return nil, err
+	}
+	return ts.GetTemplate(ctx, templateID, user.ID)
+}
+
+// DeleteTemplate удаляет шаблон. Личный шаблон удаляет его владелец, общий — администратор.
+func (ts *TemplateService) DeleteTemplate(ctx context.Context, templateID int, user *models.User) error {
+	if _, err := ts.editableTemplate(ctx, templateID, user); err != nil {
+		return err
+	}
+	if err := ts.repo.DeleteTemplate(ctx, templateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTemplateNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// Instantiate возвращает заметку по шаблону: в заголовке и тексте заменяются встроенные подстановки
+// date, time, datetime и username, а также подстановки из vars. Заметка не сохраняется.
+func (ts *TemplateService) Instantiate(ctx context.Context, templateID int, user *models.User, vars map[string]string) (*models.Note, error) {
+	template, err := ts.GetTemplate(ctx, templateID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	values := map[string]string{
+		"date":     now.Format("2006-01-02"),
+		"time":     now.Format("15:04"),
+		"datetime": now.Format("2006-01-02 15:04"),
+		"username": user.Username,
+	}
+	for name, value := range vars {
+		values[name] = value
+	}
+
+	note := &models.Note{
+		Title:  utils.ExpandTemplate(template.Title, values),
+		Text:   utils.ExpandTemplate(template.Text, values),
+		Format: template.Format,
+	}
+	if !utils.CheckNoteLength(note.Title, note.Text) {
+		return nil, fmt.Errorf("%w: заметка по шаблону превышает максимальную длину заголовка или текста", ErrInvalidTemplate)
+	}
+	return note, nil
+}
+
+// editableTemplate возвращает шаблон, если пользователь может его изменять.
+func (ts *TemplateService) editableTemplate(ctx context.Context, templateID int, user *models.User) (*models.Template, error) {
+	template, err := ts.GetTemplate(ctx, templateID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if template.Global && user.Role != models.RoleAdmin {
+		return nil, ErrTemplateForbidden
+	}
+	return template, nil
+}
+
+// newTemplate проверяет данные шаблона и возвращает шаблон без владельца.
+func newTemplate(input models.TemplateInput) (*models.Template, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxTemplateNameLength {
+		return nil, fmt.Errorf("%w: имя шаблона должно содержать от 1 до %d символов", ErrInvalidTemplate, maxTemplateNameLength)
+	}
+	if !utils.CheckNoteLength(input.Title, input.Text) {
+		return nil, fmt.Errorf("%w: превышена максимальная длина заголовка или текста", ErrInvalidTemplate)
+	}
+	format, valid := utils.NormalizeNoteFormat(input.Format)
+	if !valid || format == models.NoteFormatChecklist {
+		return nil, fmt.Errorf("%w: допустимые форматы шаблона: plain, markdown", ErrInvalidTemplate)
+	}
+	return &models.Template{Name: name, Title: input.Title, Text: input.Text, Format: format}, nil
+}
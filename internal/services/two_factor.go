@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"note_app/internal/models"
+	"note_app/pkg/utils"
+	"time"
+)
+
+const (
+	// recoveryCodesCount количество кодов восстановления, выдаваемых при подключении 2FA.
+	recoveryCodesCount = 10
+	// defaultMaxTOTPAttempts число попыток ввода кода 2FA подряд без успешной, если оно не задано в конфигурации.
+	defaultMaxTOTPAttempts = 5
+	// defaultTOTPLockout время блокировки ввода кода 2FA, если оно не задано в конфигурации.
+	defaultTOTPLockout = 15 * time.Minute
+)
+
+var (
+	// ErrTOTPAlreadyEnabled возвращается при попытке повторно подключить 2FA.
+	ErrTOTPAlreadyEnabled = errors.New("двухфакторная аутентификация уже включена")
+	// ErrTOTPNotEnrolled возвращается, если подключение 2FA не было начато или 2FA выключена.
+	ErrTOTPNotEnrolled = errors.New("двухфакторная аутентификация не подключена")
+	// ErrInvalidTwoFactorCode возвращается при неверном, просроченном или повторно использованном коде.
+	ErrInvalidTwoFactorCode = errors.New("неверный код подтверждения")
+	// ErrTooManyTwoFactorAttempts возвращается, если исчерпаны попытки ввода кода пользователем или с токеном
+	// второго шага входа.
+	ErrTooManyTwoFactorAttempts = errors.New("слишком много попыток ввода кода подтверждения")
+	// ErrTwoFactorChallengeUsed возвращается, если токен второго шага входа уже использован для входа.
+	ErrTwoFactorChallengeUsed = errors.New("токен входа уже использован")
+)
+
+// BeginTOTPEnrollment генерирует новый секрет TOTP и возвращает данные для приложения-аутентификатора.
+// 2FA включается только после подтверждения кодом в ConfirmTOTPEnrollment.
+func (us *UserService) BeginTOTPEnrollment(userID int, issuer string) (*models.TOTPEnrollment, error) {
+	user, err := us.userRepository.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := us.userRepository.SetTOTPSecret(userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPEnrollment{
+		Secret: secret,
+		URI:    utils.TOTPURI(issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment проверяет первый код из приложения, включает 2FA и возвращает коды восстановления.
+// Коды восстановления возвращаются один раз, в базе данных хранятся только их хэши.
+func (us *UserService) ConfirmTOTPEnrollment(userID int, code string) ([]string, error) {
+	user, err := us.userRepository.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotEnrolled
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodesCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, c := range codes {
+		hashes = append(hashes, utils.HashRecoveryCode(c))
+	}
+
+	if err := us.userRepository.EnableTOTP(userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP выключает 2FA после проверки текущего кода TOTP или кода восстановления.
+func (us *UserService) DisableTOTP(userID int, code, recoveryCode string) error {
+	if err := us.VerifySecondFactor(userID, code, recoveryCode); err != nil {
+		return err
+	}
+	return us.userRepository.DisableTOTP(userID)
+}
+
+// VerifySignInChallenge проверяет второй фактор при входе с токеном второго шага challenge. С одним токеном
+// допускается не больше настроенного числа попыток, после чего он становится недействительным. Токен, с которым
+// вход выполнен, нельзя использовать повторно.
+func (us *UserService) VerifySignInChallenge(challenge *models.Claims, code, recoveryCode string) error {
+	attempts, used, err := us.userRepository.BeginChallengeAttempt(challenge.ID, challenge.UserID, challenge.ExpiresAt.Time)
+	if err != nil {
+		return err
+	}
+	if used {
+		return ErrTwoFactorChallengeUsed
+	}
+	if attempts > us.maxTOTPAttempts {
+		return ErrTooManyTwoFactorAttempts
+	}
+
+	if err := us.VerifySecondFactor(challenge.UserID, code, recoveryCode); err != nil {
+		return err
+	}
+	used, err = us.userRepository.UseChallenge(challenge.ID)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrTwoFactorChallengeUsed
+	}
+	return nil
+}
+
+// VerifySecondFactor проверяет второй фактор пользователя: код TOTP либо одноразовый код восстановления.
+// Успешно проверенный код не может быть использован повторно. После настроенного числа попыток подряд без
+// успешной ввод кода блокируется на время блокировки, и до ее окончания возвращается ErrTooManyTwoFactorAttempts.
+func (us *UserService) VerifySecondFactor(userID int, code, recoveryCode string) error {
+	user, err := us.userRepository.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return ErrTOTPNotEnrolled
+	}
+
+	allowed, err := us.userRepository.BeginTOTPAttempt(userID, us.maxTOTPAttempts, us.totpLockout)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return ErrTooManyTwoFactorAttempts
+	}
+	if err := us.checkSecondFactor(userID, user.TOTPSecret, code, recoveryCode); err != nil {
+		return err
+	}
+	return us.userRepository.ResetTOTPAttempts(userID)
+}
+
+// checkSecondFactor проверяет код TOTP с секретом secret или код восстановления пользователя и отмечает его
+// использованным.
+func (us *UserService) checkSecondFactor(userID int, secret, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		used, err := us.userRepository.UseRecoveryCode(userID, utils.HashRecoveryCode(recoveryCode))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+	used, err := us.userRepository.UseTOTPStep(userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
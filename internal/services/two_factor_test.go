@@ -0,0 +1,179 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"testing"
+	"time"
+)
+
+// fakeTOTPUserRepository хранит последний использованный шаг TOTP пользователя, попытки ввода кода и попытки
+// с токенами второго шага входа в памяти.
+type fakeTOTPUserRepository struct {
+	UserRepository
+	user        models.User
+	lastStep    int64
+	attempts    int
+	lockedUntil time.Time
+	challenges  map[string]*fakeChallenge
+}
+
+// fakeChallenge попытки входа с токеном второго шага.
+type fakeChallenge struct {
+	attempts int
+	used     bool
+}
+
+func (r *fakeTOTPUserRepository) BeginTOTPAttempt(userID, maxAttempts int, lockout time.Duration) (bool, error) {
+	if !r.lockedUntil.IsZero() {
+		if time.Now().Before(r.lockedUntil) {
+			return false, nil
+		}
+		r.attempts, r.lockedUntil = 0, time.Time{}
+	}
+	r.attempts++
+	if r.attempts >= maxAttempts {
+		r.lockedUntil = time.Now().Add(lockout)
+	}
+	return true, nil
+}
+
+func (r *fakeTOTPUserRepository) ResetTOTPAttempts(userID int) error {
+	r.attempts, r.lockedUntil = 0, time.Time{}
+	return nil
+}
+
+func (r *fakeTOTPUserRepository) BeginChallengeAttempt(tokenID string, userID int, expiresAt time.Time) (int, bool, error) {
+	if r.challenges == nil {
+		r.challenges = make(map[string]*fakeChallenge)
+	}
+	challenge := r.challenges[tokenID]
+	if challenge == nil {
+		challenge = &fakeChallenge{}
+		r.challenges[tokenID] = challenge
+	}
+	if !challenge.used {
+		challenge.attempts++
+	}
+	return challenge.attempts, challenge.used, nil
+}
+
+func (r *fakeTOTPUserRepository) UseChallenge(tokenID string) (bool, error) {
+	challenge := r.challenges[tokenID]
+	if challenge == nil || challenge.used {
+		return false, nil
+	}
+	challenge.used = true
+	return true, nil
+}
+
+func (r *fakeTOTPUserRepository) GetUserByID(userID int) (*models.User, error) {
+	user := r.user
+	return &user, nil
+}
+
+func (r *fakeTOTPUserRepository) UseTOTPStep(userID int, step int64) (bool, error) {
+	if step <= r.lastStep {
+		return false, nil
+	}
+	r.lastStep = step
+	return true, nil
+}
+
+// totpCode вычисляет код TOTP шага step по RFC 6238.
+func totpCode(t *testing.T, secret string, step int64) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestVerifySecondFactorRejectsReplay(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	repo := &fakeTOTPUserRepository{user: models.User{ID: 1, TOTPEnabled: true, TOTPSecret: secret}}
+	us := NewUserService(repo, nil, config.TwoFactorConfig{})
+	step := time.Now().Unix() / 30
+
+	if err := us.VerifySecondFactor(1, totpCode(t, secret, step), ""); err != nil {
+		t.Fatalf("первое использование кода: %v", err)
+	}
+	if err := us.VerifySecondFactor(1, totpCode(t, secret, step), ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("повторное использование кода: ожидалась ErrInvalidTwoFactorCode, получено %v", err)
+	}
+	// Код предыдущего шага еще в допустимом отклонении, но старше использованного
+	if err := us.VerifySecondFactor(1, totpCode(t, secret, step-1), ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Errorf("код более раннего шага: ожидалась ErrInvalidTwoFactorCode, получено %v", err)
+	}
+	if err := us.VerifySecondFactor(1, totpCode(t, secret, step+1), ""); err != nil {
+		t.Errorf("код следующего шага: %v", err)
+	}
+}
+
+func TestVerifySecondFactorLockout(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	repo := &fakeTOTPUserRepository{user: models.User{ID: 1, TOTPEnabled: true, TOTPSecret: secret}}
+	us := NewUserService(repo, nil, config.TwoFactorConfig{MaxAttempts: 3, Lockout: 60})
+	step := time.Now().Unix() / 30
+
+	for i := 0; i < 3; i++ {
+		if err := us.VerifySecondFactor(1, "abcdef", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+			t.Fatalf("попытка %d: ожидалась ErrInvalidTwoFactorCode, получено %v", i+1, err)
+		}
+	}
+	// Верный код не принимается до окончания блокировки
+	if err := us.VerifySecondFactor(1, totpCode(t, secret, step), ""); !errors.Is(err, ErrTooManyTwoFactorAttempts) {
+		t.Fatalf("ожидалась ErrTooManyTwoFactorAttempts, получено %v", err)
+	}
+
+	repo.lockedUntil = time.Now().Add(-time.Second)
+	if err := us.VerifySecondFactor(1, totpCode(t, secret, step), ""); err != nil {
+		t.Fatalf("после окончания блокировки: %v", err)
+	}
+	if repo.attempts != 0 {
+		t.Errorf("успешная попытка не сбросила счетчик: %d", repo.attempts)
+	}
+}
+
+func TestVerifySignInChallengeLimit(t *testing.T) {
+	const secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	repo := &fakeTOTPUserRepository{user: models.User{ID: 1, TOTPEnabled: true, TOTPSecret: secret}}
+	us := NewUserService(repo, nil, config.TwoFactorConfig{MaxAttempts: 2, Lockout: 60})
+	step := time.Now().Unix() / 30
+	challenge := &models.Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{ID: "jti", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+
+	if err := us.VerifySignInChallenge(challenge, "abcdef", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("ожидалась ErrInvalidTwoFactorCode, получено %v", err)
+	}
+	// Блокировка пользователя снята, но токен второго шага исчерпал попытки
+	repo.ResetTOTPAttempts(1)
+	if err := us.VerifySignInChallenge(challenge, "abcdef", ""); !errors.Is(err, ErrInvalidTwoFactorCode) {
+		t.Fatalf("ожидалась ErrInvalidTwoFactorCode, получено %v", err)
+	}
+	repo.ResetTOTPAttempts(1)
+	if err := us.VerifySignInChallenge(challenge, totpCode(t, secret, step), ""); !errors.Is(err, ErrTooManyTwoFactorAttempts) {
+		t.Fatalf("ожидалась ErrTooManyTwoFactorAttempts, получено %v", err)
+	}
+
+	fresh := &models.Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{ID: "jti-2", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+	if err := us.VerifySignInChallenge(fresh, totpCode(t, secret, step), ""); err != nil {
+		t.Fatalf("вход с новым токеном: %v", err)
+	}
+	if err := us.VerifySignInChallenge(fresh, totpCode(t, secret, step+1), ""); !errors.Is(err, ErrTwoFactorChallengeUsed) {
+		t.Errorf("повторный вход с тем же токеном: ожидалась ErrTwoFactorChallengeUsed, получено %v", err)
+	}
+}
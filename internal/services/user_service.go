@@ -1,22 +1,92 @@
 package services
 
-import "note_app/internal/models"
+import (
+	"log"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/pkg/utils"
+	"time"
+)
 
 // UserRepository интерфейс для работы с пользователями
 type UserRepository interface {
 	CreateUser(user *models.User) error
 	GetUserByID(userID int) (*models.User, error)
 	GetUserByUsername(username string) (*models.User, error)
+	SetTOTPSecret(userID int, secret string) error
+	EnableTOTP(userID int, step int64, recoveryCodeHashes []string) error
+	DisableTOTP(userID int) error
+	UseTOTPStep(userID int, step int64) (bool, error)
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	BeginTOTPAttempt(userID, maxAttempts int, lockout time.Duration) (bool, error)
+	ResetTOTPAttempts(userID int) error
+	BeginChallengeAttempt(tokenID string, userID int, expiresAt time.Time) (int, bool, error)
+	UseChallenge(tokenID string) (bool, error)
+	GetUserByEmail(email string) (*models.User, error)
+	SetEmail(userID int, email string) error
+	MarkEmailVerified(userID int, email string) (bool, error)
+	UpdatePassword(userID int, passwordHash string) error
+	ResetPassword(tokenHash, passwordHash string) error
+	ListUsers(search string, offset, limit int) ([]models.User, error)
+	SetDisabled(userID int, disabled bool) error
+	SetRole(userID int, role string) error
+	SetRoleByUsername(username, role string) error
 }
 
 // UserService реализация интерфейса UserRepository
 type UserService struct {
-	userRepository UserRepository
+	userRepository  UserRepository
+	passwords       *utils.PasswordManager
+	maxTOTPAttempts int
+	totpLockout     time.Duration
 }
 
-// NewUserService создает новый экземпляр UserService
-func NewUserService(userRepository UserRepository) *UserService {
-	return &UserService{userRepository: userRepository}
+// NewUserService создает новый экземпляр UserService. twoFactor задает ограничение попыток ввода кода 2FA
+func NewUserService(userRepository UserRepository, passwords *utils.PasswordManager, twoFactor config.TwoFactorConfig) *UserService {
+	us := &UserService{
+		userRepository:  userRepository,
+		passwords:       passwords,
+		maxTOTPAttempts: twoFactor.MaxAttempts,
+		totpLockout:     time.Duration(twoFactor.Lockout) * time.Second,
+	}
+	if us.maxTOTPAttempts <= 0 {
+		us.maxTOTPAttempts = defaultMaxTOTPAttempts
+	}
+	if us.totpLockout <= 0 {
+		us.totpLockout = defaultTOTPLockout
+	}
+	return us
+}
+
+// ValidateUser проверяет данные нового пользователя, в том числе пароль по настроенным требованиям
+func (us *UserService) ValidateUser(user *models.User) *utils.HTTPError {
+	return utils.ValidateUser(user, us.passwords)
+}
+
+// HashPassword хэширует пароль настроенным алгоритмом
+func (us *UserService) HashPassword(password string) (string, error) {
+	return us.passwords.Hash(password)
+}
+
+// CheckPassword сверяет пароль пользователя. Если хэш создан устаревшим алгоритмом или с другими параметрами,
+// пароль перехэшируется с текущими настройками.
+func (us *UserService) CheckPassword(user *models.User, password string) bool {
+	ok, needsRehash := us.passwords.Verify(user.Password, password)
+	if !ok || !needsRehash {
+		return ok
+	}
+
+	hash, err := us.passwords.Hash(password)
+	if err != nil {
+		log.Printf("Ошибка при перехэшировании пароля: %v", err)
+		return true
+	}
+	if err := us.userRepository.UpdatePassword(user.ID, hash); err != nil {
+		log.Printf("Ошибка при сохранении нового хэша пароля: %v", err)
+		return true
+	}
+	user.Password = hash
+	return true
 }
 
 // CreateUser создает нового пользователя
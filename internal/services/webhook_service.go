@@ -0,0 +1,401 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"note_app/pkg/utils"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	// webhookSecretSize размер ключа подписи веб-хука в байтах.
+	webhookSecretSize = 32
+	// maxWebhookURLLength максимальная длина адреса веб-хука.
+	maxWebhookURLLength = 2048
+	// maxWebhookErrorLength максимальная длина сохраняемой ошибки доставки в символах.
+	maxWebhookErrorLength = 500
+
+	// defaultWebhookInterval период проверки очереди доставок, если он не задан в конфигурации.
+	defaultWebhookInterval = 5 * time.Second
+	// defaultWebhookBatchSize число доставок за одну проверку, если оно не задано в конфигурации.
+	defaultWebhookBatchSize = 100
+	// defaultWebhookMaxAttempts число попыток доставки, если оно не задано в конфигурации.
+	defaultWebhookMaxAttempts = 8
+	// defaultWebhookBackoff задержка перед первой повторной попыткой, если она не задана в конфигурации.
+	defaultWebhookBackoff = 30 * time.Second
+	// defaultWebhookMaxBackoff максимальная задержка между попытками, если она не задана в конфигурации.
+	defaultWebhookMaxBackoff = time.Hour
+	// defaultWebhookTimeout время ожидания ответа, если оно не задано в конфигурации.
+	defaultWebhookTimeout = 10 * time.Second
+)
+
+var (
+	// ErrInvalidWebhook возвращается для неверного адреса, адреса во внутренней сети или неизвестного типа события.
+	ErrInvalidWebhook = errors.New("неверные параметры веб-хука")
+	// ErrWebhookNotFound возвращается, если веб-хук не найден или принадлежит другому пользователю.
+	ErrWebhookNotFound = errors.New("веб-хук не найден")
+	// ErrWebhookDeliveryNotFound возвращается, если доставка не найдена.
+	ErrWebhookDeliveryNotFound = errors.New("доставка не найдена")
+	// errWebhookAddressForbidden возвращается при подключении к адресу во внутренней сети.
+	errWebhookAddressForbidden = errors.New("адрес получателя находится во внутренней сети")
+)
+
+// sharedAddressSpace диапазон адресов 100.64.0.0/10 (RFC 6598), используемый внутри сетей провайдеров.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// webhookEvents типы событий, на которые можно подписать веб-хук.
+var webhookEvents = map[string]bool{
+	models.EventNoteCreated: true,
+	models.EventNoteUpdated: true,
+	models.EventNoteDeleted: true,
+}
+
+// WebhookService управляет веб-хуками пользователей и доставляет на них события заметок.
+type WebhookService struct {
+	repo        repository.WebhookRepository
+	client      *http.Client
+	resolver    *net.Resolver
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	lease       time.Duration
+	// allowPrivate разрешает адреса во внутренней сети.
+	allowPrivate bool
+}
+
+// NewWebhookService создает новый экземпляр WebhookService. События заметок ставятся в очередь доставки
+// получателем outbox.WebhookSink.
+//
+// Если cfg.AllowPrivateNetworks не задан, веб-хуки не могут указывать на адреса во внутренней сети: адрес
+// проверяется при сохранении веб-хука и еще раз при подключении, потому что DNS-имя может начать указывать
+// на другой адрес. Перенаправления не выполняются, прокси из окружения не используется.
+func NewWebhookService(repo repository.WebhookRepository, cfg config.WebhooksConfig) *WebhookService {
+	ws := &WebhookService{
+		repo:         repo,
+		resolver:     net.DefaultResolver,
+		interval:     time.Duration(cfg.Interval) * time.Second,
+		batchSize:    cfg.BatchSize,
+		maxAttempts:  cfg.MaxAttempts,
+		backoff:      time.Duration(cfg.Backoff) * time.Second,
+		maxBackoff:   time.Duration(cfg.MaxBackoff) * time.Second,
+		allowPrivate: cfg.AllowPrivateNetworks,
+	}
+	if ws.interval <= 0 {
+		ws.interval = defaultWebhookInterval
+	}
+	if ws.batchSize <= 0 {
+		ws.batchSize = defaultWebhookBatchSize
+	}
+	if ws.maxAttempts <= 0 {
+		ws.maxAttempts = defaultWebhookMaxAttempts
+	}
+	if ws.backoff <= 0 {
+		ws.backoff = defaultWebhookBackoff
+	}
+	if ws.maxBackoff <= 0 {
+		ws.maxBackoff = defaultWebhookMaxBackoff
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultWebhookTimeout
+	}
+	// Порция доставок отправляется последовательно, поэтому она занимается на время отправки всей порции
+	ws.lease = time.Duration(ws.batchSize)*timeout + time.Minute
+
+	dialer := &net.Dialer{Timeout: timeout, Control: ws.checkDialAddress}
+	ws.client = &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		// Ответ с перенаправлением считается неудачной попыткой
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return ws
+}
+
+// Create создает веб-хук пользователя. Ключ подписи возвращается только один раз.
+func (ws *WebhookService) Create(ctx context.Context, userID int, input models.WebhookInput) (*models.CreatedWebhook, error) {
+	webhook := models.Webhook{UserID: userID, Active: true}
+	if err := ws.applyWebhookInput(ctx, &webhook, input); err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateRandomToken(webhookSecretSize)
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	if err := ws.repo.CreateWebhook(ctx, &webhook); err != nil {
+		return nil, err
+	}
+	return &models.CreatedWebhook{Webhook: webhook, Secret: secret}, nil
+}
+
+// GetWebhooks возвращает веб-хуки пользователя.
+func (ws *WebhookService) GetWebhooks(ctx context.Context, userID int) ([]models.Webhook, error) {
+	return ws.repo.GetWebhooksByUserID(ctx, userID)
+}
+
+// Get возвращает веб-хук пользователя.
+func (ws *WebhookService) Get(ctx context.Context, userID, webhookID int) (*models.Webhook, error) {
+	webhook, err := ws.repo.GetWebhook(ctx, webhookID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && webhook.UserID != userID) {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, err
+}
+
+// Update изменяет адрес, фильтры и состояние веб-хука пользователя. Ключ подписи не меняется.
+func (ws *WebhookService) Update(ctx context.Context, userID, webhookID int, input models.WebhookInput) (*models.Webhook, error) {
+	webhook, err := ws.Get(ctx, userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.applyWebhookInput(ctx, webhook, input); err != nil {
+		return nil, err
+	}
+	if err := ws.repo.UpdateWebhook(ctx, webhook); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	now := time.Now()
+	webhook.UpdatedAt = &now
+	return webhook, nil
+}
+
+// Delete удаляет веб-хук пользователя вместе с журналом доставок.
+func (ws *WebhookService) Delete(ctx context.Context, userID, webhookID int) error {
+	if _, err := ws.Get(ctx, userID, webhookID); err != nil {
+		return err
+	}
+	err := ws.repo.DeleteWebhook(ctx, webhookID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+// GetDeliveries возвращает журнал доставок веб-хука пользователя, начиная с последних.
+func (ws *WebhookService) GetDeliveries(ctx context.Context, userID, webhookID, offset, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := ws.Get(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+	return ws.repo.GetDeliveries(ctx, webhookID, offset, limit)
+}
+
+// Redeliver ставит событие доставки в очередь повторно как новую доставку.
+func (ws *WebhookService) Redeliver(ctx context.Context, userID, webhookID int, deliveryID int64) (*models.WebhookDelivery, error) {
+	if _, err := ws.Get(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+	delivery, err := ws.repo.Redeliver(ctx, webhookID, deliveryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("не удалось повторить доставку: %v", err)
+	}
+	return delivery, nil
+}
+
+// applyWebhookInput проверяет данные веб-хука и переносит их в webhook.
+func (ws *WebhookService) applyWebhookInput(ctx context.Context, webhook *models.Webhook, input models.WebhookInput) error {
+	u, err := url.Parse(input.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || len(input.URL) > maxWebhookURLLength {
+		return fmt.Errorf("%w: адрес должен быть абсолютным адресом http или https", ErrInvalidWebhook)
+	}
+	if err := ws.checkHost(ctx, u.Hostname()); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+
+	eventTypes := []string{}
+	seen := make(map[string]bool)
+	for _, eventType := range input.Events {
+		if !webhookEvents[eventType] {
+			return fmt.Errorf("%w: неизвестное событие %q, допустимые события: note.created, note.updated, note.deleted",
+				ErrInvalidWebhook, eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if input.AuthorID != nil && *input.AuthorID <= 0 {
+		return fmt.Errorf("%w: неверный идентификатор автора", ErrInvalidWebhook)
+	}
+
+	webhook.URL = input.URL
+	webhook.Events = eventTypes
+	webhook.AuthorID = input.AuthorID
+	if input.Active != nil {
+		webhook.Active = *input.Active
+	}
+	return nil
+}
+
+// checkHost проверяет, что имя host не указывает на адрес во внутренней сети.
+func (ws *WebhookService) checkHost(ctx context.Context, host string) error {
+	if ws.allowPrivate {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if isPrivateAddress(ip) {
+			return errWebhookAddressForbidden
+		}
+		return nil
+	}
+	addrs, err := ws.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("не удалось определить адрес %s", host)
+	}
+	for _, addr := range addrs {
+		if isPrivateAddress(addr.IP) {
+			return errWebhookAddressForbidden
+		}
+	}
+	return nil
+}
+
+// checkDialAddress проверяет адрес перед подключением к получателю. Вызывается после разрешения имени,
+// поэтому проверяется адрес, к которому действительно выполняется подключение.
+func (ws *WebhookService) checkDialAddress(network, address string, _ syscall.RawConn) error {
+	if ws.allowPrivate {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isPrivateAddress(ip) {
+		return errWebhookAddressForbidden
+	}
+	return nil
+}
+
+// isPrivateAddress сообщает, относится ли ip к внутренней сети: петлевые, частные, локальные для канала,
+// групповые и неопределенные адреса.
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip)
+}
+
+// Run отправляет доставки с заданным периодом до отмены ctx.
+func (ws *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(ws.interval)
+	defer ticker.Stop()
+
+	for {
+		ws.SendDueDeliveries(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDueDeliveries отправляет доставки, время попытки которых наступило. Если доставок больше batchSize,
+// они отправляются несколькими порциями.
+func (ws *WebhookService) SendDueDeliveries(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := ws.repo.ClaimDueDeliveries(ctx, ws.batchSize, ws.maxAttempts, ws.lease)
+		if err != nil {
+			log.Printf("Ошибка при отправке веб-хуков: %v", err)
+			return
+		}
+		for _, delivery := range deliveries {
+			ws.deliver(ctx, delivery)
+			if err := ws.repo.RecordDeliveryResult(ctx, delivery); err != nil {
+				log.Printf("Ошибка при отправке веб-хуков: %v", err)
+			}
+		}
+		if len(deliveries) < ws.batchSize {
+			return
+		}
+	}
+}
+
+// deliver выполняет попытку доставки, занятой ClaimDueDeliveries, и записывает ее результат в delivery. После
+// неудачной попытки следующая назначается с экспоненциально растущей задержкой, после maxAttempts попыток
+// доставка считается неудачной.
+func (ws *WebhookService) deliver(ctx context.Context, delivery *models.WebhookDelivery) {
+	delivery.ResponseStatus = nil
+	delivery.Error = ""
+
+	status, err := ws.send(ctx, delivery)
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	if err == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	delivery.Error = utils.TruncateString(err.Error(), maxWebhookErrorLength)
+	if delivery.Attempts >= ws.maxAttempts {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		return
+	}
+
+	next := time.Now().Add(retryDelay(ws.backoff, ws.maxBackoff, delivery.Attempts))
+	delivery.NextAttemptAt = &next
+}
+
+// send отправляет событие доставки POST-запросом и возвращает код ответа. Тело подписывается HMAC-SHA256
+// ключом веб-хука, подпись передается в заголовке X-Signature-256 в виде sha256=<hex>.
+func (ws *WebhookService) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	mac := hmac.New(sha256.New, []byte(delivery.Secret))
+	mac.Write(delivery.Payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "note_app-webhook")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+
+	resp, err := ws.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("не удалось отправить запрос: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
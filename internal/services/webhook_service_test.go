@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"note_app/internal/config"
+	"note_app/internal/models"
+	"note_app/internal/repository"
+	"testing"
+	"time"
+)
+
+// fakeWebhookRepository хранит доставки в памяти: ClaimDueDeliveries возвращает их один раз, а
+// RecordDeliveryResult запоминает сохраненные результаты.
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+	due      []*models.WebhookDelivery
+	recorded []models.WebhookDelivery
+}
+
+func (r *fakeWebhookRepository) ClaimDueDeliveries(ctx context.Context, limit, maxAttempts int, lease time.Duration) ([]*models.WebhookDelivery, error) {
+	due := r.due
+	r.due = nil
+	for _, delivery := range due {
+		delivery.Attempts++
+	}
+	return due, nil
+}
+
+func (r *fakeWebhookRepository) RecordDeliveryResult(ctx context.Context, delivery *models.WebhookDelivery) error {
+	r.recorded = append(r.recorded, *delivery)
+	return nil
+}
+
+func newTestWebhookService(repo repository.WebhookRepository, allowPrivate bool) *WebhookService {
+	return NewWebhookService(repo, config.WebhooksConfig{
+		MaxAttempts:          3,
+		Backoff:              30,
+		MaxBackoff:           3600,
+		Timeout:              5,
+		AllowPrivateNetworks: allowPrivate,
+	})
+}
+
+func TestWebhookDeliverySignature(t *testing.T) {
+	payload := []byte(`{"type":"note.created"}`)
+	received := make(chan *http.Request, 1)
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		received <- r
+	}))
+	defer server.Close()
+
+	repo := &fakeWebhookRepository{due: []*models.WebhookDelivery{{
+		ID: 7, EventType: models.EventNoteCreated, Payload: payload, Status: models.WebhookDeliveryPending,
+		URL: server.URL, Secret: "secret",
+	}}}
+	newTestWebhookService(repo, true).SendDueDeliveries(context.Background())
+
+	r := <-received
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	if got, want := r.Header.Get("X-Signature-256"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("подпись %q, ожидалась %q", got, want)
+	}
+	if r.Header.Get("X-Webhook-Delivery") != "7" || r.Header.Get("X-Webhook-Event") != models.EventNoteCreated {
+		t.Errorf("заголовки доставки: %v", r.Header)
+	}
+	if string(body) != string(payload) {
+		t.Errorf("тело %q, ожидалось %q", body, payload)
+	}
+
+	if len(repo.recorded) != 1 {
+		t.Fatalf("сохранено %d результатов, ожидался 1", len(repo.recorded))
+	}
+	delivery := repo.recorded[0]
+	if delivery.Status != models.WebhookDeliveryDelivered || delivery.DeliveredAt == nil || *delivery.ResponseStatus != http.StatusOK {
+		t.Errorf("доставка не отмечена доставленной: %+v", delivery)
+	}
+}
+
+func TestWebhookDeliveryBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	ws := newTestWebhookService(&fakeWebhookRepository{}, true)
+	delivery := &models.WebhookDelivery{Payload: []byte(`{}`), Status: models.WebhookDeliveryPending, URL: server.URL}
+
+	for attempt := 1; attempt < ws.maxAttempts; attempt++ {
+		delivery.Attempts = attempt
+		before := time.Now()
+		ws.deliver(context.Background(), delivery)
+		if delivery.Status != models.WebhookDeliveryPending || delivery.NextAttemptAt == nil {
+			t.Fatalf("попытка %d: доставка должна ожидать повтора, получено %+v", attempt, delivery)
+		}
+		want := retryDelay(ws.backoff, ws.maxBackoff, attempt)
+		if delay := delivery.NextAttemptAt.Sub(before); delay < want || delay > want+time.Second {
+			t.Errorf("попытка %d: задержка %v, ожидалась %v", attempt, delay, want)
+		}
+		if *delivery.ResponseStatus != http.StatusInternalServerError || delivery.Error == "" {
+			t.Errorf("попытка %d: не сохранен результат: %+v", attempt, delivery)
+		}
+	}
+
+	delivery.Attempts = ws.maxAttempts
+	ws.deliver(context.Background(), delivery)
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.NextAttemptAt != nil {
+		t.Errorf("после последней попытки доставка должна быть неудачной, получено %+v", delivery)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	backoff, maxBackoff := 30*time.Second, 100*time.Second
+	want := []time.Duration{30 * time.Second, 60 * time.Second, 100 * time.Second, 100 * time.Second}
+	for i, w := range want {
+		if got := retryDelay(backoff, maxBackoff, i+1); got != w {
+			t.Errorf("попытка %d: задержка %v, ожидалась %v", i+1, got, w)
+		}
+	}
+}
+
+func TestWebhookDeliveryRedirect(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("перенаправление не должно выполняться")
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	ws := newTestWebhookService(&fakeWebhookRepository{}, true)
+	delivery := &models.WebhookDelivery{Attempts: 1, Payload: []byte(`{}`), Status: models.WebhookDeliveryPending, URL: server.URL}
+	ws.deliver(context.Background(), delivery)
+	if delivery.Status != models.WebhookDeliveryPending || delivery.ResponseStatus == nil ||
+		*delivery.ResponseStatus != http.StatusTemporaryRedirect {
+		t.Errorf("перенаправление должно считаться ошибкой, получено %+v", delivery)
+	}
+}
+
+func TestWebhookPrivateAddressOnDial(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("запрос во внутреннюю сеть не должен отправляться")
+	}))
+	defer server.Close()
+
+	ws := newTestWebhookService(&fakeWebhookRepository{}, false)
+	_, err := ws.send(context.Background(), &models.WebhookDelivery{Payload: []byte(`{}`), URL: server.URL})
+	if !errors.Is(err, errWebhookAddressForbidden) {
+		t.Errorf("ожидалась errWebhookAddressForbidden, получено %v", err)
+	}
+}
+
+func TestApplyWebhookInputRejectsPrivateAddresses(t *testing.T) {
+	ws := newTestWebhookService(&fakeWebhookRepository{}, false)
+	for _, rawURL := range []string{
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://0.0.0.0/hook",
+		"http://100.64.0.1/hook",
+	} {
+		err := ws.applyWebhookInput(context.Background(), &models.Webhook{}, models.WebhookInput{URL: rawURL})
+		if !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("%s: ожидалась ErrInvalidWebhook, получено %v", rawURL, err)
+		}
+	}
+
+	var webhook models.Webhook
+	if err := ws.applyWebhookInput(context.Background(), &webhook, models.WebhookInput{URL: "https://93.184.215.14/hook"}); err != nil {
+		t.Errorf("публичный адрес отклонен: %v", err)
+	}
+}
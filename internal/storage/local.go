@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultLocalDir каталог хранилища, если он не указан в конфигурации.
+const defaultLocalDir = "data/attachments"
+
+// LocalStore хранит файлы в локальном каталоге. Объекты раскладываются по подкаталогам
+// по первым символам ключа, чтобы не складывать все файлы в один каталог.
+type LocalStore struct {
+	dir string
+}
+
+// NewLocalStore создает хранилище в каталоге dir, создавая его при необходимости.
+func NewLocalStore(dir string) (*LocalStore, error) {
+	if dir == "" {
+		dir = defaultLocalDir
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("не удалось создать каталог хранилища: %v", err)
+	}
+	return &LocalStore{dir: dir}, nil
+}
+
+// path возвращает путь к файлу объекта. Ключ не должен выходить за пределы каталога хранилища.
+func (s *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", fmt.Errorf("недопустимый ключ объекта: %q", key)
+	}
+	prefix := key
+	if len(prefix) > 2 {
+		prefix = prefix[:2]
+	}
+	return filepath.Join(s.dir, prefix, key), nil
+}
+
+// Put сохраняет объект. Файл сначала записывается во временный, а затем переименовывается,
+// чтобы читатели не увидели частично записанный объект.
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("не удалось создать каталог хранилища: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось записать файл: %v", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("записано %d байт вместо %d", written, size)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("не удалось сохранить файл: %v", err)
+	}
+	return nil
+}
+
+// Open открывает файл объекта.
+func (s *LocalStore) Open(ctx context.Context, key string, size int64) (io.ReadSeekCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete удаляет файл объекта.
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("не удалось удалить файл: %v", err)
+	}
+	return nil
+}
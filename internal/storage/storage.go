@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"note_app/internal/config"
+)
+
+// ErrNotFound возвращается, если объект с указанным ключом отсутствует в хранилище.
+var ErrNotFound = errors.New("объект не найден в хранилище")
+
+// BlobStore интерфейс хранилища файлов (вложений заметок).
+type BlobStore interface {
+	// Put сохраняет size байт из r под ключом key.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open открывает объект размером size для чтения с произвольной позиции (для ответов на Range-запросы).
+	Open(ctx context.Context, key string, size int64) (io.ReadSeekCloser, error)
+	// Delete удаляет объект. Удаление отсутствующего объекта не считается ошибкой.
+	Delete(ctx context.Context, key string) error
+}
+
+// New создает BlobStore в соответствии с конфигурацией.
+func New(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case "local", "":
+		return NewLocalStore(cfg.Local.Dir)
+	case "s3":
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("неизвестный драйвер хранилища: %s", cfg.Driver)
+	}
+}
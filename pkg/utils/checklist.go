@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"note_app/internal/models"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxChecklistItemLength максимальная длина пункта списка задач в символах.
+const MaxChecklistItemLength = 200
+
+// PrepareChecklist приводит пункты списка задач к одной строке без лишних пробелов, нумерует их по порядку
+// и проверяет длину. Возвращает пункты и текст заметки, который должен укладываться в ограничения CheckNoteLength.
+func PrepareChecklist(title string, items []models.ChecklistItem) ([]models.ChecklistItem, string, *HTTPError) {
+	prepared := make([]models.ChecklistItem, len(items))
+	for i, item := range items {
+		item.Text = strings.Join(strings.Fields(item.Text), " ")
+		if item.Text == "" || utf8.RuneCountInString(item.Text) > MaxChecklistItemLength {
+			return nil, "", &HTTPError{
+				Message: fmt.Sprintf("Пункт списка должен содержать от 1 до %d символов", MaxChecklistItemLength),
+				Code:    http.StatusBadRequest,
+			}
+		}
+		item.Position = i
+		prepared[i] = item
+	}
+
+	text := ChecklistText(prepared)
+	if !CheckNoteLength(title, text) {
+		return nil, "", &HTTPError{
+			Message: "Превышена максимальная длина заголовка или текста",
+			Code:    http.StatusBadRequest,
+		}
+	}
+	return prepared, text, nil
+}
+
+// ChecklistText формирует текст заметки-списка задач в виде списка задач Markdown.
+func ChecklistText(items []models.ChecklistItem) string {
+	var b strings.Builder
+	for i, item := range items {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if item.Checked {
+			b.WriteString("- [x] ")
+		} else {
+			b.WriteString("- [ ] ")
+		}
+		b.WriteString(item.Text)
+	}
+	return b.String()
+}
+
+// checklistLinePattern строка списка задач Markdown: "- [ ] текст" или "- [x] текст".
+var checklistLinePattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.*)$`)
+
+// ParseChecklistText возвращает пункты из текста в виде списка задач Markdown, как его формирует ChecklistText.
+// Строки, не являющиеся пунктами списка задач, пропускаются.
+func ParseChecklistText(text string) []models.ChecklistItem {
+	items := []models.ChecklistItem{}
+	for _, line := range strings.Split(text, "\n") {
+		match := checklistLinePattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		items = append(items, models.ChecklistItem{Text: match[2], Checked: match[1] != " "})
+	}
+	return items
+}
+
+// ChecklistProgress возвращает количество всех и выполненных пунктов списка задач.
+func ChecklistProgress(items []models.ChecklistItem) *models.ChecklistProgress {
+	progress := &models.ChecklistProgress{Total: len(items)}
+	for _, item := range items {
+		if item.Checked {
+			progress.Done++
+		}
+	}
+	return progress
+}
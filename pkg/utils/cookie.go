@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// TokenCookieName имя куки с токеном сессии.
+	TokenCookieName = "token"
+	// CSRFCookieName имя куки с CSRF-токеном. Куки доступна скриптам, чтобы клиент мог продублировать значение в заголовке.
+	CSRFCookieName = "csrf_token"
+	// CSRFHeaderName заголовок, в котором клиент передает CSRF-токен.
+	CSRFHeaderName = "X-CSRF-Token"
+)
+
+// sessionCookieTTL время жизни куки сессии, совпадает со временем жизни токена.
+const sessionCookieTTL = 24 * time.Hour
+
+// CookieOptions атрибуты куки сессии.
+type CookieOptions struct {
+	Secure   bool
+	SameSite http.SameSite
+	Path     string
+	Domain   string
+}
+
+// SetTokenCookie устанавливает токен в виде куки в ответе.
+// Куки недоступна скриптам (HttpOnly), остальные атрибуты берутся из opts.
+func SetTokenCookie(w http.ResponseWriter, tokenString string, opts CookieOptions) {
+	setCookie(w, TokenCookieName, tokenString, true, opts)
+}
+
+// SetCSRFCookie устанавливает куки с CSRF-токеном для защиты методом double-submit.
+func SetCSRFCookie(w http.ResponseWriter, csrfToken string, opts CookieOptions) {
+	setCookie(w, CSRFCookieName, csrfToken, false, opts)
+}
+
+// GenerateCSRFToken генерирует случайный CSRF-токен.
+func GenerateCSRFToken() (string, error) {
+	return GenerateRandomToken(32)
+}
+
+// setCookie устанавливает куки сессии с заданными атрибутами.
+func setCookie(w http.ResponseWriter, name, value string, httpOnly bool, opts CookieOptions) {
+	// Устанавливаем время истечения куки на 24 часа от текущего времени
+	expirationTime := time.Now().Add(sessionCookieTTL)
+	cookie := http.Cookie{
+		Name:     name,
+		Value:    value,
+		Expires:  expirationTime,
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		Secure:   opts.Secure,
+		HttpOnly: httpOnly,
+		SameSite: opts.SameSite,
+	}
+	http.SetCookie(w, &cookie)
+}
@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"database/sql"
+	"github.com/lib/pq"
 	"note_app/internal/models"
 )
 
@@ -11,10 +12,16 @@ func ScanNotes(rows *sql.Rows) ([]models.Note, error) {
 	var notes []models.Note
 	for rows.Next() {
 		var note models.Note
-		err := rows.Scan(&note.ID, &note.UserID, &note.Title, &note.Text, &note.CreatedAt, &note.Author)
+		var progress models.ChecklistProgress
+		err := rows.Scan(&note.ID, &note.UserID, &note.Title, &note.Text, &note.Format, &note.CreatedAt, &note.Author, &note.CommentCount,
+			&note.Pinned, &note.Favorite, &note.Archived, &note.DueAt, &note.RemindAt, &progress.Total, &progress.Done,
+			pq.Array(&note.Tags), &note.Folder, &note.Visibility)
 		if err != nil {
 			return nil, err
 		}
+		if note.Format == models.NoteFormatChecklist {
+			note.Progress = &progress
+		}
 		notes = append(notes, note)
 	}
 	if err := rows.Err(); err != nil {
@@ -0,0 +1,45 @@
+package utils
+
+import (
+	"note_app/internal/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNoteLinkLength максимальная длина текста ссылки на заметку: длиннее не бывает заголовок заметки.
+const MaxNoteLinkLength = 100
+
+var (
+	// noteLinkPattern ссылка на заметку: текст в двойных квадратных скобках без переносов строк.
+	noteLinkPattern = regexp.MustCompile(`\[\[([^\[\]\n]+)\]\]`)
+	// noteIDLinkPattern ссылка на заметку по идентификатору.
+	noteIDLinkPattern = regexp.MustCompile(`^#([0-9]+)$`)
+)
+
+// ParseNoteLinks возвращает ссылки [[заголовок]] и [[#id]] из текста заметки в порядке появления без повторов.
+// Пустые и слишком длинные ссылки пропускаются.
+func ParseNoteLinks(text string) []models.NoteLinkRef {
+	var links []models.NoteLinkRef
+	seen := make(map[string]bool)
+	for _, match := range noteLinkPattern.FindAllStringSubmatch(text, -1) {
+		linkText := strings.Join(strings.Fields(match[1]), " ")
+		if linkText == "" || utf8.RuneCountInString(linkText) > MaxNoteLinkLength || seen[linkText] {
+			continue
+		}
+		seen[linkText] = true
+
+		link := models.NoteLinkRef{Text: linkText}
+		if idMatch := noteIDLinkPattern.FindStringSubmatch(linkText); idMatch != nil {
+			// Идентификатор вне диапазона INTEGER не может принадлежать заметке
+			id, err := strconv.ParseInt(idMatch[1], 10, 32)
+			if err != nil || id == 0 {
+				continue
+			}
+			link.TargetID = int(id)
+		}
+		links = append(links, link)
+	}
+	return links
+}
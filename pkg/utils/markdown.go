@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"html"
+	"note_app/internal/models"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExcerptLength максимальная длина краткого содержания заметки в символах.
+const ExcerptLength = 200
+
+var (
+	// markdown преобразует Markdown в HTML. Сырой HTML в тексте не пропускается (goldmark экранирует его по умолчанию).
+	markdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+	// htmlPolicy оставляет только безопасные теги и атрибуты: без скриптов, стилей, обработчиков событий и
+	// ссылок с опасными схемами. Ссылки получают rel="nofollow noopener" и открываются в новой вкладке.
+	htmlPolicy = newHTMLPolicy()
+	// textPolicy удаляет все теги, оставляя текст.
+	textPolicy = bluemonday.StrictPolicy()
+)
+
+// newHTMLPolicy создает политику очистки HTML для отображения заметок.
+func newHTMLPolicy() *bluemonday.Policy {
+	policy := bluemonday.UGCPolicy()
+	policy.RequireNoFollowOnLinks(true)
+	policy.AddTargetBlankToFullyQualifiedLinks(true)
+	// Флажки списков задач GFM
+	policy.AllowAttrs("type").Matching(bluemonday.SpaceSeparatedTokens).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+	return policy
+}
+
+// NormalizeNoteFormat возвращает формат заметки с учетом значения по умолчанию и признак того, что формат допустим.
+func NormalizeNoteFormat(format string) (string, bool) {
+	switch format {
+	case "", models.NoteFormatPlain:
+		return models.NoteFormatPlain, true
+	case models.NoteFormatMarkdown, models.NoteFormatChecklist:
+		return format, true
+	}
+	return "", false
+}
+
+// RenderNoteHTML преобразует текст заметки в безопасный HTML.
+// Текст в формате plain экранируется, переводы строк заменяются на <br>. Текст списка задач является Markdown.
+func RenderNoteHTML(text, format string) string {
+	if format != models.NoteFormatMarkdown && format != models.NoteFormatChecklist {
+		escaped := html.EscapeString(text)
+		return "<p>" + strings.ReplaceAll(escaped, "\n", "<br>\n") + "</p>"
+	}
+
+	var buf bytes.Buffer
+	if err := markdown.Convert([]byte(text), &buf); err != nil {
+		return "<p>" + html.EscapeString(text) + "</p>"
+	}
+	return htmlPolicy.Sanitize(buf.String())
+}
+
+// NoteExcerpt возвращает краткое содержание заметки без разметки длиной не более maxLength символов.
+// Текст обрезается по границе слова и дополняется многоточием.
+func NoteExcerpt(text, format string, maxLength int) string {
+	if format == models.NoteFormatMarkdown || format == models.NoteFormatChecklist {
+		text = html.UnescapeString(textPolicy.Sanitize(RenderNoteHTML(text, format)))
+	}
+	text = strings.Join(strings.Fields(text), " ")
+
+	if utf8.RuneCountInString(text) <= maxLength {
+		return text
+	}
+	runes := []rune(text)[:maxLength]
+	cut := string(runes)
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " .,;:") + "…"
+}
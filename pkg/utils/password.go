@@ -0,0 +1,283 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/unicode/norm"
+	"net/http"
+	"note_app/internal/config"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Алгоритмы хэширования паролей.
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	// Значения по умолчанию для параметров, не указанных в конфигурации.
+	defaultMinPasswordLength = 8
+	defaultMaxPasswordLength = 128
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	// argon2SaltLength и argon2KeyLength размеры соли и хэша argon2id в байтах.
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+	// bcryptMaxPasswordBytes bcrypt учитывает только первые 72 байта пароля.
+	bcryptMaxPasswordBytes = 72
+)
+
+// PasswordManager проверяет пароли на соответствие требованиям, хэширует и сверяет их.
+type PasswordManager struct {
+	minLength    int
+	maxLength    int
+	allowUnicode bool
+	// breached SHA-1 хэши скомпрометированных паролей в верхнем регистре.
+	breached map[string]struct{}
+
+	algorithm         string
+	bcryptCost        int
+	argon2Memory      uint32
+	argon2Iterations  uint32
+	argon2Parallelism uint8
+}
+
+// NewPasswordManager создает PasswordManager по конфигурации, подставляя значения по умолчанию для незаданных параметров.
+func NewPasswordManager(cfg config.PasswordConfig) (*PasswordManager, error) {
+	pm := &PasswordManager{
+		minLength:         cfg.MinLength,
+		maxLength:         cfg.MaxLength,
+		allowUnicode:      cfg.AllowUnicode,
+		algorithm:         cfg.Hashing.Algorithm,
+		bcryptCost:        cfg.Hashing.BcryptCost,
+		argon2Memory:      cfg.Hashing.Argon2Memory,
+		argon2Iterations:  cfg.Hashing.Argon2Iterations,
+		argon2Parallelism: cfg.Hashing.Argon2Parallelism,
+	}
+	if pm.minLength <= 0 {
+		pm.minLength = defaultMinPasswordLength
+	}
+	if pm.maxLength <= 0 {
+		pm.maxLength = defaultMaxPasswordLength
+	}
+	if pm.minLength > pm.maxLength {
+		return nil, errors.New("минимальная длина пароля больше максимальной")
+	}
+	if pm.algorithm == "" {
+		pm.algorithm = PasswordAlgorithmArgon2id
+	}
+	if pm.bcryptCost == 0 {
+		pm.bcryptCost = bcrypt.DefaultCost
+	}
+	if pm.argon2Memory == 0 {
+		pm.argon2Memory = defaultArgon2Memory
+	}
+	if pm.argon2Iterations == 0 {
+		pm.argon2Iterations = defaultArgon2Iterations
+	}
+	if pm.argon2Parallelism == 0 {
+		pm.argon2Parallelism = defaultArgon2Parallelism
+	}
+
+	switch pm.algorithm {
+	case PasswordAlgorithmArgon2id:
+	case PasswordAlgorithmBcrypt:
+		if pm.bcryptCost < bcrypt.MinCost || pm.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("недопустимая стоимость bcrypt: %d", pm.bcryptCost)
+		}
+	default:
+		return nil, fmt.Errorf("неподдерживаемый алгоритм хэширования паролей: %s", pm.algorithm)
+	}
+
+	if cfg.BreachedListFile != "" {
+		breached, err := loadBreachedPasswords(cfg.BreachedListFile)
+		if err != nil {
+			return nil, fmt.Errorf("не удалось загрузить список скомпрометированных паролей: %v", err)
+		}
+		pm.breached = breached
+	}
+	return pm, nil
+}
+
+// loadBreachedPasswords загружает список скомпрометированных паролей. Строка может содержать сам пароль
+// или его SHA-1 хэш, в том числе в формате Have I Been Pwned (HASH:COUNT).
+func loadBreachedPasswords(path string) (map[string]struct{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	breached := make(map[string]struct{})
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			breached[strings.ToUpper(hash)] = struct{}{}
+			continue
+		}
+		breached[sha1Hex(line)] = struct{}{}
+	}
+	return breached, scanner.Err()
+}
+
+// isSHA1Hex проверяет, является ли строка SHA-1 хэшем в шестнадцатеричной записи.
+func isSHA1Hex(s string) bool {
+	if len(s) != sha1.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// sha1Hex возвращает SHA-1 хэш строки в шестнадцатеричной записи в верхнем регистре.
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// normalizePassword приводит пароль к форме NFKC, чтобы один и тот же пароль, введенный на разных устройствах,
+// давал одинаковый хэш. Пароли из символов ASCII не изменяются.
+func normalizePassword(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// Validate проверяет, что пароль удовлетворяет требованиям: длине, набору символов и отсутствию
+// в списке скомпрометированных паролей.
+func (pm *PasswordManager) Validate(password string) *HTTPError {
+	if !utf8.ValidString(password) {
+		return &HTTPError{Message: "Пароль содержит недопустимые символы", Code: http.StatusBadRequest}
+	}
+	password = normalizePassword(password)
+
+	length := utf8.RuneCountInString(password)
+	if length < pm.minLength || length > pm.maxLength {
+		return &HTTPError{
+			Message: fmt.Sprintf("Пароль должен быть от %d до %d символов", pm.minLength, pm.maxLength),
+			Code:    http.StatusBadRequest,
+		}
+	}
+	if pm.algorithm == PasswordAlgorithmBcrypt && len(password) > bcryptMaxPasswordBytes {
+		return &HTTPError{
+			Message: fmt.Sprintf("Пароль не должен превышать %d байт", bcryptMaxPasswordBytes),
+			Code:    http.StatusBadRequest,
+		}
+	}
+
+	for _, r := range password {
+		if !pm.allowUnicode && r > unicode.MaxASCII {
+			return &HTTPError{
+				Message: "Пароль может содержать только латинские буквы, цифры, пробел и специальные символы",
+				Code:    http.StatusBadRequest,
+			}
+		}
+		if !unicode.IsPrint(r) {
+			return &HTTPError{Message: "Пароль не должен содержать управляющие символы", Code: http.StatusBadRequest}
+		}
+	}
+
+	if _, found := pm.breached[sha1Hex(password)]; found {
+		return &HTTPError{
+			Message: "Этот пароль встречается в утечках данных, выберите другой",
+			Code:    http.StatusBadRequest,
+		}
+	}
+	return nil
+}
+
+// Hash хэширует пароль настроенным алгоритмом.
+func (pm *PasswordManager) Hash(password string) (string, error) {
+	password = normalizePassword(password)
+
+	if pm.algorithm == PasswordAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), pm.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, pm.argon2Iterations, pm.argon2Memory, pm.argon2Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, pm.argon2Memory, pm.argon2Iterations,
+		pm.argon2Parallelism, base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify сверяет пароль с хэшем. needsRehash сообщает, что хэш создан другим алгоритмом или с другими
+// параметрами и его следует заменить хэшем с текущими настройками.
+func (pm *PasswordManager) Verify(hash, password string) (ok bool, needsRehash bool) {
+	password = normalizePassword(password)
+
+	if strings.HasPrefix(hash, "$argon2id$") {
+		params, salt, key, err := parseArgon2Hash(hash)
+		if err != nil {
+			return false, false
+		}
+		candidate := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(candidate, key) != 1 {
+			return false, false
+		}
+		return true, pm.algorithm != PasswordAlgorithmArgon2id || params.memory != pm.argon2Memory ||
+			params.iterations != pm.argon2Iterations || params.parallelism != pm.argon2Parallelism ||
+			len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return false, false
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, err != nil || pm.algorithm != PasswordAlgorithmBcrypt || cost != pm.bcryptCost
+}
+
+// argon2Params параметры, с которыми создан хэш argon2id.
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+// parseArgon2Hash разбирает хэш argon2id в формате PHC: $argon2id$v=19$m=...,t=...,p=...$соль$хэш.
+func parseArgon2Hash(hash string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("неверный формат хэша argon2id")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("неподдерживаемая версия argon2id")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return params, nil, nil, errors.New("неверные параметры хэша argon2id")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, errors.New("неверный хэш argon2id")
+	}
+	return params, salt, key, nil
+}
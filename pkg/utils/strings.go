@@ -0,0 +1,16 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// TruncateString обрезает строку до maxLength символов по границе символа. Недопустимые последовательности
+// UTF-8 заменяются символом U+FFFD, чтобы строку можно было сохранить в базе данных.
+func TruncateString(s string, maxLength int) string {
+	s = strings.ToValidUTF8(s, "�")
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+	return string([]rune(s)[:maxLength])
+}
@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"заметка", 10, "заметка"},
+		{"заметка", 3, "зам"},
+		{"ab\xffcd", 10, "ab�cd"},
+	}
+	for _, tt := range tests {
+		if got := TruncateString(tt.s, tt.max); got != tt.want {
+			t.Errorf("TruncateString(%q, %d) = %q, ожидалось %q", tt.s, tt.max, got, tt.want)
+		}
+	}
+
+	long := strings.Repeat("я", 1000)
+	if got := TruncateString(long, 501); !utf8.ValidString(got) || utf8.RuneCountInString(got) != 501 {
+		t.Errorf("обрезанная строка: %d символов, допустимый UTF-8: %v", utf8.RuneCountInString(got), utf8.ValidString(got))
+	}
+}
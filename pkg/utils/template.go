@@ -0,0 +1,18 @@
+package utils
+
+import "regexp"
+
+// placeholderPattern подстановка шаблона: имя из букв, цифр и подчеркиваний в двойных фигурных скобках,
+// допускаются пробелы внутри скобок.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// ExpandTemplate заменяет подстановки вида {{name}} значениями из vars. Подстановки без значения остаются как есть.
+func ExpandTemplate(text string, vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret секрет из тестовых векторов RFC 6238 для SHA-1.
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestValidateTOTPVectors(t *testing.T) {
+	// Последние шесть цифр восьмизначных кодов из приложения B RFC 6238
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok || step != tt.unix/totpPeriod {
+			t.Errorf("ValidateTOTP(%q, %d) = %d, %v; ожидался шаг %d", tt.code, tt.unix, step, ok, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / totpPeriod
+	key, _ := totpEncoding.DecodeString(rfc6238Secret)
+
+	for _, offset := range []int64{-1, 1} {
+		got, ok := ValidateTOTP(rfc6238Secret, hotp(key, uint64(step+offset)), now)
+		if !ok || got != step+offset {
+			t.Errorf("код соседнего шага %+d: шаг %d, %v", offset, got, ok)
+		}
+	}
+	for _, offset := range []int64{-2, 2} {
+		if _, ok := ValidateTOTP(rfc6238Secret, hotp(key, uint64(step+offset)), now); ok {
+			t.Errorf("код шага %+d за пределами допустимого отклонения принят", offset)
+		}
+	}
+}
+
+func TestValidateTOTPInvalidInput(t *testing.T) {
+	now := time.Unix(59, 0)
+	for _, code := range []string{"", "28708", "2870820", "000000"} {
+		if _, ok := ValidateTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("код %q принят", code)
+		}
+	}
+	if _, ok := ValidateTOTP("не base32", "287082", now); ok {
+		t.Error("код принят для неверного секрета")
+	}
+}
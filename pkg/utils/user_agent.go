@@ -0,0 +1,45 @@
+package utils
+
+import "strings"
+
+// userAgentBrowsers признаки браузеров в заголовке User-Agent. Порядок важен: Edge и Opera
+// содержат "Chrome", а Chrome содержит "Safari".
+var userAgentBrowsers = []struct{ token, name string }{
+	{"Edg/", "Edge"},
+	{"OPR/", "Opera"},
+	{"Firefox/", "Firefox"},
+	{"Chrome/", "Chrome"},
+	{"Safari/", "Safari"},
+	{"curl/", "curl"},
+}
+
+// userAgentSystems признаки операционных систем. Android содержит "Linux", iOS — "Mac OS X".
+var userAgentSystems = []struct{ token, name string }{
+	{"Android", "Android"},
+	{"iPhone", "iOS"},
+	{"iPad", "iPadOS"},
+	{"Windows", "Windows"},
+	{"Mac OS X", "macOS"},
+	{"Linux", "Linux"},
+}
+
+// DescribeDevice возвращает краткое описание устройства по заголовку User-Agent, например "Chrome, Windows".
+func DescribeDevice(userAgent string) string {
+	var parts []string
+	for _, b := range userAgentBrowsers {
+		if strings.Contains(userAgent, b.token) {
+			parts = append(parts, b.name)
+			break
+		}
+	}
+	for _, s := range userAgentSystems {
+		if strings.Contains(userAgent, s.token) {
+			parts = append(parts, s.name)
+			break
+		}
+	}
+	if len(parts) == 0 {
+		return "Неизвестное устройство"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -2,17 +2,16 @@ package utils
 
 import (
 	"net/http"
+	"net/mail"
 	"note_app/internal/models"
 	"regexp"
 )
 
-// ValidateUser проверяет валидность данных пользователя.
-func ValidateUser(user *models.User) *HTTPError {
+// ValidateUser проверяет валидность данных пользователя. Пароль проверяется по требованиям passwords.
+func ValidateUser(user *models.User, passwords *PasswordManager) *HTTPError {
 	const (
 		minUsernameLength = 4
 		maxUsernameLength = 20
-		minPasswordLength = 6
-		maxPasswordLength = 20
 	)
 
 	// Проверка длины имени пользователя
@@ -32,23 +31,31 @@ func ValidateUser(user *models.User) *HTTPError {
 		}
 	}
 
-	// Проверка длины пароля
-	passwordLength := len(user.Password)
-	if passwordLength < minPasswordLength || passwordLength > maxPasswordLength {
-		return &HTTPError{
-			Message: "Пароль должен быть от 6 до 20 символов",
-			Code:    http.StatusBadRequest,
+	if err := passwords.Validate(user.Password); err != nil {
+		return err
+	}
+
+	// Email необязателен, но если указан, должен быть корректным
+	if user.Email != "" {
+		if err := ValidateEmail(user.Email); err != nil {
+			return err
 		}
 	}
 
-	// Проверка на допустимые символы в пароле
-	if !regexp.MustCompile(`^[a-zA-Z0-9_!?@#$%^&*()-+=]+$`).MatchString(user.Password) {
+	return nil
+}
+
+// ValidateEmail проверяет формат адреса электронной почты.
+func ValidateEmail(email string) *HTTPError {
+	const maxEmailLength = 255
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > maxEmailLength {
 		return &HTTPError{
-			Message: "Пароль может содержать только буквы (латинские), цифры и следующие специальные символы: !?@#$%^&*()-+=",
+			Message: "Неверный формат адреса электронной почты",
 			Code:    http.StatusBadRequest,
 		}
 	}
-
 	return nil
 }
 